@@ -0,0 +1,235 @@
+package http2curl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// accessLogHost stands in for the host of log records that do not name it
+const accessLogHost = "localhost"
+
+// ParseAccessLog returns the replay command of an access log record in one
+// of these formats:
+//
+//   - JSON objects with method, url or path, host or authority, scheme,
+//     headers and body fields, as written by NGINX log_format escape=json
+//     (request_method, request_uri, http_* ...) and Envoy JSON access logs
+//   - the NGINX combined format
+//   - the Envoy default format
+//
+// Records that do not name the host are replayed against localhost, noted
+// in a comment.
+func ParseAccessLog(line string, opts ...CurlOption) (*CurlCommand, error) {
+	line = strings.TrimSpace(line)
+	var record accessLogRecord
+	var err error
+	switch {
+	case strings.HasPrefix(line, "{"):
+		record, err = parseJSONAccessLog(line)
+	case strings.HasPrefix(line, "["):
+		record, err = parseEnvoyAccessLog(line)
+	default:
+		record, err = parseCombinedAccessLog(line)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record.command(opts)
+}
+
+// accessLogRecord is the request of an access log record
+type accessLogRecord struct {
+	method, scheme, host, target string
+	header                       http.Header
+	body                         string
+}
+
+// command returns the replay command of the record
+func (r accessLogRecord) command(opts []CurlOption) (*CurlCommand, error) {
+	if r.method == "" || r.target == "" {
+		return nil, fmt.Errorf("%w: no request method or path", ErrAccessLogFormat)
+	}
+	rawURL := r.target
+	if !strings.Contains(rawURL, "://") {
+		if r.scheme == "" {
+			r.scheme = "http"
+		}
+		if r.host == "" {
+			r.host = accessLogHost
+			opts = append(opts, WithComment("host not in the log record, replace "+accessLogHost))
+		}
+		rawURL = r.scheme + "://" + r.host + r.target
+	}
+	var body []byte
+	if r.body != "" {
+		body = []byte(r.body)
+	}
+	return BuildCurlCommand(r.method, rawURL, r.header, body, opts...)
+}
+
+// setHeader sets a header of the record unless value is empty or "-"
+func (r *accessLogRecord) setHeader(key, value string) {
+	if value == "" || value == "-" {
+		return
+	}
+	if r.header == nil {
+		r.header = http.Header{}
+	}
+	r.header.Add(key, value)
+}
+
+// jsonAccessLogFields maps the fields of JSON access logs to the parts of
+// the request they hold
+var jsonAccessLogFields = map[string]string{
+	"method": "method", "request_method": "method", ":method": "method",
+	"url": "target", "uri": "target", "request_uri": "target", "path": "target", ":path": "target",
+	"host": "host", "http_host": "host", "authority": "host", ":authority": "host",
+	"scheme": "scheme", "x_forwarded_proto": "scheme", "http_x_forwarded_proto": "scheme",
+	"body": "body", "request_body": "body",
+	"user_agent": "User-Agent", "x_forwarded_for": "X-Forwarded-For", "request_id": "X-Request-Id",
+	"referer": "Referer",
+}
+
+func parseJSONAccessLog(line string) (accessLogRecord, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return accessLogRecord{}, fmt.Errorf("%w: %w", ErrAccessLogFormat, err)
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var r accessLogRecord
+	for _, name := range names {
+		raw := fields[name]
+		key := strings.ToLower(name)
+		if key == "headers" || key == "request_headers" {
+			if err := r.setJSONHeaders(raw); err != nil {
+				return accessLogRecord{}, err
+			}
+			continue
+		}
+		var value string
+		if json.Unmarshal(raw, &value) != nil || value == "-" {
+			continue // Numbers and nested objects, e.g. status and timings
+		}
+		switch part := jsonAccessLogFields[key]; part {
+		case "method":
+			r.method = value
+		case "target":
+			r.target = value
+		case "host":
+			r.host = value
+		case "scheme":
+			r.scheme = value
+		case "body":
+			r.body = value
+		case "":
+			if strings.HasPrefix(key, "http_") {
+				r.setHeader(http.CanonicalHeaderKey(strings.ReplaceAll(key[len("http_"):], "_", "-")), value)
+			}
+		default:
+			r.setHeader(part, value)
+		}
+	}
+	return r, nil
+}
+
+// setJSONHeaders adds the headers of a JSON object of strings or string
+// arrays
+func (r *accessLogRecord) setJSONHeaders(raw json.RawMessage) error {
+	var headers map[string]interface{}
+	if err := json.Unmarshal(raw, &headers); err != nil {
+		return fmt.Errorf("%w: headers: %w", ErrAccessLogFormat, err)
+	}
+	for key, value := range headers {
+		switch v := value.(type) {
+		case string:
+			r.setHeader(key, v)
+		case []interface{}:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					r.setHeader(key, s)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseCombinedAccessLog parses the NGINX combined format:
+// $remote_addr - $remote_user [$time_local] "$request" $status
+// $body_bytes_sent "$http_referer" "$http_user_agent"
+func parseCombinedAccessLog(line string) (accessLogRecord, error) {
+	quoted := quotedLogFields(line)
+	if len(quoted) < 3 {
+		return accessLogRecord{}, fmt.Errorf("%w: expected NGINX combined format", ErrAccessLogFormat)
+	}
+	r, err := parseRequestLine(quoted[0])
+	if err != nil {
+		return accessLogRecord{}, err
+	}
+	r.setHeader("Referer", quoted[1])
+	r.setHeader("User-Agent", quoted[2])
+	return r, nil
+}
+
+// parseEnvoyAccessLog parses the Envoy default format:
+// [%START_TIME%] "%REQ(:METHOD)% %REQ(X-ENVOY-ORIGINAL-PATH?:PATH)% %PROTOCOL%"
+// ... "%REQ(X-FORWARDED-FOR)%" "%REQ(USER-AGENT)%" "%REQ(X-REQUEST-ID)%"
+// "%REQ(:AUTHORITY)%" "%UPSTREAM_HOST%"
+func parseEnvoyAccessLog(line string) (accessLogRecord, error) {
+	quoted := quotedLogFields(line)
+	if len(quoted) < 5 {
+		return accessLogRecord{}, fmt.Errorf("%w: expected Envoy default format", ErrAccessLogFormat)
+	}
+	r, err := parseRequestLine(quoted[0])
+	if err != nil {
+		return accessLogRecord{}, err
+	}
+	r.setHeader("X-Forwarded-For", quoted[1])
+	r.setHeader("User-Agent", quoted[2])
+	r.setHeader("X-Request-Id", quoted[3])
+	if quoted[4] != "-" {
+		r.host = quoted[4]
+	}
+	return r, nil
+}
+
+// parseRequestLine parses a logged "METHOD target PROTOCOL" request line
+func parseRequestLine(line string) (accessLogRecord, error) {
+	parts := strings.Fields(line)
+	if len(parts) < 2 {
+		return accessLogRecord{}, fmt.Errorf("%w: request line %q", ErrAccessLogFormat, line)
+	}
+	return accessLogRecord{method: parts[0], target: parts[1]}, nil
+}
+
+// quotedLogFields returns the double-quoted fields of a log line, unescaping
+// \" and \\
+func quotedLogFields(line string) []string {
+	var fields []string
+	for {
+		start := strings.IndexByte(line, '"')
+		if start < 0 {
+			return fields
+		}
+		var field strings.Builder
+		i := start + 1
+		for ; i < len(line) && line[i] != '"'; i++ {
+			if line[i] == '\\' && i+1 < len(line) && (line[i+1] == '"' || line[i+1] == '\\') {
+				i++
+			}
+			field.WriteByte(line[i])
+		}
+		fields = append(fields, field.String())
+		if i >= len(line) {
+			return fields
+		}
+		line = line[i+1:]
+	}
+}
@@ -0,0 +1,62 @@
+package http2curl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseAccessLog(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{
+			name: "json with headers",
+			line: `{"method":"POST","path":"/cats?color=grey","authority":"example.com","scheme":"https","status":201,` +
+				`"headers":{"Content-Type":"application/json","Accept":["text/plain","application/json"]},"body":"{\"name\":\"tom\"}"}`,
+			want: `curl -X 'POST' -d '{"name":"tom"}' -H 'Accept: text/plain' -H 'Accept: application/json' -H 'Content-Type: application/json' 'https://example.com/cats?color=grey'`,
+		},
+		{
+			name: "nginx json",
+			line: `{"request_method":"GET","request_uri":"/cats","http_host":"example.com","http_user_agent":"curl/8.0","http_x_api_version":"2","http_referer":"-"}`,
+			want: `curl -X 'GET' -H 'User-Agent: curl/8.0' -H 'X-Api-Version: 2' 'http://example.com/cats'`,
+		},
+		{
+			name: "nginx combined",
+			line: `203.0.113.7 - - [17/Oct/2026:10:00:00 +0000] "DELETE /cats/1 HTTP/1.1" 204 0 "https://example.com/" "Mozilla/5.0 \"cat\""`,
+			want: "# host not in the log record, replace localhost\n" +
+				`curl -X 'DELETE' -H 'Referer: https://example.com/' -H 'User-Agent: Mozilla/5.0 "cat"' 'http://localhost/cats/1'`,
+		},
+		{
+			name: "envoy default",
+			line: `[2026-10-17T10:00:00.000Z] "GET /v1/cats HTTP/2" 200 - 0 42 3 2 "203.0.113.7" "grpc-go/1.60" "5f1c2a" "cats.internal:8080" "10.0.0.5:8080"`,
+			want: `curl -X 'GET' -H 'User-Agent: grpc-go/1.60' -H 'X-Forwarded-For: 203.0.113.7' -H 'X-Request-Id: 5f1c2a' 'http://cats.internal:8080/v1/cats'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := ParseAccessLog(tt.line)
+			if err != nil {
+				t.Fatalf("ParseAccessLog() error = %v", err)
+			}
+			if got := command.String(); got != tt.want {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAccessLogErrors(t *testing.T) {
+	for _, line := range []string{
+		`{"status":200}`,
+		`{"method":`,
+		`203.0.113.7 - - [17/Oct/2026:10:00:00 +0000] "-" 400 0 "-" "-"`,
+		`not a log line`,
+	} {
+		if _, err := ParseAccessLog(line); !errors.Is(err, ErrAccessLogFormat) {
+			t.Errorf("ParseAccessLog(%q) error = %v, want %v", line, err, ErrAccessLogFormat)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package http2curl
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// now returns the current time, replaced in tests
+var now = time.Now
+
+// annotator returns a comment for the command generated from req, or an
+// empty string for none
+type annotator func(req *http.Request) string
+
+// WithComment adds a comment above the generated command
+func WithComment(format string, args ...interface{}) CurlOption {
+	comment := fmt.Sprintf(format, args...)
+	return withAnnotator(func(*http.Request) string {
+		return comment
+	})
+}
+
+// WithTimestampComment adds a comment with the time the command was generated
+func WithTimestampComment() CurlOption {
+	return withAnnotator(func(*http.Request) string {
+		return "timestamp: " + now().UTC().Format(time.RFC3339)
+	})
+}
+
+// WithTraceIDComment adds a comment with the trace ID found in the given
+// request header, e.g. WithTraceIDComment("X-Request-Id")
+func WithTraceIDComment(header string) CurlOption {
+	return withAnnotator(func(req *http.Request) string {
+		if traceID := req.Header.Get(header); traceID != "" {
+			return "trace-id: " + traceID
+		}
+		return ""
+	})
+}
+
+// WithSourceComment adds a comment naming the service the request came from
+func WithSourceComment(service string) CurlOption {
+	return WithComment("source: %s", service)
+}
+
+// WithInlineComments renders comments at the end of the command line instead
+// of on separate lines above it
+func WithInlineComments() CurlOption {
+	return func(c *CurlCommand) {
+		c.InlineComments = true
+	}
+}
+
+func withAnnotator(annotate annotator) CurlOption {
+	return func(c *CurlCommand) {
+		c.annotators = append(c.annotators, annotate)
+	}
+}
+
+// annotate adds the comments of the configured annotators
+func (c *CurlCommand) annotate(req *http.Request) {
+	for _, annotate := range c.annotators {
+		if comment := annotate(req); comment != "" {
+			c.Comments = append(c.Comments, comment)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package http2curl
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAnnotations(t *testing.T) {
+	now = func() time.Time {
+		return time.Date(2021, 6, 1, 12, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	}
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		name        string
+		setupReq    func() *http.Request
+		opts        []CurlOption
+		wantCommand string
+	}{
+		{
+			name: "comments above the command",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com", nil)
+				req.Header.Set("X-Request-Id", "abc-123")
+				return req
+			},
+			opts: []CurlOption{
+				WithComment("ticket %s", "OPS-42"),
+				WithTimestampComment(),
+				WithTraceIDComment("X-Request-Id"),
+				WithSourceComment("billing"),
+			},
+			wantCommand: "# ticket OPS-42\n" +
+				"# timestamp: 2021-06-01T10:30:00Z\n" +
+				"# trace-id: abc-123\n" +
+				"# source: billing\n" +
+				"curl -X 'GET' -H 'X-Request-Id: abc-123' 'http://example.com'",
+		},
+		{
+			name: "missing trace ID",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com", nil)
+				return req
+			},
+			opts:        []CurlOption{WithTraceIDComment("X-Request-Id")},
+			wantCommand: "curl -X 'GET' 'http://example.com'",
+		},
+		{
+			name: "multi-line comment",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com", nil)
+				return req
+			},
+			opts:        []CurlOption{WithComment("first\nsecond")},
+			wantCommand: "# first second\ncurl -X 'GET' 'http://example.com'",
+		},
+		{
+			name: "inline comments",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com", nil)
+				return req
+			},
+			opts:        []CurlOption{WithInlineComments(), WithComment("first"), WithSourceComment("billing")},
+			wantCommand: "curl -X 'GET' 'http://example.com' # first; source: billing",
+		},
+		{
+			name: "inline comments with heredoc body",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("hello"))
+				return req
+			},
+			opts:        []CurlOption{WithInlineComments(), WithHeredocBody(), WithComment("first")},
+			wantCommand: "{ printf '%s' \"$(cat)\"; } <<'EOF' | curl -X 'POST' --data-binary @- 'http://example.com' # first\nhello\nEOF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := GetCurlCommand(tt.setupReq(), tt.opts...)
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if command.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+		})
+	}
+}
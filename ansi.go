@@ -0,0 +1,166 @@
+package http2curl
+
+import "strings"
+
+// Theme holds the ANSI SGR parameters ANSIString colors each part of the
+// command with, e.g. "1;34" for bold blue. Empty parameters leave a part
+// uncolored.
+type Theme struct {
+	Flag     string // Flags, e.g. -H
+	Method   string // The -X parameter
+	Header   string // -H parameters
+	URL      string
+	Body     string
+	Comment  string
+	Redacted string // REDACTED placeholders, within any other part
+}
+
+// DefaultTheme colors commands for dark and light terminals alike
+var DefaultTheme = Theme{
+	Flag:     "2",
+	Method:   "1;35",
+	Header:   "36",
+	URL:      "1;34",
+	Body:     "32",
+	Comment:  "90",
+	Redacted: "1;31",
+}
+
+// ANSIString returns String colored with ANSI escape sequences for
+// interactive terminals, e.g. ANSIString(DefaultTheme)
+func (c *CurlCommand) ANSIString(theme Theme) string {
+	return c.renderPainted(" ", &ansiPainter{theme: theme})
+}
+
+// themePart is a part of the command a painter marks
+type themePart int
+
+const (
+	partNone themePart = iota
+	partFlag
+	partMethod
+	partHeader
+	partURL
+	partBody
+	partComment
+	partRedacted
+)
+
+// painter marks the parts of a rendered command, e.g. with colors. Parts
+// nest: a REDACTED placeholder is marked within the part containing it.
+type painter interface {
+	open(b *strings.Builder, part themePart)
+	close(b *strings.Builder, part themePart)
+}
+
+func paintOpen(p painter, b *strings.Builder, part themePart) {
+	if p != nil && part != partNone {
+		p.open(b, part)
+	}
+}
+
+func paintClose(p painter, b *strings.Builder, part themePart) {
+	if p != nil && part != partNone {
+		p.close(b, part)
+	}
+}
+
+// argPart returns the part of the command the i-th of args is
+func (c *CurlCommand) argPart(args []arg, i int) themePart {
+	switch c.argKind(args, i) {
+	case TokenFlag:
+		return partFlag
+	case TokenHeader:
+		return partHeader
+	case TokenBody:
+		return partBody
+	case TokenURL:
+		return partURL
+	case TokenValue:
+		if args[i-1].value == "-X" || args[i-1].value == "--request" {
+			return partMethod
+		}
+	}
+	return partNone
+}
+
+// writePaintedArg writes the i-th of args to b marked with p, marking the
+// REDACTED placeholders it contains
+func (c *CurlCommand) writePaintedArg(b *strings.Builder, args []arg, i int, p painter) {
+	part := c.argPart(args, i)
+	paintOpen(p, b, part)
+	if !strings.Contains(args[i].value, redactedValue) {
+		c.writeArg(b, args[i])
+	} else {
+		var word strings.Builder
+		c.writeArg(&word, args[i])
+		rest := word.String()
+		for {
+			j := strings.Index(rest, redactedValue)
+			if j < 0 {
+				break
+			}
+			b.WriteString(rest[:j])
+			paintOpen(p, b, partRedacted)
+			b.WriteString(redactedValue)
+			paintClose(p, b, partRedacted)
+			rest = rest[j+len(redactedValue):]
+		}
+		b.WriteString(rest)
+	}
+	paintClose(p, b, part)
+}
+
+// ansiPainter colors parts with the SGR parameters of a Theme
+type ansiPainter struct {
+	theme  Theme
+	opened []string // Parameters of the parts opened, innermost last
+}
+
+func (a *ansiPainter) code(part themePart) string {
+	switch part {
+	case partFlag:
+		return a.theme.Flag
+	case partMethod:
+		return a.theme.Method
+	case partHeader:
+		return a.theme.Header
+	case partURL:
+		return a.theme.URL
+	case partBody:
+		return a.theme.Body
+	case partComment:
+		return a.theme.Comment
+	case partRedacted:
+		return a.theme.Redacted
+	}
+	return ""
+}
+
+func (a *ansiPainter) open(b *strings.Builder, part themePart) {
+	code := a.code(part)
+	a.opened = append(a.opened, code)
+	writeSGR(b, code)
+}
+
+func (a *ansiPainter) close(b *strings.Builder, part themePart) {
+	code := a.opened[len(a.opened)-1]
+	a.opened = a.opened[:len(a.opened)-1]
+	if code == "" {
+		return
+	}
+	b.WriteString("\x1b[0m")
+	if len(a.opened) > 0 {
+		writeSGR(b, a.opened[len(a.opened)-1]) // Restore the enclosing part
+	}
+}
+
+// writeSGR writes the escape sequence selecting the SGR parameters code
+func writeSGR(b *strings.Builder, code string) {
+	if code == "" {
+		return
+	}
+	b.WriteString("\x1b[")
+	b.WriteString(code)
+	b.WriteByte('m')
+}
@@ -0,0 +1,27 @@
+package http2curl
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestANSIString(t *testing.T) {
+	req, _ := http.NewRequest("POST", "http://example.com/cats", strings.NewReader("meow"))
+	req.Header.Set("Authorization", "Bearer secret")
+	command, err := GetCurlCommand(req, WithRedactedHeaders("Authorization"), WithComment("cats"))
+	if err != nil {
+		t.Fatalf("GetCurlCommand() error = %v", err)
+	}
+
+	want := "\x1b[90m# cats\x1b[0m\n" +
+		"curl \x1b[2m-X\x1b[0m \x1b[1;35m'POST'\x1b[0m \x1b[2m-d\x1b[0m \x1b[32m'meow'\x1b[0m " +
+		"\x1b[2m-H\x1b[0m \x1b[36m'Authorization: \x1b[1;31mREDACTED\x1b[0m\x1b[36m'\x1b[0m " +
+		"\x1b[1;34m'http://example.com/cats'\x1b[0m"
+	if got := command.ANSIString(DefaultTheme); got != want {
+		t.Errorf("Got:\n%q\nWant:\n%q", got, want)
+	}
+	if got := command.ANSIString(Theme{}); got != command.String() {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, command.String())
+	}
+}
@@ -0,0 +1,167 @@
+package http2curl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// AsyncRenderer generates commands on a pool of background workers. Callers
+// only pay for a snapshot of the request: a clone of its method, URL and
+// headers and a copy of its body up to a cap.
+type AsyncRenderer struct {
+	converter *Converter
+	maxBody   int64
+	jobs      chan asyncJob
+	wg        sync.WaitGroup
+	dropped   atomic.Int64
+	closeOnce sync.Once
+}
+
+type asyncJob struct {
+	req       *http.Request
+	truncated int64        // Size of the body read before truncation, 0 when complete
+	opts      []CurlOption // Applied after the options of the renderer
+	done      func(*CurlCommand, error)
+}
+
+// NewAsyncRenderer starts workers goroutines generating commands with opts.
+// Up to queueSize snapshots wait for a worker, and bodies are copied up to
+// maxBody bytes, or entirely if maxBody is 0.
+func NewAsyncRenderer(workers, queueSize int, maxBody int64, opts ...CurlOption) *AsyncRenderer {
+	if workers < 1 {
+		workers = 1
+	}
+	r := &AsyncRenderer{
+		converter: NewConverter(opts...),
+		maxBody:   maxBody,
+		jobs:      make(chan asyncJob, queueSize),
+	}
+	r.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go r.work()
+	}
+	return r
+}
+
+func (r *AsyncRenderer) work() {
+	defer r.wg.Done()
+	for job := range r.jobs {
+		command, err := r.converter.Convert(job.req, job.opts...)
+		if err == nil && job.truncated > 0 {
+			command.Comments = append(command.Comments,
+				fmt.Sprintf("body truncated to %d bytes", job.truncated-1))
+		}
+		job.done(command, err)
+	}
+}
+
+// Submit snapshots req and queues it for rendering, calling done from a
+// worker goroutine. The body of req is restored for later readers. Submit
+// returns false without blocking when the queue is full.
+func (r *AsyncRenderer) Submit(req *http.Request, done func(*CurlCommand, error)) bool {
+	snapshot := req.Clone(req.Context())
+	var data []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if data, err = snapshotBody(req, r.maxBody); err != nil {
+			done(nil, fmt.Errorf("%w: %w", ErrBodyRead, err))
+			return true
+		}
+	}
+	return r.submit(snapshot, data, r.maxBody, nil, done)
+}
+
+// submit queues snapshot for rendering with opts, its body being data
+// copied up to one byte past maxBody
+func (r *AsyncRenderer) submit(snapshot *http.Request, data []byte, maxBody int64, opts []CurlOption, done func(*CurlCommand, error)) bool {
+	var truncated int64
+	if maxBody > 0 && int64(len(data)) > maxBody {
+		truncated = int64(len(data))
+		data = data[:maxBody]
+	}
+	if data != nil {
+		snapshot.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	select {
+	case r.jobs <- asyncJob{req: snapshot, truncated: truncated, opts: opts, done: done}:
+		return true
+	default:
+		r.dropped.Add(1)
+		return false
+	}
+}
+
+// Dropped returns the number of snapshots discarded because the queue was
+// full
+func (r *AsyncRenderer) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// Close renders the queued snapshots and stops the workers. Submit must not
+// be called after Close.
+func (r *AsyncRenderer) Close() {
+	r.closeOnce.Do(func() { close(r.jobs) })
+	r.wg.Wait()
+}
+
+// WithAsyncRenderer makes a Transport render commands on r, with the options
+// of r, logging them from r's workers
+func WithAsyncRenderer(r *AsyncRenderer) TransportOption {
+	return func(t *Transport) {
+		t.async = r
+	}
+}
+
+// AsyncMiddleware returns net/http middleware snapshotting each request and
+// rendering the command of those answered with a 5xx status on r before
+// passing it to log. Like Middleware, it redacts SensitiveHeaders and copies
+// bodies up to MiddlewareMaxBodySize when r copies them entirely.
+func AsyncMiddleware(r *AsyncRenderer, log ServerErrorLogger) func(http.Handler) http.Handler {
+	maxBody := r.maxBody
+	if maxBody == 0 {
+		maxBody = MiddlewareMaxBodySize
+	}
+	opts := []CurlOption{WithRedactedHeaders(SensitiveHeaders...)}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			// Snapshot before the handler consumes the body
+			snapshot := req.Clone(req.Context())
+			var body []byte
+			if req.Body != nil && req.Body != http.NoBody {
+				body, _ = snapshotBody(req, maxBody)
+			}
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, req)
+			if rec.status < http.StatusInternalServerError {
+				return
+			}
+			status := rec.status
+			r.submit(snapshot, body, maxBody, opts, func(command *CurlCommand, err error) {
+				if err == nil {
+					log(command, status)
+				}
+			})
+		})
+	}
+}
+
+// snapshotBody copies the body of req up to one byte past maxBody, or entirely
+// if it is 0, restoring it for later readers
+func snapshotBody(req *http.Request, maxBody int64) ([]byte, error) {
+	body := io.Reader(req.Body)
+	if maxBody > 0 {
+		body = io.LimitReader(req.Body, maxBody+1)
+	}
+	data, err := io.ReadAll(body)
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), req.Body), req.Body}
+	return data, err
+}
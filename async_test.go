@@ -0,0 +1,146 @@
+package http2curl
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAsyncRenderer(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxBody     int64
+		body        string
+		wantCommand string
+	}{
+		{
+			name:        "complete body",
+			body:        "meow",
+			wantCommand: `curl -X 'POST' -d 'meow' -H 'X-Auth-Token: REDACTED' 'http://example.com/cats'`,
+		},
+		{
+			name:    "truncated body",
+			maxBody: 4,
+			body:    "meow meow",
+			wantCommand: "# body truncated to 4 bytes\n" +
+				`curl -X 'POST' -d 'meow' -H 'X-Auth-Token: REDACTED' 'http://example.com/cats'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := NewAsyncRenderer(2, 4, tt.maxBody, WithRedactedHeaders("X-Auth-Token"))
+			req, _ := http.NewRequest("POST", "http://example.com/cats", strings.NewReader(tt.body))
+			req.Header.Set("X-Auth-Token", "private-token")
+
+			rendered := make(chan string, 1)
+			if !renderer.Submit(req, func(command *CurlCommand, err error) {
+				if err != nil {
+					t.Errorf("render error = %v", err)
+				}
+				rendered <- command.String()
+			}) {
+				t.Fatal("Submit() = false, want true")
+			}
+			req.Header.Set("X-Auth-Token", "changed after submit")
+			renderer.Close()
+
+			if got := <-rendered; got != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.wantCommand)
+			}
+			if body, _ := io.ReadAll(req.Body); string(body) != tt.body {
+				t.Errorf("request body after Submit() = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestAsyncRendererDropsWhenFull(t *testing.T) {
+	renderer := NewAsyncRenderer(1, 1, 0)
+	block := make(chan struct{})
+	started := make(chan struct{})
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+
+	renderer.Submit(req, func(*CurlCommand, error) {
+		close(started)
+		<-block
+	})
+	<-started
+	if !renderer.Submit(req, func(*CurlCommand, error) {}) {
+		t.Error("Submit() with a busy worker and an empty queue = false, want true")
+	}
+	if renderer.Submit(req, func(*CurlCommand, error) {}) {
+		t.Error("Submit() with a busy worker and a full queue = true, want false")
+	}
+	close(block)
+	renderer.Close()
+	if renderer.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want 1", renderer.Dropped())
+	}
+}
+
+func TestAsyncMiddlewareAndTransport(t *testing.T) {
+	renderer := NewAsyncRenderer(1, 8, 0)
+	logged := make(chan string, 2)
+
+	handler := AsyncMiddleware(renderer, func(command *CurlCommand, status int) {
+		logged <- "middleware: " + command.String()
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(nil, func(command *CurlCommand, resp *http.Response, err error) {
+		logged <- "transport: " + command.String()
+	}, WithAsyncRenderer(renderer))}
+	req, _ := http.NewRequest("PUT", server.URL+"/cats", strings.NewReader("meow"))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+	defer renderer.Close()
+
+	want := map[string]bool{
+		`transport: curl -X 'PUT' -d 'meow' '` + server.URL + `/cats'`:                                                                 true,
+		`middleware: curl -X 'PUT' -d 'meow' -H 'Accept-Encoding: gzip' -H 'User-Agent: Go-http-client/1.1' '` + server.URL + `/cats'`: true,
+	}
+	for i := 0; i < 2; i++ {
+		if got := <-logged; !want[got] {
+			t.Errorf("unexpected log %q, want one of %v", got, want)
+		}
+	}
+}
+
+func TestAsyncMiddlewareDefaults(t *testing.T) {
+	renderer := NewAsyncRenderer(1, 8, 0)
+	defer renderer.Close()
+	logged := make(chan string, 1)
+
+	handler := AsyncMiddleware(renderer, func(command *CurlCommand, status int) {
+		logged <- command.String()
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	req := httptest.NewRequest("GET", "http://example.com/cats", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Cookie", "session=secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := `curl -X 'GET' -H 'Authorization: REDACTED' -H 'Cookie: REDACTED' 'http://example.com/cats'`
+	if got := <-logged; got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+
+	large := httptest.NewRequest("POST", "http://example.com/cats", strings.NewReader(strings.Repeat("x", MiddlewareMaxBodySize+10)))
+	handler.ServeHTTP(httptest.NewRecorder(), large)
+	wantComment := fmt.Sprintf("# body truncated to %d bytes\n", MiddlewareMaxBodySize)
+	if got := <-logged; !strings.HasPrefix(got, wantComment) {
+		t.Errorf("command of a large body starts with %.80q, want %q", got, wantComment)
+	}
+}
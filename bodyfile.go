@@ -0,0 +1,37 @@
+package http2curl
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WithBodyFromFile renders the body as --data-binary '@path' instead of
+// inlining it, for requests whose body was read from the file at path. The
+// request body is left unread.
+func WithBodyFromFile(path string) CurlOption {
+	return func(c *CurlCommand) {
+		c.BodyFile = path
+	}
+}
+
+// fileBodyPath returns the absolute path of a body read from a regular file
+// that is still at its start, or an empty string for other bodies
+func fileBodyPath(body io.Reader) string {
+	f, ok := body.(*os.File)
+	if !ok {
+		return ""
+	}
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+	if offset, err := f.Seek(0, io.SeekCurrent); err != nil || offset != 0 {
+		return ""
+	}
+	path, err := filepath.Abs(f.Name())
+	if err != nil {
+		return ""
+	}
+	return path
+}
@@ -0,0 +1,90 @@
+package http2curl
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cats.json")
+	if err := os.WriteFile(path, []byte(`{"name":"Tom"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		body        func(t *testing.T) io.Reader
+		opts        []CurlOption
+		wantCommand string
+		wantBody    string
+	}{
+		{
+			name: "os.File body",
+			body: func(t *testing.T) io.Reader {
+				f, err := os.Open(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { f.Close() })
+				return f
+			},
+			wantCommand: `curl -X 'POST' --data-binary '@` + path + `' 'http://example.com/cats'`,
+			wantBody:    `{"name":"Tom"}`,
+		},
+		{
+			name: "partly read os.File body",
+			body: func(t *testing.T) io.Reader {
+				f, err := os.Open(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { f.Close() })
+				f.Seek(1, io.SeekStart)
+				return f
+			},
+			wantCommand: `curl -X 'POST' -d '"name":"Tom"}' 'http://example.com/cats'`,
+			wantBody:    `"name":"Tom"}`,
+		},
+		{
+			name:        "WithBodyFromFile",
+			body:        func(*testing.T) io.Reader { return strings.NewReader("meow") },
+			opts:        []CurlOption{WithBodyFromFile("/tmp/meow.txt")},
+			wantCommand: `curl -X 'POST' --data-binary '@/tmp/meow.txt' 'http://example.com/cats'`,
+			wantBody:    "meow",
+		},
+		{
+			name: "os.File body in compat mode",
+			body: func(t *testing.T) io.Reader {
+				f, err := os.Open(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { f.Close() })
+				return f
+			},
+			opts:        []CurlOption{WithCompatVersion(CompatV1)},
+			wantCommand: `curl -X 'POST' -d '{"name":"Tom"}' 'http://example.com/cats'`,
+			wantBody:    `{"name":"Tom"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "http://example.com/cats", tt.body(t))
+			command, err := GetCurlCommand(req, tt.opts...)
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.wantCommand)
+			}
+			if body, _ := io.ReadAll(req.Body); string(body) != tt.wantBody {
+				t.Errorf("request body after GetCurlCommand() = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package http2curl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Builder constructs a curl command from scratch, e.g. from an API
+// specification, with the same options and rendering as GetCurlCommand:
+//
+//	command, err := NewCommand().Method("POST").URL("https://example.com/cats").
+//		Header("Accept", "application/json").JSONBody(cat).Build()
+//
+// The first error met while building is returned by Build.
+type Builder struct {
+	method string
+	url    string
+	header http.Header
+	body   []byte
+	opts   []CurlOption
+	err    error
+}
+
+// NewCommand returns a Builder for a GET request
+func NewCommand() *Builder {
+	return &Builder{method: http.MethodGet, header: http.Header{}}
+}
+
+// Method sets the request method
+func (b *Builder) Method(method string) *Builder {
+	b.method = method
+	return b
+}
+
+// URL sets the request URL
+func (b *Builder) URL(rawURL string) *Builder {
+	b.url = rawURL
+	return b
+}
+
+// Query adds a query parameter to the URL
+func (b *Builder) Query(key, value string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	u, err := url.Parse(b.url)
+	if err != nil {
+		b.err = fmt.Errorf("url parse error: %w", err)
+		return b
+	}
+	query := u.Query()
+	query.Add(key, value)
+	u.RawQuery = query.Encode()
+	b.url = u.String()
+	return b
+}
+
+// Header adds a header value
+func (b *Builder) Header(key, value string) *Builder {
+	b.header.Add(key, value)
+	return b
+}
+
+// Body sets the raw request body
+func (b *Builder) Body(data string) *Builder {
+	b.body = []byte(data)
+	return b
+}
+
+// JSONBody sets the body to v encoded as JSON, with a Content-Type of
+// application/json unless one is set
+func (b *Builder) JSONBody(v interface{}) *Builder {
+	data, err := json.Marshal(v)
+	if err != nil {
+		if b.err == nil {
+			b.err = fmt.Errorf("json body: %w", err)
+		}
+		return b
+	}
+	b.body = data
+	b.setDefaultContentType("application/json")
+	return b
+}
+
+// FormBody sets the body to the URL encoded form values, with a
+// Content-Type of application/x-www-form-urlencoded unless one is set
+func (b *Builder) FormBody(values url.Values) *Builder {
+	b.body = []byte(values.Encode())
+	b.setDefaultContentType("application/x-www-form-urlencoded")
+	return b
+}
+
+func (b *Builder) setDefaultContentType(contentType string) {
+	if b.header.Get("Content-Type") == "" {
+		b.header.Set("Content-Type", contentType)
+	}
+}
+
+// Options adds options applied when building the command
+func (b *Builder) Options(opts ...CurlOption) *Builder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// Build returns the curl command
+func (b *Builder) Build() (*CurlCommand, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return BuildCurlCommand(b.method, b.url, b.header, b.body, b.opts...)
+}
+
+// BuildCurlCommand returns the curl command for a request given by its
+// parts, e.g. from a log record or a proxy, with the same options and
+// rendering as GetCurlCommand. header and body are not modified.
+func BuildCurlCommand(method, rawURL string, header http.Header, body []byte, opts ...CurlOption) (*CurlCommand, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, rawURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		req.Header = header.Clone()
+	}
+	return GetCurlCommand(req, opts...)
+}
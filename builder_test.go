@@ -0,0 +1,89 @@
+package http2curl
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	tests := []struct {
+		name        string
+		builder     *Builder
+		wantCommand string
+		wantErr     bool
+	}{
+		{
+			name:        "defaults to GET",
+			builder:     NewCommand().URL("http://example.com/cats"),
+			wantCommand: `curl -X 'GET' 'http://example.com/cats'`,
+		},
+		{
+			name: "JSON body",
+			builder: NewCommand().Method("POST").URL("https://example.com/cats").
+				Header("Accept", "application/json").JSONBody(map[string]string{"name": "o'neill"}),
+			wantCommand: `curl -X 'POST' -d '{"name":"o'\''neill"}' -H 'Accept: application/json' -H 'Content-Type: application/json' 'https://example.com/cats'`,
+		},
+		{
+			name: "form body keeps explicit content type",
+			builder: NewCommand().Method("POST").URL("https://example.com/login").
+				Header("Content-Type", "application/x-www-form-urlencoded; charset=utf-8").
+				FormBody(url.Values{"user": {"tom"}, "pass": {"secret"}}),
+			wantCommand: `curl -X 'POST' -d 'pass=secret&user=tom' -H 'Content-Type: application/x-www-form-urlencoded; charset=utf-8' 'https://example.com/login'`,
+		},
+		{
+			name: "query and options",
+			builder: NewCommand().URL("https://example.com/cats?color=grey").Query("name", "tom").
+				Header("Authorization", "Bearer abc").Options(WithRedactedHeaders("Authorization"), WithInsecureSkipVerify()),
+			wantCommand: `curl -k -X 'GET' -H 'Authorization: REDACTED' 'https://example.com/cats?color=grey&name=tom'`,
+		},
+		{
+			name:    "unencodable JSON body",
+			builder: NewCommand().URL("http://example.com").JSONBody(make(chan int)),
+			wantErr: true,
+		},
+		{
+			name:    "invalid URL",
+			builder: NewCommand().URL("http://[::1"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := tt.builder.Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && command.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestBuildCurlCommand(t *testing.T) {
+	header := http.Header{"Content-Type": {"application/json"}}
+	body := []byte(`{"name":"tom"}`)
+	command, err := BuildCurlCommand("POST", "http://example.com/cats", header, body, WithCurlJSON())
+	if err != nil {
+		t.Fatalf("BuildCurlCommand() error = %v", err)
+	}
+	want := `curl -X 'POST' --json '{"name":"tom"}' 'http://example.com/cats'`
+	if got := command.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+
+	command, err = BuildCurlCommand("DELETE", "http://example.com/cats/1", nil, nil)
+	if err != nil {
+		t.Fatalf("BuildCurlCommand() error = %v", err)
+	}
+	want = `curl -X 'DELETE' 'http://example.com/cats/1'`
+	if got := command.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+
+	if _, err := BuildCurlCommand("GET", "http://[::1", nil, nil); err == nil {
+		t.Error("BuildCurlCommand() with an invalid URL returned no error")
+	}
+}
@@ -0,0 +1,191 @@
+package http2curl
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// renderCache holds the last rendered form of a command along with the
+// inputs it was rendered from, so it is reused until any of them changes
+type renderCache struct {
+	mu              sync.Mutex
+	valid           bool
+	inputs          renderInputs
+	headers         []Header
+	flags           []string
+	comments        []string
+	footerComments  []string
+	suppressHeaders []string
+	variables       []Variable
+	formParts       []FormPart
+	next            []string
+	output          string
+}
+
+// renderInputs are the scalar fields String depends on
+type renderInputs struct {
+	method, url, proxy, requestTarget string
+	traceFile                         string
+	bodyFile, configFile              string
+	body                              BodySpec
+	hasBody                           bool
+
+	proxyTunnel, insecureSkipVerify, enableCompression      bool
+	escapedNewlines, heredocBody, curlJSON                  bool
+	contentLength, inlineComments, exactURL                 bool
+	longRequestFlag, implicitPost, doubleQuotes, traceASCII bool
+
+	maxTime, connectTimeout      time.Duration
+	tlsMinVersion, tlsMaxVersion uint16
+	ciphers, tls13Ciphers        string
+	pinnedPubKey                 string
+	socksProxy                   string
+	socksVersion                 SocksVersion
+	httpVersion                  string
+	resolve                      string
+	cookies                      string
+	cookieFile, cookieJarFile    string
+	outputFile, writeOut         string
+	failQuietly, windowsCurl     bool
+	posixPortable, powerShell    bool
+
+	flagOrder     [flagClassCount]FlagClass
+	compatVersion string
+	placeholders  PlaceholderSyntax
+}
+
+func (c *CurlCommand) renderInputs() renderInputs {
+	inputs := renderInputs{
+		method:             c.Method,
+		url:                c.URL,
+		proxy:              c.Proxy,
+		requestTarget:      c.RequestTarget,
+		traceFile:          c.TraceFile,
+		bodyFile:           c.BodyFile,
+		configFile:         c.ConfigFile,
+		proxyTunnel:        c.ProxyTunnel,
+		insecureSkipVerify: c.InsecureSkipVerify,
+		enableCompression:  c.EnableCompression,
+		escapedNewlines:    c.EscapedNewlines,
+		heredocBody:        c.HeredocBody,
+		curlJSON:           c.CurlJSON,
+		contentLength:      c.ContentLength,
+		inlineComments:     c.InlineComments,
+		exactURL:           c.ExactURL,
+		longRequestFlag:    c.LongRequestFlag,
+		implicitPost:       c.ImplicitPost,
+		doubleQuotes:       c.DoubleQuotes,
+		traceASCII:         c.TraceASCII,
+		maxTime:            c.MaxTime,
+		connectTimeout:     c.ConnectTimeout,
+		tlsMinVersion:      c.TLSMinVersion,
+		tlsMaxVersion:      c.TLSMaxVersion,
+		pinnedPubKey:       c.PinnedPubKey,
+		socksProxy:         c.SocksProxy,
+		socksVersion:       c.SocksVersion,
+		httpVersion:        c.HTTPVersion,
+		resolve:            strings.Join(c.Resolve, "\n"),
+		cookies:            c.Cookies,
+		cookieFile:         c.CookieFile,
+		cookieJarFile:      c.CookieJarFile,
+		outputFile:         c.OutputFile,
+		writeOut:           c.WriteOut,
+		failQuietly:        c.FailQuietly,
+		windowsCurl:        c.WindowsCurl,
+		powerShell:         c.PowerShell,
+		posixPortable:      c.POSIXPortable,
+		flagOrder:          c.flagOrder(),
+		compatVersion:      c.CompatVersion,
+		placeholders:       c.PlaceholderSyntax,
+	}
+	if c.Body != nil {
+		inputs.body, inputs.hasBody = *c.Body, true
+	}
+	inputs.ciphers, inputs.tls13Ciphers = c.cipherLists()
+	return inputs
+}
+
+// get returns the rendered command, rendering it again if c changed since
+// the last call
+func (rc *renderCache) get(c *CurlCommand) string {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	inputs := c.renderInputs()
+	next := c.nextOutputs()
+	if rc.valid && rc.inputs == inputs && equalHeaders(rc.headers, c.Headers) &&
+		equalStrings(rc.flags, c.Flags) && equalStrings(rc.comments, c.Comments) &&
+		equalStrings(rc.footerComments, c.FooterComments) &&
+		equalStrings(rc.suppressHeaders, c.SuppressHeaders) && equalVariables(rc.variables, c.Variables) &&
+		equalFormParts(rc.formParts, c.FormParts) && equalStrings(rc.next, next) {
+		return rc.output
+	}
+	rc.output = c.render()
+	rc.inputs = inputs
+	rc.headers = append(rc.headers[:0], c.Headers...)
+	rc.flags = append(rc.flags[:0], c.Flags...)
+	rc.comments = append(rc.comments[:0], c.Comments...)
+	rc.footerComments = append(rc.footerComments[:0], c.FooterComments...)
+	rc.suppressHeaders = append(rc.suppressHeaders[:0], c.SuppressHeaders...)
+	rc.variables = append(rc.variables[:0], c.Variables...)
+	rc.formParts = append(rc.formParts[:0], c.FormParts...)
+	rc.next = next
+	rc.valid = true
+	return rc.output
+}
+
+// Len returns the length of the rendered command, e.g. to size log buffers
+func (c *CurlCommand) Len() int {
+	return len(c.String())
+}
+
+func equalHeaders(a, b []Header) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// equalVariables compares the names and values of variables, the only
+// parts used when rendering
+func equalVariables(a, b []Variable) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Value != b[i].Value {
+			return false
+		}
+	}
+	return true
+}
+
+func equalFormParts(a, b []FormPart) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
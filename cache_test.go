@@ -0,0 +1,63 @@
+package http2curl
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestCurlCommandStringCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		mutate      func(c *CurlCommand)
+		wantCommand string
+	}{
+		{
+			name:        "unchanged",
+			mutate:      func(c *CurlCommand) {},
+			wantCommand: `curl -X 'POST' -d 'meow' -H 'X-Auth-Token: private-token' 'http://example.com/cats'`,
+		},
+		{
+			name:        "field assignment",
+			mutate:      func(c *CurlCommand) { c.Method = "PUT" },
+			wantCommand: `curl -X 'PUT' -d 'meow' -H 'X-Auth-Token: private-token' 'http://example.com/cats'`,
+		},
+		{
+			name:        "header modified in place",
+			mutate:      func(c *CurlCommand) { c.Headers[0].Value = "other-token" },
+			wantCommand: `curl -X 'POST' -d 'meow' -H 'X-Auth-Token: other-token' 'http://example.com/cats'`,
+		},
+		{
+			name:        "body modified in place",
+			mutate:      func(c *CurlCommand) { c.Body.Data = "purr" },
+			wantCommand: `curl -X 'POST' -d 'purr' -H 'X-Auth-Token: private-token' 'http://example.com/cats'`,
+		},
+		{
+			name:        "mutation method",
+			mutate:      func(c *CurlCommand) { c.AddFlag("--fail") },
+			wantCommand: `curl -X 'POST' -d 'meow' -H 'X-Auth-Token: private-token' 'http://example.com/cats' --fail`,
+		},
+		{
+			name:        "comment added",
+			mutate:      func(c *CurlCommand) { c.Comments = append(c.Comments, "note") },
+			wantCommand: "# note\n" + `curl -X 'POST' -d 'meow' -H 'X-Auth-Token: private-token' 'http://example.com/cats'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "http://example.com/cats", bytes.NewBufferString("meow"))
+			req.Header.Set("X-Auth-Token", "private-token")
+			command, _ := GetCurlCommand(req)
+			_ = command.String()
+
+			tt.mutate(command)
+			if got := command.String(); got != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.wantCommand)
+			}
+			if command.Len() != len(tt.wantCommand) {
+				t.Errorf("Len() = %d, want %d", command.Len(), len(tt.wantCommand))
+			}
+		})
+	}
+}
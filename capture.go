@@ -0,0 +1,124 @@
+package http2curl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CapturedCommand is a command recorded by a CaptureBuffer
+type CapturedCommand struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	Status  int       `json:"status,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// CaptureBuffer keeps the most recent commands in a fixed-size ring, safe for
+// concurrent use. Its LogRoundTrip and LogServerError methods can be passed
+// to NewTransport and Middleware, and Handler serves the recorded commands.
+type CaptureBuffer struct {
+	mu      sync.Mutex
+	entries []CapturedCommand
+	next    int
+	full    bool
+}
+
+// NewCaptureBuffer returns a CaptureBuffer holding up to size commands
+func NewCaptureBuffer(size int) *CaptureBuffer {
+	if size < 1 {
+		size = 1
+	}
+	return &CaptureBuffer{entries: make([]CapturedCommand, size)}
+}
+
+// Add records command along with the response status and error, if any
+func (b *CaptureBuffer) Add(command *CurlCommand, status int, err error) {
+	if command == nil {
+		return
+	}
+	entry := CapturedCommand{Time: now().UTC(), Command: command.String(), Status: status}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries[b.next] = entry
+	b.next = (b.next + 1) % len(b.entries)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// LogRoundTrip is a TransportLogger recording commands in the buffer
+func (b *CaptureBuffer) LogRoundTrip(command *CurlCommand, resp *http.Response, err error) {
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	b.Add(command, status, err)
+}
+
+// LogServerError is a ServerErrorLogger recording commands in the buffer
+func (b *CaptureBuffer) LogServerError(command *CurlCommand, status int) {
+	b.Add(command, status, nil)
+}
+
+// Recent returns up to n of the most recent commands, oldest first, or all
+// of them if n <= 0
+func (b *CaptureBuffer) Recent(n int) []CapturedCommand {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var recent []CapturedCommand
+	if b.full {
+		recent = append(recent, b.entries[b.next:]...)
+	}
+	recent = append(recent, b.entries[:b.next]...)
+	if n > 0 && n < len(recent) {
+		recent = recent[len(recent)-n:]
+	}
+	return recent
+}
+
+// Handler returns a handler serving the recorded commands as shell comments
+// and commands, or as JSON when requested with ?format=json or an Accept
+// header. The n query parameter limits the number of commands.
+func (b *CaptureBuffer) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := 0
+		if s := r.URL.Query().Get("n"); s != "" {
+			var err error
+			if n, err = strconv.Atoi(s); err != nil {
+				http.Error(w, "invalid n", http.StatusBadRequest)
+				return
+			}
+		}
+		recent := b.Recent(n)
+
+		if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			if recent == nil {
+				recent = []CapturedCommand{}
+			}
+			json.NewEncoder(w).Encode(recent)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, entry := range recent {
+			fmt.Fprintf(w, "# %s", entry.Time.Format(time.RFC3339))
+			if entry.Status != 0 {
+				fmt.Fprintf(w, " status %d", entry.Status)
+			}
+			if entry.Error != "" {
+				fmt.Fprintf(w, " error: %s", sanitizeComment(entry.Error))
+			}
+			fmt.Fprintf(w, "\n%s\n\n", entry.Command)
+		}
+	})
+}
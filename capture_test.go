@@ -0,0 +1,75 @@
+package http2curl
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCaptureBuffer(t *testing.T) {
+	now = func() time.Time {
+		return time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	}
+	defer func() { now = time.Now }()
+
+	buf := NewCaptureBuffer(2)
+	for _, path := range []string{"/a", "/b", "/c"} {
+		req, _ := http.NewRequest("GET", "http://example.com"+path, nil)
+		command, _ := GetCurlCommand(req)
+		buf.LogRoundTrip(command, &http.Response{StatusCode: http.StatusOK}, nil)
+	}
+	req, _ := http.NewRequest("GET", "http://example.com/d", nil)
+	command, _ := GetCurlCommand(req)
+	buf.Add(command, 0, errors.New("connection\nrefused"))
+
+	tests := []struct {
+		name     string
+		target   string
+		accept   string
+		wantType string
+		wantBody string
+	}{
+		{
+			name:     "text",
+			target:   "/debug/curl",
+			wantType: "text/plain; charset=utf-8",
+			wantBody: "# 2024-05-06T07:08:09Z status 200\n" +
+				"curl -X 'GET' 'http://example.com/c'\n\n" +
+				"# 2024-05-06T07:08:09Z error: connection refused\n" +
+				"curl -X 'GET' 'http://example.com/d'\n\n",
+		},
+		{
+			name:     "JSON limited to the last command",
+			target:   "/debug/curl?format=json&n=1",
+			wantType: "application/json",
+			wantBody: `[{"time":"2024-05-06T07:08:09Z","command":"curl -X 'GET' 'http://example.com/d'","error":"connection\nrefused"}]` + "\n",
+		},
+		{
+			name:     "JSON from Accept header",
+			target:   "/debug/curl?n=1",
+			accept:   "application/json",
+			wantType: "application/json",
+			wantBody: `[{"time":"2024-05-06T07:08:09Z","command":"curl -X 'GET' 'http://example.com/d'","error":"connection\nrefused"}]` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			buf.Handler().ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if rec.Body.String() != tt.wantBody {
+				t.Errorf("Got:\n%s\nWant:\n%s", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
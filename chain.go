@@ -0,0 +1,123 @@
+package http2curl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChainCommands merges cmds into a single curl invocation, separating the
+// requests with --next so they run in order and share curl's connection
+// reuse and cookie engine. Comments and footer comments of the chained
+// commands are moved to the result, and their variables are merged into a
+// table shared by every request of the chain. At most one of the commands
+// may read its body from standard input, and none from a config file.
+func ChainCommands(cmds ...*CurlCommand) (*CurlCommand, error) {
+	var chain []*CurlCommand
+	for _, command := range cmds {
+		if command == nil {
+			continue
+		}
+		chain = append(chain, command)
+		chain = append(chain, command.Next...)
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("%w: no commands to chain", ErrConflictingOptions)
+	}
+	stdin := 0
+	for _, command := range chain {
+		if command.ConfigFile != "" {
+			return nil, fmt.Errorf("%w: commands read from a config file cannot be chained", ErrConflictingOptions)
+		}
+		if command.readsStdin() {
+			stdin++
+		}
+	}
+	if stdin > 1 {
+		return nil, fmt.Errorf("%w: %d chained commands read their body from standard input", ErrConflictingOptions, stdin)
+	}
+
+	head := chain[0].Clone()
+	head.Next = nil
+	vars := append([]Variable(nil), head.Variables...)
+	for _, command := range chain[1:] {
+		next := command.Clone()
+		next.Next = nil
+		head.Comments = append(head.Comments, next.Comments...)
+		head.FooterComments = append(head.FooterComments, next.FooterComments...)
+		if len(next.Variables) > 0 && next.PlaceholderSyntax != head.PlaceholderSyntax {
+			return nil, fmt.Errorf("%w: chained commands use different placeholder syntaxes", ErrConflictingOptions)
+		}
+		for _, v := range next.Variables {
+			existing, ok := findVariable(vars, v.Name)
+			if !ok {
+				vars = append(vars, v)
+			} else if existing.Value != v.Value {
+				return nil, fmt.Errorf("%w: chained commands set variable %s to different values", ErrConflictingOptions, v.Name)
+			}
+		}
+		next.Comments, next.FooterComments = nil, nil
+		head.Next = append(head.Next, next)
+	}
+	// Quote the placeholders of every request for the shell to expand
+	for _, command := range append([]*CurlCommand{head}, head.Next...) {
+		command.adoptVariables(vars, head.PlaceholderSyntax)
+	}
+	return head, nil
+}
+
+// readsStdin reports whether curl reads the body of the command from
+// standard input
+func (c *CurlCommand) readsStdin() bool {
+	if c.ConfigFile != "" {
+		return false
+	}
+	switch c.bodyMode() {
+	case bodyEcho, bodyStream, bodyBase64, bodyHeredoc, bodyPrintf:
+		return true
+	}
+	return false
+}
+
+// stdinCommand returns the command of the chain reading its body from
+// standard input, or c if there is none
+func (c *CurlCommand) stdinCommand() *CurlCommand {
+	for _, next := range c.Next {
+		if next.readsStdin() {
+			return next
+		}
+	}
+	return c
+}
+
+// nextOutputs returns the rendered chained commands, used to tell when the
+// rendered chain changed
+func (c *CurlCommand) nextOutputs() []string {
+	if len(c.Next) == 0 {
+		return nil
+	}
+	outputs := make([]string, len(c.Next))
+	for i, next := range c.Next {
+		outputs[i] = next.String()
+	}
+	return outputs
+}
+
+// nextConfigContents returns the chained commands in curl config file format
+func (c *CurlCommand) nextConfigContents() string {
+	var b strings.Builder
+	for _, next := range c.Next {
+		b.WriteString("next\n")
+		b.WriteString(next.ConfigFileContents())
+	}
+	return b.String()
+}
+
+// findVariable returns the variable with the given name
+func findVariable(variables []Variable, name string) (Variable, bool) {
+	for _, v := range variables {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Variable{}, false
+}
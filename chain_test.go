@@ -0,0 +1,97 @@
+package http2curl
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestChainCommands(t *testing.T) {
+	login, _ := http.NewRequest("POST", "http://example.com/login", strings.NewReader("user=cat"))
+	first, _ := GetCurlCommand(login, WithComment("login"))
+	feed, _ := http.NewRequest("PUT", "http://example.com/feed", strings.NewReader("fish\nand chips"))
+	second, _ := GetCurlCommand(feed, WithHeredocBody())
+	status, _ := http.NewRequest("GET", "http://example.com/status", nil)
+	third, _ := GetCurlCommand(status)
+
+	chained, err := ChainCommands(first, second, third)
+	if err != nil {
+		t.Fatalf("ChainCommands() error = %v", err)
+	}
+	want := "# login\n" +
+		`{ printf '%s' "$(cat)"; } <<'EOF' | curl -X 'POST' -d 'user=cat' 'http://example.com/login' --next -X 'PUT' --data-binary @- 'http://example.com/feed' --next -X 'GET' 'http://example.com/status'` +
+		"\nfish\nand chips\nEOF"
+	if got := chained.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+	if len(first.Next) != 0 {
+		t.Error("ChainCommands() modified its first command")
+	}
+
+	wantConfig := "request = \"POST\"\ndata = \"user=cat\"\nurl = \"http://example.com/login\"\n" +
+		"next\nrequest = \"PUT\"\ndata-binary = \"@-\"\nurl = \"http://example.com/feed\"\n" +
+		"next\nrequest = \"GET\"\nurl = \"http://example.com/status\"\n"
+	if got := chained.ConfigFileContents(); got != wantConfig {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, wantConfig)
+	}
+
+	again, _ := ChainCommands(first, second, third)
+	if !chained.Equal(again) || chained.Hash() != again.Hash() {
+		t.Error("equal chains compare different")
+	}
+	if reordered, _ := ChainCommands(first, third, second); chained.Equal(reordered) {
+		t.Error("chains in different orders compare equal")
+	}
+}
+
+func TestChainCommandsVariables(t *testing.T) {
+	login, _ := http.NewRequest("POST", "http://api.example.com/login", nil)
+	first, _ := GetCurlCommand(login, WithVariable("HOST", "api.example.com"))
+	status, _ := http.NewRequest("GET", "http://api.example.com/status", nil)
+	second, _ := GetCurlCommand(status, WithVariable("HOST", "api.example.com"))
+
+	chained, err := ChainCommands(first, second)
+	if err != nil {
+		t.Fatalf("ChainCommands() error = %v", err)
+	}
+	want := "HOST='api.example.com'\n" +
+		`curl -X 'POST' 'http://'"${HOST}"'/login' --next -X 'GET' 'http://'"${HOST}"'/status'`
+	if got := chained.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+
+	wantConfig := "request = \"POST\"\nurl = \"http://api.example.com/login\"\n" +
+		"next\nrequest = \"GET\"\nurl = \"http://api.example.com/status\"\n"
+	if got := chained.ConfigFileContents(); got != wantConfig {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, wantConfig)
+	}
+
+	mustache, _ := GetCurlCommand(status, WithVariable("HOST", "api.example.com"), WithPlaceholderSyntax(PlaceholderMustache))
+	if _, err := ChainCommands(first, mustache); !errors.Is(err, ErrConflictingOptions) {
+		t.Errorf("ChainCommands() with different placeholder syntaxes error = %v, want %v", err, ErrConflictingOptions)
+	}
+}
+
+func TestChainCommandsErrors(t *testing.T) {
+	req, _ := http.NewRequest("POST", "http://example.com", strings.NewReader("meow"))
+	stdin, _ := GetCurlCommand(req, WithHeredocBody())
+	config, _ := GetCurlCommand(req)
+	config.ConfigFile = DefaultConfigFile
+
+	tests := []struct {
+		name string
+		cmds []*CurlCommand
+	}{
+		{name: "no commands"},
+		{name: "two stdin bodies", cmds: []*CurlCommand{stdin, stdin}},
+		{name: "config file", cmds: []*CurlCommand{stdin, config}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ChainCommands(tt.cmds...); !errors.Is(err, ErrConflictingOptions) {
+				t.Errorf("ChainCommands() error = %v, want %v", err, ErrConflictingOptions)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package http2curl
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+	"unicode/utf8"
+)
+
+// windows1252 maps bytes 0x80-0x9F of windows-1252 to Unicode, bytes
+// without a character map to the C1 control of the same value
+var windows1252 = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+// WithTranscodeBody converts bodies in a single-byte charset declared by the
+// Content-Type (ISO-8859-1, windows-1252) to UTF-8 and updates the header.
+// Bodies in other charsets that are not valid UTF-8, like Shift_JIS, are
+// rendered with their bytes hex-escaped so they survive UTF-8 terminals.
+func WithTranscodeBody() CurlOption {
+	return func(c *CurlCommand) {
+		c.TranscodeBody = true
+	}
+}
+
+// transcodeBody converts the body to UTF-8 and returns the Content-Type to
+// render, or an empty string when it is unchanged
+func (c *CurlCommand) transcodeBody(contentType string) string {
+	if !c.hasBody() {
+		return ""
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	charset := strings.ToLower(params["charset"])
+	if err != nil || charset == "" || charset == "utf-8" || charset == "utf8" {
+		return ""
+	}
+
+	var decode func(byte) rune
+	switch charset {
+	case "iso-8859-1", "iso8859-1", "latin1", "l1":
+		decode = func(b byte) rune { return rune(b) }
+	case "windows-1252", "cp1252":
+		decode = func(b byte) rune {
+			if b >= 0x80 && b < 0xA0 {
+				return windows1252[b-0x80]
+			}
+			return rune(b)
+		}
+	case "us-ascii", "ascii":
+		if isASCII(c.Body.Data) {
+			return ""
+		}
+	}
+	if decode == nil {
+		if !utf8.ValidString(c.Body.Data) {
+			c.Body.HexEscaped = true
+		}
+		return ""
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(c.Body.Data); i++ {
+		b.WriteRune(decode(c.Body.Data[i]))
+	}
+	c.Body.Data = b.String()
+	params["charset"] = "utf-8"
+	return mime.FormatMediaType(mediaType, params)
+}
+
+// ansiCEscape quotes s for bash with $'...', hex-escaping control and
+// non-ASCII bytes
+func ansiCEscape(s string) string {
+	var b strings.Builder
+	b.WriteString("$'")
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\'' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c < 0x20 || c >= 0x7F:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteString("'")
+	return b.String()
+}
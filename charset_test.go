@@ -0,0 +1,64 @@
+package http2curl
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestWithTranscodeBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		opts        []CurlOption
+		wantCommand string
+	}{
+		{
+			name:        "ISO-8859-1 body",
+			contentType: "text/plain; charset=ISO-8859-1",
+			body:        []byte("caf\xe9 cr\xe8me"),
+			wantCommand: `curl -X 'POST' -d 'café crème' -H 'Content-Type: text/plain; charset=utf-8' 'http://example.com'`,
+		},
+		{
+			name:        "windows-1252 body",
+			contentType: "text/plain;charset=windows-1252",
+			body:        []byte("\x93quoted\x94 \x80 5"),
+			wantCommand: `curl -X 'POST' -d '“quoted” € 5' -H 'Content-Type: text/plain; charset=utf-8' 'http://example.com'`,
+		},
+		{
+			name:        "Shift_JIS body is hex-escaped",
+			contentType: "text/plain; charset=Shift_JIS",
+			body:        []byte("\x82\xb1\x82\xf1\x82\xc9\x82\xbf\x82\xcd\nit's"),
+			wantCommand: `curl -X 'POST' --data-binary $'\x82\xb1\x82\xf1\x82\xc9\x82\xbf\x82\xcd\nit\'s' -H 'Content-Type: text/plain; charset=Shift_JIS' 'http://example.com'`,
+		},
+		{
+			name:        "hex-escaped body with Content-Length",
+			contentType: "text/plain; charset=Shift_JIS",
+			body:        []byte("\x82\xb1\n"),
+			opts:        []CurlOption{WithContentLength()},
+			wantCommand: `curl -X 'POST' --data-binary $'\x82\xb1\n' -H 'Content-Type: text/plain; charset=Shift_JIS' -H 'Content-Length: 3' 'http://example.com'`,
+		},
+		{
+			name:        "UTF-8 body",
+			contentType: "text/plain; charset=utf-8",
+			body:        []byte("café"),
+			wantCommand: `curl -X 'POST' -d 'café' -H 'Content-Type: text/plain; charset=utf-8' 'http://example.com'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "http://example.com", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			command, err := GetCurlCommand(req, append([]CurlOption{WithTranscodeBody()}, tt.opts...)...)
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if command.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+		})
+	}
+}
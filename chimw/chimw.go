@@ -0,0 +1,48 @@
+// Package chimw provides chi middleware attaching the curl command of each
+// incoming request to its context, with http2curl.SensitiveHeaders redacted.
+//
+//	r := chi.NewRouter()
+//	r.Use(chimw.Middleware(func(command *http2curl.CurlCommand, status int) {
+//		log.Printf("%d, replay with: %s", status, command)
+//	}))
+package chimw
+
+import (
+	"net/http"
+
+	"github.com/chodges15/http2curl/v3"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Middleware returns chi middleware storing the curl command of each request
+// on the request context. If log is not nil it is called for requests
+// answered with a 5xx status.
+func Middleware(log http2curl.ServerErrorLogger, opts ...http2curl.CurlOption) func(http.Handler) http.Handler {
+	converter := http2curl.NewMiddlewareConverter(opts...)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			command, err := converter.Convert(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			r = r.WithContext(http2curl.ContextWithCommand(r.Context(), command))
+			if log == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+			if status := ww.Status(); status >= http.StatusInternalServerError {
+				log(command, status)
+			}
+		})
+	}
+}
+
+// Command returns the curl command stored by Middleware, or nil if there is
+// none
+func Command(r *http.Request) *http2curl.CurlCommand {
+	return http2curl.CommandFromContext(r.Context())
+}
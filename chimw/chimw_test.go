@@ -0,0 +1,41 @@
+package chimw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chodges15/http2curl/v3"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestMiddleware(t *testing.T) {
+	var logged []string
+	var fromContext *http2curl.CurlCommand
+	r := chi.NewRouter()
+	r.Use(Middleware(func(command *http2curl.CurlCommand, status int) {
+		logged = append(logged, http.StatusText(status)+": "+command.String())
+	}))
+	r.Post("/cats", func(w http.ResponseWriter, r *http.Request) {
+		fromContext = Command(r)
+		w.WriteHeader(http.StatusCreated)
+	})
+	r.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest("POST", "http://example.com/cats", strings.NewReader("meow"))
+	req.Header.Set("X-Api-Key", "secret")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://example.com/fail", nil))
+
+	want := `curl -X 'POST' -d 'meow' -H 'X-Api-Key: REDACTED' 'http://example.com/cats'`
+	if fromContext == nil || fromContext.String() != want {
+		t.Errorf("Got:\n%v\nWant:\n%s", fromContext, want)
+	}
+	wantLogged := []string{`Internal Server Error: curl -X 'GET' 'http://example.com/fail'`}
+	if len(logged) != 1 || logged[0] != wantLogged[0] {
+		t.Errorf("Got:\n%q\nWant:\n%q", logged, wantLogged)
+	}
+}
@@ -0,0 +1,9 @@
+module github.com/chodges15/http2curl/v3/chimw
+
+go 1.20
+
+require github.com/chodges15/http2curl/v3 v3.0.0-00010101000000-000000000000
+
+require github.com/go-chi/chi/v5 v5.0.12
+
+replace github.com/chodges15/http2curl/v3 => ../
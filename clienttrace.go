@@ -0,0 +1,71 @@
+package http2curl
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+)
+
+// TraceForRequest returns a ClientTrace rendering the command of req once it
+// has been written, with the headers the transport actually sent, such as
+// User-Agent, Accept-Encoding and a Host differing from the URL, instead of
+// only those set before sending. Attach it with httptrace.WithClientTrace to
+// the context of the request being sent; sink receives the command of each
+// attempt. Bodies without GetBody are copied as they are sent, replacing
+// req.Body, so the trace must be created before the request is sent.
+func TraceForRequest(req *http.Request, sink func(command *CurlCommand, err error), opts ...CurlOption) *httptrace.ClientTrace {
+	converter := NewConverter(opts...)
+	var sent *lockedBuffer
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		sent = &lockedBuffer{}
+		req.Body = &teeReadCloser{Reader: io.TeeReader(req.Body, sent), Closer: req.Body}
+	}
+
+	var mu sync.Mutex
+	written := http.Header{}
+	return &httptrace.ClientTrace{
+		WroteHeaderField: func(key string, values []string) {
+			mu.Lock()
+			defer mu.Unlock()
+			switch key {
+			case ":authority":
+				key = "Host"
+			case ":method", ":path", ":scheme":
+				return
+			}
+			key = http.CanonicalHeaderKey(key)
+			written[key] = append(written[key], values...)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			mu.Lock()
+			header := written
+			written = http.Header{}
+			mu.Unlock()
+
+			render := req.Clone(req.Context())
+			render.Header = header
+			if strings.EqualFold(header.Get("Host"), req.URL.Host) {
+				header.Del("Host")
+			}
+			switch {
+			case sent != nil:
+				render.Body = io.NopCloser(bytes.NewReader(sent.Bytes()))
+			case req.GetBody != nil:
+				body, err := req.GetBody()
+				if err != nil {
+					sink(nil, err)
+					return
+				}
+				render.Body = body
+			}
+			command, err := converter.Convert(render)
+			if err != nil {
+				command = nil
+			}
+			sink(command, err)
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package http2curl
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strings"
+	"testing"
+)
+
+func TestTraceForRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		body    func() io.Reader
+		host    string
+		headers map[string]string
+		want    string
+	}{
+		{
+			name: "transport headers",
+			want: `curl -X 'GET' -H 'Accept-Encoding: gzip' -H 'User-Agent: Go-http-client/1.1' '` + server.URL + `/cats'`,
+		},
+		{
+			name:    "host override and streamed body",
+			body:    func() io.Reader { return io.MultiReader(strings.NewReader("meow")) },
+			host:    "cats.example.com",
+			headers: map[string]string{"X-Cat": "tom"},
+			want: `curl -X 'GET' -d 'meow' -H 'Accept-Encoding: gzip' -H 'Host: cats.example.com' ` +
+				`-H 'Transfer-Encoding: chunked' -H 'User-Agent: Go-http-client/1.1' -H 'X-Cat: tom' '` + server.URL + `/cats'`,
+		},
+		{
+			name: "rewindable body",
+			body: func() io.Reader { return strings.NewReader("meow") },
+			want: `curl -X 'GET' -d 'meow' -H 'Accept-Encoding: gzip' -H 'User-Agent: Go-http-client/1.1' '` + server.URL + `/cats'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader
+			if tt.body != nil {
+				body = tt.body()
+			}
+			req, _ := http.NewRequest("GET", server.URL+"/cats", body)
+			req.Host = tt.host
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			var got []string
+			trace := TraceForRequest(req, func(command *CurlCommand, err error) {
+				if err != nil {
+					t.Errorf("sink error = %v", err)
+					return
+				}
+				got = append(got, command.String())
+			})
+			resp, err := http.DefaultClient.Do(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			resp.Body.Close()
+
+			if len(got) != 1 || got[0] != tt.want {
+				t.Errorf("Got:\n%s\nWant:\n%s", strings.Join(got, "\n"), tt.want)
+			}
+		})
+	}
+}
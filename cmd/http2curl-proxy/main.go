@@ -0,0 +1,59 @@
+// Command http2curl-proxy is a reverse proxy printing the curl command of
+// every request it forwards:
+//
+//	http2curl-proxy -listen :8080 -target https://api.example.com
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/chodges15/http2curl/v3"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run serves the proxy until it fails, returning so deferred calls run
+// before main exits
+func run() error {
+	var (
+		listen = flag.String("listen", "localhost:8080", "address to listen on")
+		target = flag.String("target", "", "URL requests are forwarded to")
+		output = flag.String("o", "", "file commands are appended to, stdout when empty")
+		redact = flag.String("redact", "Authorization,Cookie", "comma separated headers whose values are redacted")
+	)
+	flag.Parse()
+
+	u, err := url.Parse(*target)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid -target %q", *target)
+	}
+
+	sink := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.OpenFile(*output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		sink = f
+	}
+
+	var opts []http2curl.CurlOption
+	if *redact != "" {
+		opts = append(opts, http2curl.WithRedactedHeaders(strings.Split(*redact, ",")...))
+	}
+
+	log.Printf("forwarding %s to %s", *listen, u)
+	return http.ListenAndServe(*listen, http2curl.NewDebugProxy(u, sink, opts...))
+}
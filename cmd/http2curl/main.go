@@ -0,0 +1,306 @@
+// Command http2curl reads a captured HTTP request and prints the equivalent
+// curl command.
+//
+// The input may be a raw HTTP/1.x request, such as the output of
+// httputil.DumpRequest, or a HAR archive, in which case one command is
+// printed per entry:
+//
+//	http2curl [flags] [file]
+//
+// The request is read from stdin when no file is given.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/chodges15/http2curl/v3"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "http2curl:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+// listFlag is a comma separated list of values which may be repeated
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("http2curl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: http2curl [flags] [file]")
+		fs.PrintDefaults()
+	}
+
+	var (
+		format            = fs.String("format", "auto", "input format: auto, raw or har")
+		scheme            = fs.String("scheme", "http", "scheme of raw requests: http or https")
+		insecure          = fs.Bool("k", false, "add -k to https commands")
+		compressed        = fs.Bool("compressed", false, "add --compressed")
+		autoCompressed    = fs.Bool("auto-compressed", false, "use --compressed instead of gzip/br Accept-Encoding headers")
+		decompress        = fs.Bool("decompress", false, "decompress gzip bodies")
+		escapedNewlines   = fs.Bool("escaped-newlines", false, "escape newlines in the body")
+		heredoc           = fs.Bool("heredoc", false, "pass the body through a here-document")
+		curlJSON          = fs.Bool("json", false, "use --json for JSON bodies")
+		contentLength     = fs.Bool("content-length", false, "add a Content-Length header")
+		graphQL           = fs.Bool("graphql", false, "render GraphQL bodies readably")
+		prettyXML         = fs.Bool("pretty-xml", false, "indent XML bodies")
+		transcode         = fs.Bool("transcode", false, "convert bodies in other charsets to UTF-8")
+		exactURL          = fs.Bool("exact-url", false, "render the path and query exactly as sent")
+		forwarded         = fs.Bool("forwarded", false, "reconstruct raw request URLs from Forwarded headers")
+		longRequest       = fs.Bool("long-request", false, "use --request instead of -X")
+		noGoHeaders       = fs.Bool("no-go-headers", false, "drop headers added by the Go HTTP client")
+		strict            = fs.Bool("strict", false, "fail instead of approximating the request")
+		maxBodySize       = fs.Int64("max-body-size", 0, "maximum body size in bytes, 0 for no limit")
+		doubleQuotes      = fs.Bool("double-quotes", false, "quote arguments with double quotes")
+		windows           = fs.Bool("windows", false, "render for curl.exe run from cmd.exe")
+		posix             = fs.Bool("posix", false, "avoid bash-isms such as echo -e and $'...'")
+		formFields        = fs.Bool("form-fields", false, "render urlencoded bodies with one -d per field")
+		multipartForm     = fs.Bool("multipart", false, "render multipart bodies with -F, extracting files to the temporary directory")
+		normalizeJSON     = fs.Bool("normalize-json", false, "compact JSON bodies and sort their keys")
+		maxTime           = fs.Duration("max-time", 0, "limit the whole transfer with --max-time")
+		trace             = fs.String("trace", "", "make curl write a wire trace to this file")
+		traceASCII        = fs.Bool("trace-ascii", false, "write the trace with --trace-ascii")
+		redact, include   listFlag
+		exclude, comments listFlag
+		flagOrder         listFlag
+	)
+	fs.Var(&redact, "redact", "comma separated headers whose values are redacted")
+	fs.Var(&include, "include", "comma separated headers to render")
+	fs.Var(&exclude, "exclude", "comma separated headers to drop")
+	fs.Var(&comments, "comment", "comment to add above the command")
+	fs.Var(&flagOrder, "flag-order", "comma separated order of the command segments: tls, transport, method, data, headers, url and extra")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	order, err := parseFlagOrder(flagOrder)
+	if err != nil {
+		return err
+	}
+
+	var opts []http2curl.CurlOption
+	for _, o := range []struct {
+		set bool
+		opt http2curl.CurlOption
+	}{
+		{*insecure, http2curl.WithInsecureSkipVerify()},
+		{*compressed, http2curl.WithCompression()},
+		{*autoCompressed, http2curl.WithAutoCompressedFlag()},
+		{*decompress, http2curl.WithAutoDecompressGZIP()},
+		{*escapedNewlines, http2curl.WithEscapedNewlines()},
+		{*heredoc, http2curl.WithHeredocBody()},
+		{*curlJSON, http2curl.WithCurlJSON()},
+		{*contentLength, http2curl.WithContentLength()},
+		{*graphQL, http2curl.WithGraphQL()},
+		{*prettyXML, http2curl.WithPrettyXML()},
+		{*transcode, http2curl.WithTranscodeBody()},
+		{*exactURL, http2curl.WithExactURL()},
+		{*forwarded, http2curl.WithForwardedHeaders()},
+		{*longRequest, http2curl.WithLongRequestFlag()},
+		{*noGoHeaders, http2curl.WithoutDefaultGoHeaders()},
+		{*strict, http2curl.WithStrict()},
+		{*maxBodySize > 0, http2curl.WithMaxBodySize(*maxBodySize)},
+		{*doubleQuotes, http2curl.WithDoubleQuoteEscaping()},
+		{*windows, http2curl.WithWindowsCurl()},
+		{*posix, http2curl.WithPOSIXPortability()},
+		{*formFields, http2curl.WithFormFields()},
+		{*multipartForm, http2curl.WithMultipartForm()},
+		{*normalizeJSON, http2curl.WithNormalizedJSON()},
+		{*maxTime > 0, http2curl.WithMaxTime(*maxTime)},
+		{*trace != "" || *traceASCII, http2curl.WithTrace(*trace, *traceASCII)},
+		{len(order) > 0, http2curl.WithFlagOrder(order...)},
+		{len(redact) > 0, http2curl.WithRedactedHeaders(redact...)},
+		{len(include) > 0, http2curl.WithIncludeHeaders(include...)},
+		{len(exclude) > 0, http2curl.WithExcludeHeaders(exclude...)},
+	} {
+		if o.set {
+			opts = append(opts, o.opt)
+		}
+	}
+	for _, comment := range comments {
+		opts = append(opts, http2curl.WithComment("%s", comment))
+	}
+
+	input := stdin
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	default:
+		fs.Usage()
+		return errors.New("too many arguments")
+	}
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return err
+	}
+
+	reqs, err := parseInput(data, *format, *scheme)
+	if err != nil {
+		return err
+	}
+	for i, req := range reqs {
+		command, err := http2curl.GetCurlCommand(req, opts...)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+		fmt.Fprintln(stdout, command)
+	}
+	return nil
+}
+
+// flagClasses are the -flag-order names of the command segments
+var flagClasses = map[string]http2curl.FlagClass{
+	"tls":       http2curl.FlagClassTLS,
+	"transport": http2curl.FlagClassTransport,
+	"method":    http2curl.FlagClassMethod,
+	"data":      http2curl.FlagClassData,
+	"headers":   http2curl.FlagClassHeaders,
+	"url":       http2curl.FlagClassURL,
+	"extra":     http2curl.FlagClassExtra,
+}
+
+// parseFlagOrder returns the segments named in names
+func parseFlagOrder(names []string) ([]http2curl.FlagClass, error) {
+	order := make([]http2curl.FlagClass, 0, len(names))
+	for _, name := range names {
+		class, ok := flagClasses[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown flag order segment %q", name)
+		}
+		order = append(order, class)
+	}
+	return order, nil
+}
+
+// parseInput returns the requests captured in data
+func parseInput(data []byte, format, scheme string) ([]*http.Request, error) {
+	if format == "auto" {
+		format = "raw"
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+			format = "har"
+		}
+	}
+	switch format {
+	case "raw":
+		req, err := parseRaw(data, scheme)
+		if err != nil {
+			return nil, err
+		}
+		return []*http.Request{req}, nil
+	case "har":
+		return parseHAR(data)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// parseRaw reads a raw HTTP/1.x request as received by a server
+func parseRaw(data []byte, scheme string) (*http.Request, error) {
+	// Captures edited by hand often use bare newlines
+	if !bytes.Contains(data, []byte("\r\n")) {
+		head, body, _ := bytes.Cut(data, []byte("\n\n"))
+		data = append(bytes.ReplaceAll(head, []byte("\n"), []byte("\r\n")), "\r\n\r\n"...)
+		data = append(data, body...)
+	}
+	req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid raw request: %w", err)
+	}
+	switch scheme {
+	case "http":
+	case "https":
+		req.TLS = &tls.ConnectionState{}
+	default:
+		return nil, fmt.Errorf("unknown scheme %q", scheme)
+	}
+	return req, nil
+}
+
+// har is the subset of the HTTP Archive format describing requests
+type har struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method  string `json:"method"`
+				URL     string `json:"url"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+				PostData *struct {
+					MimeType string `json:"mimeType"`
+					Text     string `json:"text"`
+				} `json:"postData"`
+			} `json:"request"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// parseHAR returns the request of every entry of a HAR archive
+func parseHAR(data []byte) ([]*http.Request, error) {
+	var archive har
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("invalid HAR archive: %w", err)
+	}
+	if len(archive.Log.Entries) == 0 {
+		return nil, errors.New("HAR archive has no entries")
+	}
+
+	reqs := make([]*http.Request, 0, len(archive.Log.Entries))
+	for i, entry := range archive.Log.Entries {
+		var body io.Reader
+		if entry.Request.PostData != nil {
+			body = strings.NewReader(entry.Request.PostData.Text)
+		}
+		req, err := http.NewRequest(entry.Request.Method, entry.Request.URL, body)
+		if err != nil {
+			return nil, fmt.Errorf("HAR entry %d: %w", i, err)
+		}
+		for _, h := range entry.Request.Headers {
+			// HTTP/2 pseudo headers and Host are part of the URL
+			if strings.HasPrefix(h.Name, ":") || strings.EqualFold(h.Name, "Host") {
+				continue
+			}
+			req.Header.Add(h.Name, h.Value)
+		}
+		if entry.Request.PostData != nil && entry.Request.PostData.MimeType != "" && req.Header.Get("Content-Type") == "" {
+			req.Header.Set("Content-Type", entry.Request.PostData.MimeType)
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
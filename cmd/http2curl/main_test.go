@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		input       string
+		wantCommand string
+		wantErr     bool
+	}{
+		{
+			name: "raw request dump",
+			input: "POST /cats?name=tom HTTP/1.1\r\n" +
+				"Host: example.com\r\n" +
+				"Content-Length: 4\r\n" +
+				"Content-Type: text/plain\r\n" +
+				"X-Auth-Token: private-token\r\n" +
+				"\r\n" +
+				"meow",
+			wantCommand: `curl -X 'POST' -d 'meow' -H 'Content-Type: text/plain' -H 'X-Auth-Token: private-token' 'http://example.com/cats?name=tom'` + "\n",
+		},
+		{
+			name: "raw request with bare newlines and flags",
+			args: []string{"-scheme", "https", "-k", "-redact", "X-Auth-Token"},
+			input: "GET /cats HTTP/1.1\n" +
+				"Host: example.com\n" +
+				"X-Auth-Token: private-token\n" +
+				"\n",
+			wantCommand: `curl -k -X 'GET' -H 'X-Auth-Token: REDACTED' 'https://example.com/cats'` + "\n",
+		},
+		{
+			name: "HAR archive",
+			input: `{"log": {"entries": [
+				{"request": {"method": "GET", "url": "https://example.com/a", "headers": [
+					{"name": ":authority", "value": "example.com"},
+					{"name": "Accept", "value": "*/*"}
+				]}},
+				{"request": {"method": "PUT", "url": "https://example.com/b", "headers": [],
+					"postData": {"mimeType": "application/json", "text": "{\"a\":1}"}}}
+			]}}`,
+			wantCommand: `curl -X 'GET' -H 'Accept: */*' 'https://example.com/a'` + "\n\n" +
+				`curl -X 'PUT' -d '{"a":1}' -H 'Content-Type: application/json' 'https://example.com/b'` + "\n",
+		},
+		{
+			name: "rendering flags",
+			args: []string{"-windows", "-max-time", "2.5s", "-flag-order", "url,method"},
+			input: "GET /cats HTTP/1.1\r\n" +
+				"Host: example.com\r\n" +
+				"\r\n",
+			wantCommand: `curl "http://example.com/cats" -X "GET" --max-time 2.5` + "\n",
+		},
+		{
+			name: "body flags",
+			args: []string{"-double-quotes", "-form-fields", "-trace-ascii"},
+			input: "POST /cats HTTP/1.1\r\n" +
+				"Host: example.com\r\n" +
+				"Content-Length: 14\r\n" +
+				"Content-Type: application/x-www-form-urlencoded\r\n" +
+				"\r\n" +
+				"name=tom&age=3",
+			wantCommand: `curl -X "POST" -d "name=tom" -d "age=3" -H "Content-Type: application/x-www-form-urlencoded" "http://example.com/cats" --trace-ascii "curl-trace.txt" --trace-time` + "\n",
+		},
+		{
+			name: "normalized JSON",
+			args: []string{"-normalize-json", "-posix"},
+			input: "POST /cats HTTP/1.1\r\n" +
+				"Host: example.com\r\n" +
+				"Content-Length: 19\r\n" +
+				"\r\n" +
+				`{ "b": 1, "a": 2 }` + "\n",
+			wantCommand: `curl -X 'POST' -d '{"a":2,"b":1}' 'http://example.com/cats'` + "\n",
+		},
+		{
+			name:    "unknown flag order segment",
+			args:    []string{"-flag-order", "url,body"},
+			input:   "GET / HTTP/1.1\r\n\r\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			args:    []string{"-format", "pcap"},
+			input:   "GET / HTTP/1.1\r\n\r\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid raw request",
+			input:   "not a request",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := run(tt.args, strings.NewReader(tt.input), &stdout, &stderr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && stdout.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", stdout.String(), tt.wantCommand)
+			}
+		})
+	}
+}
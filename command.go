@@ -0,0 +1,415 @@
+package http2curl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Header is a single header line passed to curl with -H
+type Header struct {
+	Key   string
+	Value string
+}
+
+// BodySpec describes the request body passed to curl
+type BodySpec struct {
+	Data       string // Raw body contents
+	HexEscaped bool   // Render with bash ANSI-C quoting, hex-escaping non-ASCII bytes
+	Chunked    bool   // Stream through standard input with chunked transfer encoding
+	Base64     bool   // Pipe through base64 --decode, for exact binary replay
+	Fields     bool   // Render each &-separated field of a form with its own -d
+}
+
+// CurlCommand holds the structured form of a curl command and the
+// configuration options used to generate it.
+//
+// A generated command is safe for concurrent use by multiple goroutines as
+// long as none of them modifies it: String, MultiLineString, Hash, Equal,
+// ConfigFileContents and Clone only read it. To change a shared command,
+// modify a copy made with Clone or Derive.
+type CurlCommand struct {
+	Method   string
+	URL      string
+	Headers  []Header
+	Body     *BodySpec
+	Flags    []string // Extra flags appended after the URL
+	Comments []string // Annotations rendered as shell comments
+
+	SuppressHeaders []string   // Headers curl adds by default, removed with "Key:", e.g. Accept
+	FooterComments  []string   // Annotations rendered as shell comments below the command
+	Variables       []Variable // Variables referenced in place of their values
+	FormParts       []FormPart // Multipart form parts passed with -F in place of Body
+	Cookies         string     // -b, "name=value; ..." pairs from a cookie jar
+	CookieFile      string     // -b, read cookies from this file
+	CookieJarFile   string     // -c, save received cookies to this file
+	OutputFile      string     // -o, write the response body to this file
+	WriteOut        string     // -w, printed after the request completes
+
+	PlaceholderSyntax PlaceholderSyntax // How Variables are referenced
+
+	Next []*CurlCommand // Requests run after this one with --next, see ChainCommands
+
+	Proxy         string // -x
+	ProxyTunnel   bool   // -p
+	RequestTarget string // --request-target
+	TraceFile     string // --trace, or --trace-ascii with TraceASCII
+	TraceASCII    bool
+
+	MaxTime        time.Duration // --max-time, 0 for none
+	ConnectTimeout time.Duration // --connect-timeout, 0 for none
+	Resolve        []string      // --resolve host:port:addr entries pinning hosts to addresses
+	TLSMinVersion  uint16        // --tlsv1.x, a tls.VersionTLS1x constant or 0 for none
+	TLSMaxVersion  uint16        // --tls-max, a tls.VersionTLS1x constant or 0 for none
+	Ciphers        []uint16      // Go cipher suites for --ciphers and --tls13-ciphers
+	PinnedPubKey   string        // --pinnedpubkey, a key file or sha256// hashes
+	SocksProxy     string        // SOCKS proxy host:port, used with SocksVersion
+	SocksVersion   SocksVersion
+	HTTPVersion    string // --http2 or --http2-prior-knowledge, set with HTTP2Flags or H2CPriorKnowledge
+
+	InsecureSkipVerify bool     // -k
+	EnableCompression  bool     // --compressed
+	AutoCompressedFlag bool     // --compressed instead of a gzip/br Accept-Encoding header
+	AutoDecompressGZIP bool     // Automatically decompress GZIP request
+	MaxBodySize        int64    // Maximum body size in bytes, 0 for no limit
+	BodyPeek           int      // Render at most this many body bytes, leaving the rest unread
+	RedactHeaders      []string // Headers whose values are replaced with REDACTED
+	IncludeHeaders     []string // Headers to render, all when empty
+	ExcludeHeaders     []string // Headers to drop
+	EscapedNewlines    bool     // Escape newline characters in the curl command
+	HeredocBody        bool     // Pass the body verbatim through a here-document
+	ChunkedBody        bool     // Stream bodies of unknown length with chunked transfer encoding
+	RejectControlChars bool     // Fail on control characters in headers instead of stripping them
+	RejectSecrets      bool     // Fail on secrets found by the secret scanners instead of redacting them
+	Strict             bool     // Fail with ErrLossyConversion instead of approximating the request
+	DoubleQuotes       bool     // Quote values with double quotes instead of single quotes
+	CurlJSON           bool     // --json
+	ContentLength      bool     // Emit a Content-Length header computed from the rendered body
+	InlineComments     bool     // Render comments on the command line instead of above it
+	TimingComments     bool     // List the timings recorded by TraceTimings below the command
+	BracketIPv6        bool     // Bracket IPv6 literal hosts missing brackets
+	PunycodeHost       bool     // Render internationalized hosts in ACE form
+	UnicodeHost        bool     // Render internationalized hosts in Unicode
+	ExactURL           bool     // Render the path and query exactly as sent
+	ForwardedHeaders   bool     // Reconstruct server side URLs from Forwarded headers
+	HTTP2Flags         bool     // Select HTTP/2 for requests received over HTTP/2
+	H2CPriorKnowledge  bool     // --http2-prior-knowledge for http:// requests that went over h2c
+	FailQuietly        bool     // -fsS, failing on HTTP errors and printing only errors
+	WindowsCurl        bool     // Render for curl.exe run from a cmd.exe batch file
+	PowerShell         bool     // Render for curl.exe run from PowerShell
+	POSIXPortable      bool     // Avoid bash-isms such as echo -e and $'...'
+	IncludeFragment    bool     // Include the fragment of server side URLs
+	LongRequestFlag    bool     // --request instead of -X
+	ImplicitPost       bool     // Leave out -X for POST requests whose body implies the method
+	GraphQL            bool     // Render GraphQL bodies readably
+	NormalizeJSON      bool     // Compact JSON bodies and sort their keys
+	FormFields         bool     // Render urlencoded bodies with one -d per field
+	MultipartForm      bool     // Render multipart/form-data bodies with one -F per part
+	PrettyXML          bool     // Indent XML bodies
+	TranscodeBody      bool     // Convert bodies in other charsets to UTF-8
+	MaxCommandLength   int      // Maximum rendered length before falling back to files
+
+	PreserveMultipartBoundary bool  // Send multipart bodies exactly, instead of with -F
+	MaxDecompressedSize       int64 // Maximum decompressed body size in bytes, 0 for no limit
+	TruncateDecompressed      bool  // Truncate bodies over MaxDecompressedSize instead of failing
+
+	FlagOrder     []FlagClass // Order of the command line segments, DefaultFlagOrder when empty
+	CompatVersion string      // Earlier release whose rendering defaults are kept, e.g. CompatV1
+
+	BodyFile   string         // Read the body from this file instead of the command line
+	ConfigFile string         // Read all options from this curl config file
+	Fallback   LengthFallback // How the command was shortened to fit MaxCommandLength
+
+	headerTransformers []HeaderTransformer
+	urlRewriters       []func(*url.URL) *url.URL
+	annotators         []annotator
+	metrics            Metrics
+	secretScanners     []SecretScanner
+	protoDecoders      []ProtoDecoder
+	fileResolver       MultipartFileResolver
+	tempDir            string
+	proxyFunc          func(*http.Request) (*url.URL, error)
+	resolver           HostResolver
+	cookieJar          http.CookieJar
+
+	bodyBytes  int64         // Request body bytes buffered during generation
+	duration   time.Duration // Time spent generating the command
+	redactions []Redaction   // Values redacted during generation
+	warnings   []LossWarning // Approximations made during generation
+	tempFiles  []string      // Files extracted from multipart bodies
+
+	bodyTruncated bool // Body cut to BodyPeek bytes during generation
+
+	cache *renderCache
+}
+
+// SetHeader sets the header entry associated with key to value, replacing
+// any existing entries with the same key
+func (c *CurlCommand) SetHeader(key, value string) {
+	c.RemoveHeader(key)
+	i := sort.Search(len(c.Headers), func(i int) bool { return c.Headers[i].Key >= key })
+	c.Headers = append(c.Headers, Header{})
+	copy(c.Headers[i+1:], c.Headers[i:])
+	c.Headers[i] = Header{Key: key, Value: value}
+}
+
+// RemoveHeader removes all header entries associated with key
+func (c *CurlCommand) RemoveHeader(key string) {
+	headers := c.Headers[:0]
+	for _, h := range c.Headers {
+		if !strings.EqualFold(h.Key, key) {
+			headers = append(headers, h)
+		}
+	}
+	c.Headers = headers
+}
+
+// SetURLHost replaces the host (and port) of the command URL
+func (c *CurlCommand) SetURLHost(host string) error {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("url parse error: %w", err)
+	}
+	u.Host = host
+	c.URL = u.String()
+	return nil
+}
+
+// SetQueryParam sets the query parameter key to value in the command URL,
+// replacing any existing values
+func (c *CurlCommand) SetQueryParam(key, value string) error {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("url parse error: %w", err)
+	}
+	query := u.Query()
+	query.Set(key, value)
+	u.RawQuery = query.Encode()
+	c.URL = u.String()
+	return nil
+}
+
+// AddFlag appends extra flags to the command, e.g. AddFlag("--max-time", "10")
+func (c *CurlCommand) AddFlag(flags ...string) {
+	c.Flags = append(c.Flags, flags...)
+}
+
+// Clone returns a deep copy of the command which can be safely mutated
+// independently of the original
+func (c *CurlCommand) Clone() *CurlCommand {
+	clone := *c
+	clone.cache = &renderCache{}
+	clone.Headers = append([]Header(nil), c.Headers...)
+	clone.Flags = append([]string(nil), c.Flags...)
+	clone.Comments = append([]string(nil), c.Comments...)
+	clone.FooterComments = append([]string(nil), c.FooterComments...)
+	clone.SuppressHeaders = append([]string(nil), c.SuppressHeaders...)
+	clone.Variables = append([]Variable(nil), c.Variables...)
+	clone.FormParts = append([]FormPart(nil), c.FormParts...)
+	clone.RedactHeaders = append([]string(nil), c.RedactHeaders...)
+	clone.IncludeHeaders = append([]string(nil), c.IncludeHeaders...)
+	clone.ExcludeHeaders = append([]string(nil), c.ExcludeHeaders...)
+	clone.Ciphers = append([]uint16(nil), c.Ciphers...)
+	clone.Resolve = append([]string(nil), c.Resolve...)
+	clone.FlagOrder = append([]FlagClass(nil), c.FlagOrder...)
+	clone.headerTransformers = append([]HeaderTransformer(nil), c.headerTransformers...)
+	clone.urlRewriters = append([]func(*url.URL) *url.URL(nil), c.urlRewriters...)
+	clone.annotators = append([]annotator(nil), c.annotators...)
+	clone.secretScanners = append([]SecretScanner(nil), c.secretScanners...)
+	clone.protoDecoders = append([]ProtoDecoder(nil), c.protoDecoders...)
+	clone.redactions = append([]Redaction(nil), c.redactions...)
+	clone.warnings = append([]LossWarning(nil), c.warnings...)
+	clone.tempFiles = append([]string(nil), c.tempFiles...)
+	if c.Body != nil {
+		body := *c.Body
+		clone.Body = &body
+	}
+	if c.Next != nil {
+		clone.Next = make([]*CurlCommand, len(c.Next))
+		for i, next := range c.Next {
+			clone.Next[i] = next.Clone()
+		}
+	}
+	return &clone
+}
+
+// Derive returns a copy of the command changed by modify, leaving the
+// original untouched for the goroutines sharing it, e.g.
+// command.Derive(func(c *CurlCommand) { c.SetHeader("Authorization", "REDACTED") })
+func (c *CurlCommand) Derive(modify func(*CurlCommand)) *CurlCommand {
+	derived := c.Clone()
+	modify(derived)
+	return derived
+}
+
+// Equal reports whether both commands describe the same request, ignoring
+// the order of headers and flags and any comments
+func (c *CurlCommand) Equal(other *CurlCommand) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	if c.Method != other.Method || c.URL != other.URL ||
+		c.Proxy != other.Proxy || c.ProxyTunnel != other.ProxyTunnel ||
+		c.RequestTarget != other.RequestTarget ||
+		c.MaxTime != other.MaxTime || c.ConnectTimeout != other.ConnectTimeout ||
+		c.TLSMinVersion != other.TLSMinVersion || c.TLSMaxVersion != other.TLSMaxVersion ||
+		c.PinnedPubKey != other.PinnedPubKey ||
+		c.SocksProxy != other.SocksProxy || c.SocksVersion != other.SocksVersion ||
+		c.HTTPVersion != other.HTTPVersion || c.Cookies != other.Cookies ||
+		c.CookieFile != other.CookieFile || c.CookieJarFile != other.CookieJarFile ||
+		c.InsecureSkipVerify != other.InsecureSkipVerify ||
+		c.EnableCompression != other.EnableCompression ||
+		c.EscapedNewlines != other.EscapedNewlines ||
+		c.HeredocBody != other.HeredocBody ||
+		c.CurlJSON != other.CurlJSON || c.ImplicitPost != other.ImplicitPost {
+		return false
+	}
+	if (c.Body == nil) != (other.Body == nil) || (c.Body != nil && *c.Body != *other.Body) {
+		return false
+	}
+	if len(c.FormParts) != len(other.FormParts) {
+		return false
+	}
+	for i := range c.FormParts {
+		if c.FormParts[i] != other.FormParts[i] {
+			return false
+		}
+	}
+	if len(c.Next) != len(other.Next) {
+		return false
+	}
+	for i := range c.Next {
+		if !c.Next[i].Equal(other.Next[i]) {
+			return false
+		}
+	}
+	return equalUnordered(headerLines(c.Headers), headerLines(other.Headers)) &&
+		equalUnordered(headerKeys(c.SuppressHeaders), headerKeys(other.SuppressHeaders)) &&
+		equalUnordered(c.Flags, other.Flags) && equalUnordered(c.Resolve, other.Resolve)
+}
+
+// headerLines returns the headers as "key: value" lines with canonical keys
+func headerLines(headers []Header) []string {
+	lines := make([]string, 0, len(headers))
+	for _, h := range headers {
+		lines = append(lines, http.CanonicalHeaderKey(h.Key)+": "+h.Value)
+	}
+	return lines
+}
+
+// headerKeys returns the canonical form of the keys
+func headerKeys(keys []string) []string {
+	canonical := make([]string, len(keys))
+	for i, k := range keys {
+		canonical[i] = http.CanonicalHeaderKey(k)
+	}
+	return canonical
+}
+
+func equalUnordered(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// VolatileHeaders lists headers that usually differ between otherwise
+// identical requests, for use with Hash
+var VolatileHeaders = []string{
+	"Date",
+	"Traceparent",
+	"Tracestate",
+	"X-Amzn-Trace-Id",
+	"X-B3-Spanid",
+	"X-B3-Traceid",
+	"X-Cloud-Trace-Context",
+	"X-Request-Id",
+}
+
+// Hash returns a stable hex-encoded SHA-256 digest of the normalized command,
+// ignoring header and flag order, comments and the given headers, e.g.
+// Hash(VolatileHeaders...)
+func (c *CurlCommand) Hash(excludeHeaders ...string) string {
+	headers := make([]Header, 0, len(c.Headers))
+	for _, h := range c.Headers {
+		if !containsFold(excludeHeaders, h.Key) {
+			headers = append(headers, h)
+		}
+	}
+	lines := headerLines(headers)
+	sort.Strings(lines)
+	flags := append([]string(nil), c.Flags...)
+	sort.Strings(flags)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%t\x00%s\x00", c.Proxy, c.ProxyTunnel, c.RequestTarget)
+	fmt.Fprintf(hash, "%s\x00%s\x00%t\x00%t\x00%t\x00%t\x00%t\x00", c.Method, c.URL,
+		c.InsecureSkipVerify, c.EnableCompression, c.EscapedNewlines, c.HeredocBody, c.CurlJSON)
+	if c.ImplicitPost {
+		fmt.Fprint(hash, "I\x00")
+	}
+	if c.SocksProxy != "" {
+		fmt.Fprintf(hash, "S%s\x00%d\x00", c.SocksProxy, c.SocksVersion)
+	}
+	if c.HTTPVersion != "" {
+		fmt.Fprintf(hash, "P%s\x00", c.HTTPVersion)
+	}
+	resolve := append([]string(nil), c.Resolve...)
+	sort.Strings(resolve)
+	for _, entry := range resolve {
+		fmt.Fprintf(hash, "R%q\x00", entry)
+	}
+	if c.Cookies != "" || c.CookieFile != "" || c.CookieJarFile != "" {
+		fmt.Fprintf(hash, "C%q\x00%q\x00%q\x00", c.Cookies, c.CookieFile, c.CookieJarFile)
+	}
+	if c.MaxTime != 0 || c.ConnectTimeout != 0 {
+		fmt.Fprintf(hash, "T%d\x00%d\x00", c.MaxTime, c.ConnectTimeout)
+	}
+	if c.TLSMinVersion != 0 || c.TLSMaxVersion != 0 || len(c.Ciphers) > 0 || c.PinnedPubKey != "" {
+		fmt.Fprintf(hash, "V%d\x00%d\x00%v\x00%s\x00", c.TLSMinVersion, c.TLSMaxVersion, c.Ciphers, c.PinnedPubKey)
+	}
+	for _, line := range lines {
+		fmt.Fprintf(hash, "H%q\x00", line)
+	}
+	for _, flag := range flags {
+		fmt.Fprintf(hash, "F%q\x00", flag)
+	}
+	suppressed := headerKeys(c.SuppressHeaders)
+	sort.Strings(suppressed)
+	for _, k := range suppressed {
+		fmt.Fprintf(hash, "S%q\x00", k)
+	}
+	for _, part := range c.FormParts {
+		fmt.Fprintf(hash, "P%q\x00%q\x00%q\x00%q\x00%q\x00", part.Name, part.Value, part.File, part.Filename, part.ContentType)
+	}
+	for _, next := range c.Next {
+		fmt.Fprintf(hash, "N%s\x00", next.Hash(excludeHeaders...))
+	}
+	if c.Body != nil {
+		fmt.Fprintf(hash, "B%q", c.Body.Data)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,287 @@
+package http2curl
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGetCurlCommandFields(t *testing.T) {
+	req, _ := http.NewRequest("POST", "http://example.com/cats?name=tom", bytes.NewBufferString("meow"))
+	req.Header.Set("X-Auth-Token", "private-token")
+	req.Header.Set("Content-Type", "text/plain")
+
+	command, err := GetCurlCommand(req)
+	if err != nil {
+		t.Fatalf("GetCurlCommand() error = %v", err)
+	}
+
+	if command.Method != "POST" {
+		t.Errorf("Method = %q, want %q", command.Method, "POST")
+	}
+	if command.URL != "http://example.com/cats?name=tom" {
+		t.Errorf("URL = %q, want %q", command.URL, "http://example.com/cats?name=tom")
+	}
+	wantHeaders := []Header{
+		{Key: "Content-Type", Value: "text/plain"},
+		{Key: "X-Auth-Token", Value: "private-token"},
+	}
+	if !reflect.DeepEqual(command.Headers, wantHeaders) {
+		t.Errorf("Headers = %v, want %v", command.Headers, wantHeaders)
+	}
+	if command.Body == nil || command.Body.Data != "meow" {
+		t.Errorf("Body = %v, want %q", command.Body, "meow")
+	}
+}
+
+func TestCurlCommandString(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     *CurlCommand
+		wantCommand string
+	}{
+		{
+			name:        "method and URL only",
+			command:     &CurlCommand{Method: "GET", URL: "http://example.com"},
+			wantCommand: `curl -X 'GET' 'http://example.com'`,
+		},
+		{
+			name: "headers, body and flags",
+			command: &CurlCommand{
+				Method:  "POST",
+				URL:     "https://example.com",
+				Headers: []Header{{Key: "Accept", Value: "*/*"}},
+				Body:    &BodySpec{Data: "o'neill"},
+				Flags:   []string{"--fail"},
+
+				InsecureSkipVerify: true,
+				EnableCompression:  true,
+			},
+			wantCommand: `curl -k -X 'POST' -d 'o'\''neill' -H 'Accept: */*' 'https://example.com' --compressed --fail`,
+		},
+		{
+			name: "modified fields are rendered",
+			command: func() *CurlCommand {
+				req, _ := http.NewRequest("GET", "http://example.com", nil)
+				command, _ := GetCurlCommand(req)
+				command.Method = "DELETE"
+				command.URL = "http://staging.example.com"
+				return command
+			}(),
+			wantCommand: `curl -X 'DELETE' 'http://staging.example.com'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.command.String(); got != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestCurlCommandMutation(t *testing.T) {
+	tests := []struct {
+		name        string
+		mutate      func(c *CurlCommand) error
+		wantCommand string
+		wantErr     bool
+	}{
+		{
+			name: "set new header",
+			mutate: func(c *CurlCommand) error {
+				c.SetHeader("Authorization", "Bearer new")
+				return nil
+			},
+			wantCommand: `curl -X 'GET' -H 'Authorization: Bearer new' -H 'X-Auth-Token: private-token' 'http://example.com/cats?name=tom'`,
+		},
+		{
+			name: "replace existing header",
+			mutate: func(c *CurlCommand) error {
+				c.SetHeader("X-Auth-Token", "swapped-token")
+				return nil
+			},
+			wantCommand: `curl -X 'GET' -H 'X-Auth-Token: swapped-token' 'http://example.com/cats?name=tom'`,
+		},
+		{
+			name: "remove header case insensitively",
+			mutate: func(c *CurlCommand) error {
+				c.RemoveHeader("x-auth-token")
+				return nil
+			},
+			wantCommand: `curl -X 'GET' 'http://example.com/cats?name=tom'`,
+		},
+		{
+			name: "set URL host",
+			mutate: func(c *CurlCommand) error {
+				return c.SetURLHost("staging.example.com:8080")
+			},
+			wantCommand: `curl -X 'GET' -H 'X-Auth-Token: private-token' 'http://staging.example.com:8080/cats?name=tom'`,
+		},
+		{
+			name: "set query param",
+			mutate: func(c *CurlCommand) error {
+				return c.SetQueryParam("name", "jerry")
+			},
+			wantCommand: `curl -X 'GET' -H 'X-Auth-Token: private-token' 'http://example.com/cats?name=jerry'`,
+		},
+		{
+			name: "add flag",
+			mutate: func(c *CurlCommand) error {
+				c.AddFlag("--max-time", "10")
+				return nil
+			},
+			wantCommand: `curl -X 'GET' -H 'X-Auth-Token: private-token' 'http://example.com/cats?name=tom' --max-time 10`,
+		},
+		{
+			name: "invalid URL",
+			mutate: func(c *CurlCommand) error {
+				c.URL = "http://[::1"
+				return c.SetURLHost("example.org")
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "http://example.com/cats?name=tom", nil)
+			req.Header.Set("X-Auth-Token", "private-token")
+			command, err := GetCurlCommand(req)
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+
+			err = tt.mutate(command)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mutate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && command.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestCurlCommandClone(t *testing.T) {
+	req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("data"))
+	req.Header.Set("X-Auth-Token", "private-token")
+	command, _ := GetCurlCommand(req)
+
+	clone := command.Clone()
+	clone.SetHeader("X-Auth-Token", "other-token")
+	clone.Body.Data = "other"
+	clone.AddFlag("--fail")
+
+	want := `curl -X 'POST' -d 'data' -H 'X-Auth-Token: private-token' 'http://example.com'`
+	if got := command.String(); got != want {
+		t.Errorf("original modified by clone mutation:\nGot:\n%s\nWant:\n%s", got, want)
+	}
+	if command.Equal(clone) {
+		t.Errorf("Equal() = true after mutating clone")
+	}
+}
+
+func TestCurlCommandEqual(t *testing.T) {
+	base := &CurlCommand{
+		Method:  "GET",
+		URL:     "http://example.com",
+		Headers: []Header{{Key: "Accept", Value: "*/*"}, {Key: "X-Token", Value: "abc"}},
+		Flags:   []string{"--fail", "--silent"},
+	}
+
+	tests := []struct {
+		name  string
+		other *CurlCommand
+		want  bool
+	}{
+		{
+			name:  "clone",
+			other: base.Clone(),
+			want:  true,
+		},
+		{
+			name: "different header and flag order",
+			other: &CurlCommand{
+				Method:  "GET",
+				URL:     "http://example.com",
+				Headers: []Header{{Key: "x-token", Value: "abc"}, {Key: "Accept", Value: "*/*"}},
+				Flags:   []string{"--silent", "--fail"},
+			},
+			want: true,
+		},
+		{
+			name: "different header value",
+			other: &CurlCommand{
+				Method:  "GET",
+				URL:     "http://example.com",
+				Headers: []Header{{Key: "Accept", Value: "*/*"}, {Key: "X-Token", Value: "xyz"}},
+				Flags:   []string{"--fail", "--silent"},
+			},
+			want: false,
+		},
+		{
+			name: "body present",
+			other: func() *CurlCommand {
+				c := base.Clone()
+				c.Body = &BodySpec{Data: "data"}
+				return c
+			}(),
+			want: false,
+		},
+		{
+			name: "suppressed headers",
+			other: func() *CurlCommand {
+				c := base.Clone()
+				c.SuppressHeaders = []string{"accept"}
+				return c
+			}(),
+			want: false,
+		},
+		{
+			name:  "nil",
+			other: nil,
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := base.Equal(tt.other); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurlCommandHash(t *testing.T) {
+	newCommand := func(date string, headerOrder ...string) *CurlCommand {
+		c := &CurlCommand{Method: "GET", URL: "http://example.com"}
+		for _, k := range headerOrder {
+			c.Headers = append(c.Headers, Header{Key: k, Value: "value"})
+		}
+		c.Headers = append(c.Headers, Header{Key: "Date", Value: date})
+		return c
+	}
+
+	a := newCommand("Mon, 02 Jan 2006 15:04:05 GMT", "Accept", "X-Token")
+	b := newCommand("Tue, 03 Jan 2006 15:04:05 GMT", "X-Token", "Accept")
+
+	if a.Hash() == b.Hash() {
+		t.Errorf("Hash() equal for commands with different Date headers")
+	}
+	if a.Hash(VolatileHeaders...) != b.Hash(VolatileHeaders...) {
+		t.Errorf("Hash(VolatileHeaders...) differs for commands only differing in volatile headers")
+	}
+	if a.Hash() != a.Clone().Hash() {
+		t.Errorf("Hash() not stable across clones")
+	}
+
+	c := a.Clone()
+	c.Body = &BodySpec{Data: "data"}
+	if a.Hash() == c.Hash() {
+		t.Errorf("Hash() equal for commands with different bodies")
+	}
+}
@@ -0,0 +1,50 @@
+package http2curl
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// CompatV1 selects the token layout and escaping of the original v1 output
+const CompatV1 = "1"
+
+// WithCompatVersion pins the rendering defaults to those of an earlier
+// release, so golden files and log parsers keep matching after upgrades.
+// With CompatV1 the Content-Length header is kept, server side URLs drop the
+// query string, hosts are rendered as sent without -g, escaped newlines are
+// piped through echo -e, and CONNECT, OPTIONS * and trailers get no special
+// treatment. Options added since still apply
+// when used.
+func WithCompatVersion(version string) CurlOption {
+	return func(c *CurlCommand) {
+		c.CompatVersion = version
+	}
+}
+
+// compatV1 reports whether the command renders the v1 layout
+func (c *CurlCommand) compatV1() bool {
+	return c.CompatVersion == CompatV1
+}
+
+// validateCompatVersion checks that the compat version is one this release
+// can render
+func validateCompatVersion(version string) error {
+	switch version {
+	case "", CompatV1:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedCompatVersion, version)
+	}
+}
+
+// v1RequestURL returns the URL as rendered by v1
+func v1RequestURL(req *http.Request) string {
+	if req.URL.Scheme == "" {
+		scheme := "http"
+		if req.TLS != nil {
+			scheme = "https"
+		}
+		return fmt.Sprintf("%s://%s%s", scheme, req.Host, req.URL.Path)
+	}
+	return req.URL.String()
+}
@@ -0,0 +1,80 @@
+package http2curl
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithCompatVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		newReq      func() *http.Request
+		wantCommand string
+	}{
+		{
+			name: "content length kept",
+			newReq: func() *http.Request {
+				req, _ := http.NewRequest("PUT", "http://www.example.com/abc/def.ghi?jlk=mno&pqr=stu", strings.NewReader(`{"hello":"world"}`))
+				req.Header.Set("Content-Length", "17")
+				return req
+			},
+			wantCommand: `curl -X 'PUT' -d '{"hello":"world"}' -H 'Content-Length: 17' 'http://www.example.com/abc/def.ghi?jlk=mno&pqr=stu'`,
+		},
+		{
+			name: "server side query dropped",
+			newReq: func() *http.Request {
+				return httptest.NewRequest("GET", "/cats?name=tom", nil)
+			},
+			wantCommand: `curl -X 'GET' 'http://example.com/cats'`,
+		},
+		{
+			name: "IPv6 host without globbing flag",
+			newReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://[::1]:8080/cats", nil)
+				return req
+			},
+			wantCommand: `curl -X 'GET' 'http://[::1]:8080/cats'`,
+		},
+		{
+			name: "OPTIONS asterisk form",
+			newReq: func() *http.Request {
+				return httptest.NewRequest("OPTIONS", "*", nil)
+			},
+			wantCommand: `curl -X 'OPTIONS' 'http://example.com*'`,
+		},
+		{
+			name: "trailers not announced",
+			newReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com/upload", strings.NewReader("data"))
+				req.Trailer = http.Header{"X-Checksum": nil}
+				return req
+			},
+			wantCommand: `curl -X 'POST' -d 'data' 'http://example.com/upload'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := GetCurlCommand(tt.newReq(), WithCompatVersion(CompatV1))
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestWithCompatVersionUnsupported(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	if _, err := GetCurlCommand(req, WithCompatVersion("0")); !errors.Is(err, ErrUnsupportedCompatVersion) {
+		t.Errorf("GetCurlCommand() error = %v, want %v", err, ErrUnsupportedCompatVersion)
+	}
+	if _, err := (Config{CompatVersion: "2"}).Options(); !errors.Is(err, ErrUnsupportedCompatVersion) {
+		t.Errorf("Config.Options() error = %v, want %v", err, ErrUnsupportedCompatVersion)
+	}
+}
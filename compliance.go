@@ -0,0 +1,99 @@
+package http2curl
+
+import (
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// ComplianceMaxBody is the number of body bytes kept by ForCompliance
+const ComplianceMaxBody = 1024
+
+// ForCompliance bundles options making commands safe for long-retention
+// logs in PCI and PII regulated environments: SensitiveHeaders and
+// Set-Cookie are redacted, card and account numbers and other secrets are
+// replaced with REDACTED and bodies are cut to ComplianceMaxBody bytes.
+func ForCompliance() CurlOption {
+	opts := []CurlOption{
+		WithRedactedHeaders(append([]string{"Set-Cookie"}, SensitiveHeaders...)...),
+		WithSecretScanner(CardNumberScanner),
+		WithSecretScanner(AccountNumberScanner),
+		WithSecretScanner(HeuristicScanner),
+		WithBodyPeek(ComplianceMaxBody),
+	}
+	return func(c *CurlCommand) {
+		for _, opt := range opts {
+			opt(c)
+		}
+	}
+}
+
+// CardNumberScanner finds payment card numbers: runs of 13 to 19 digits,
+// optionally grouped with spaces or dashes, passing the Luhn check. A run
+// of 7 or more digits ending the text is also reported, as it may be a
+// card number cut off by body truncation.
+var CardNumberScanner SecretScanner = SecretScannerFunc(findCardNumbers)
+
+// AccountNumberScanner finds IBAN account numbers passing the mod 97 check
+var AccountNumberScanner SecretScanner = SecretScannerFunc(findAccountNumbers)
+
+var (
+	digitRunPattern = regexp.MustCompile(`\d(?:[ -]?\d)+`)
+	ibanPattern     = regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`)
+)
+
+func findCardNumbers(s string) []string {
+	var secrets []string
+	for _, loc := range digitRunPattern.FindAllStringIndex(s, -1) {
+		run := s[loc[0]:loc[1]]
+		digits := strings.NewReplacer(" ", "", "-", "").Replace(run)
+		switch {
+		case len(digits) >= 13 && len(digits) <= 19 && luhnValid(digits):
+			secrets = append(secrets, run)
+		case len(digits) >= 7 && len(digits) < 13 && loc[1] == len(s):
+			secrets = append(secrets, run)
+		}
+	}
+	return secrets
+}
+
+// luhnValid reports whether the digits pass the Luhn checksum
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func findAccountNumbers(s string) []string {
+	var secrets []string
+	for _, candidate := range ibanPattern.FindAllString(s, -1) {
+		if ibanValid(strings.ReplaceAll(candidate, " ", "")) {
+			secrets = append(secrets, candidate)
+		}
+	}
+	return secrets
+}
+
+// ibanValid reports whether the IBAN passes the ISO 7064 mod 97 check
+func ibanValid(iban string) bool {
+	var numeric strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			numeric.WriteString(big.NewInt(int64(r-'A') + 10).String())
+		} else {
+			numeric.WriteRune(r)
+		}
+	}
+	n, ok := new(big.Int).SetString(numeric.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
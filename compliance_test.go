@@ -0,0 +1,63 @@
+package http2curl
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestForCompliance(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		header      http.Header
+		wantCommand string
+	}{
+		{
+			name:        "card number",
+			body:        `{"card":"4111 1111 1111 1111","amount":"1000"}`,
+			wantCommand: `curl -X 'POST' -d '{"card":"REDACTED","amount":"1000"}' 'https://example.com/pay'`,
+		},
+		{
+			name:        "digits failing the Luhn check",
+			body:        `{"order":"1234567890123"}`,
+			wantCommand: `curl -X 'POST' -d '{"order":"1234567890123"}' 'https://example.com/pay'`,
+		},
+		{
+			name:        "IBAN",
+			body:        "iban=GB82 WEST 1234 5698 7654 32&amount=10",
+			wantCommand: `curl -X 'POST' -d 'iban=REDACTED&amount=10' 'https://example.com/pay'`,
+		},
+		{
+			name: "credential headers",
+			header: http.Header{
+				"Authorization": {"Basic dG9tOmplcnJ5"},
+				"Cookie":        {"session=abc"},
+				"Accept":        {"application/json"},
+			},
+			wantCommand: `curl -X 'POST' -H 'Accept: application/json' -H 'Authorization: REDACTED' -H 'Cookie: REDACTED' 'https://example.com/pay'`,
+		},
+		{
+			name: "truncated body ending in a partial card number",
+			body: strings.Repeat("a", ComplianceMaxBody-8) + "41111111 1111 1111",
+			wantCommand: "# body truncated to 1024 bytes, Content-Length: 1034\n" +
+				`curl -X 'POST' -d '` + strings.Repeat("a", ComplianceMaxBody-8) + `REDACTED' 'https://example.com/pay'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "https://example.com/pay", strings.NewReader(tt.body))
+			if tt.header != nil {
+				req.Header = tt.header
+			}
+			command, err := GetCurlCommand(req, ForCompliance())
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.wantCommand)
+			}
+		})
+	}
+}
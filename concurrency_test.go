@@ -0,0 +1,78 @@
+package http2curl
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestSharedCommandConcurrentUse shares one generated command between
+// goroutines reading it and deriving modified copies; run with -race
+func TestSharedCommandConcurrentUse(t *testing.T) {
+	req, _ := http.NewRequest("POST", "http://example.com/cats", strings.NewReader("meow"))
+	req.Header.Set("Authorization", "Bearer secret")
+	shared, err := GetCurlCommand(req, WithRedactedHeaders("Authorization"))
+	if err != nil {
+		t.Fatalf("GetCurlCommand() error = %v", err)
+	}
+	want := shared.String()
+	wantHash := shared.Hash()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if got := shared.String(); got != want {
+					t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+				}
+				_ = shared.MultiLineString()
+				_ = shared.ConfigFileContents()
+				_ = shared.Redactions()
+				if shared.Hash() != wantHash {
+					t.Error("Hash() changed while shared")
+				}
+				derived := shared.Derive(func(c *CurlCommand) {
+					c.SetHeader("X-Worker", "cat")
+					c.AddFlag("-v")
+				})
+				if derived.Equal(shared) {
+					t.Error("derived command equals the shared one")
+				}
+				_ = derived.String()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := shared.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
+
+func TestSessionSetCookieFileKeepsReturnedCommands(t *testing.T) {
+	session := NewSession()
+	req, _ := http.NewRequest("GET", "http://example.com/cats", nil)
+	first, _ := session.Add(req)
+	want := first.String()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			_ = first.String()
+		}
+	}()
+	session.SetCookieFile("jar.txt")
+	wg.Wait()
+
+	if got := first.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+	if got := session.Commands()[0].CookieJarFile; got != "jar.txt" {
+		t.Errorf("CookieJarFile = %q, want %q", got, "jar.txt")
+	}
+}
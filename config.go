@@ -0,0 +1,111 @@
+package http2curl
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Shell selects the shell syntax generated commands are written for
+type Shell string
+
+// Supported shells
+const (
+	Bash       Shell = "bash"
+	Cmd        Shell = "cmd"        // cmd.exe batch files running curl.exe, see WithWindowsCurl
+	PowerShell Shell = "powershell" // PowerShell running curl.exe, see WithPowerShell
+)
+
+// Config is a serializable alternative to functional options, suitable for
+// loading from configuration files or the environment
+type Config struct {
+	Shell              Shell    `json:"shell,omitempty" yaml:"shell,omitempty"`
+	InsecureSkipVerify bool     `json:"insecure_skip_verify,omitempty" yaml:"insecure_skip_verify,omitempty"`
+	Compressed         bool     `json:"compressed,omitempty" yaml:"compressed,omitempty"`
+	AutoDecompressGZIP bool     `json:"auto_decompress_gzip,omitempty" yaml:"auto_decompress_gzip,omitempty"`
+	EscapedNewlines    bool     `json:"escaped_newlines,omitempty" yaml:"escaped_newlines,omitempty"`
+	HeredocBody        bool     `json:"heredoc_body,omitempty" yaml:"heredoc_body,omitempty"`
+	CurlJSON           bool     `json:"curl_json,omitempty" yaml:"curl_json,omitempty"`
+	DoubleQuotes       bool     `json:"double_quotes,omitempty" yaml:"double_quotes,omitempty"`
+	NormalizeJSON      bool     `json:"normalize_json,omitempty" yaml:"normalize_json,omitempty"`
+	FormFields         bool     `json:"form_fields,omitempty" yaml:"form_fields,omitempty"`
+	MaxBodySize        int64    `json:"max_body_size,omitempty" yaml:"max_body_size,omitempty"`
+	RedactHeaders      []string `json:"redact_headers,omitempty" yaml:"redact_headers,omitempty"`
+	IncludeHeaders     []string `json:"include_headers,omitempty" yaml:"include_headers,omitempty"`
+	ExcludeHeaders     []string `json:"exclude_headers,omitempty" yaml:"exclude_headers,omitempty"`
+	CompatVersion      string   `json:"compat_version,omitempty" yaml:"compat_version,omitempty"`
+
+	MaxDecompressedSize int64 `json:"max_decompressed_size,omitempty" yaml:"max_decompressed_size,omitempty"`
+}
+
+// Options returns the functional options equivalent to the configuration
+func (cfg Config) Options() ([]CurlOption, error) {
+	var opts []CurlOption
+
+	switch cfg.Shell {
+	case "", Bash:
+	case Cmd:
+		opts = append(opts, WithWindowsCurl())
+	case PowerShell:
+		opts = append(opts, WithPowerShell())
+	default:
+		return nil, fmt.Errorf("unsupported shell %q", cfg.Shell)
+	}
+	if cfg.InsecureSkipVerify {
+		opts = append(opts, WithInsecureSkipVerify())
+	}
+	if cfg.Compressed {
+		opts = append(opts, WithCompression())
+	}
+	if cfg.AutoDecompressGZIP {
+		opts = append(opts, WithAutoDecompressGZIP())
+	}
+	if cfg.EscapedNewlines {
+		opts = append(opts, WithEscapedNewlines())
+	}
+	if cfg.HeredocBody {
+		opts = append(opts, WithHeredocBody())
+	}
+	if cfg.CurlJSON {
+		opts = append(opts, WithCurlJSON())
+	}
+	if cfg.DoubleQuotes {
+		opts = append(opts, WithDoubleQuoteEscaping())
+	}
+	if cfg.NormalizeJSON {
+		opts = append(opts, WithNormalizedJSON())
+	}
+	if cfg.FormFields {
+		opts = append(opts, WithFormFields())
+	}
+	if cfg.MaxBodySize > 0 {
+		opts = append(opts, WithMaxBodySize(cfg.MaxBodySize))
+	}
+	if cfg.MaxDecompressedSize > 0 {
+		opts = append(opts, WithMaxDecompressedSize(cfg.MaxDecompressedSize))
+	}
+	if len(cfg.RedactHeaders) > 0 {
+		opts = append(opts, WithRedactedHeaders(cfg.RedactHeaders...))
+	}
+	if len(cfg.IncludeHeaders) > 0 {
+		opts = append(opts, WithIncludeHeaders(cfg.IncludeHeaders...))
+	}
+	if len(cfg.ExcludeHeaders) > 0 {
+		opts = append(opts, WithExcludeHeaders(cfg.ExcludeHeaders...))
+	}
+	if cfg.CompatVersion != "" {
+		if err := validateCompatVersion(cfg.CompatVersion); err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithCompatVersion(cfg.CompatVersion))
+	}
+	return opts, nil
+}
+
+// GetCurlCommandWithConfig generates curl command configured by cfg
+func GetCurlCommandWithConfig(req *http.Request, cfg Config) (*CurlCommand, error) {
+	opts, err := cfg.Options()
+	if err != nil {
+		return nil, err
+	}
+	return GetCurlCommand(req, opts...)
+}
@@ -0,0 +1,74 @@
+package http2curl
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestGetCurlCommandWithConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		wantCommand string
+		wantErr     bool
+	}{
+		{
+			name:        "empty config",
+			config:      `{}`,
+			wantCommand: `curl -X 'POST' -d 'hello\nworld' -H 'Authorization: Bearer secret' -H 'X-Auth-Token: private-token' 'https://example.com'`,
+		},
+		{
+			name:        "redaction and flags",
+			config:      `{"shell":"bash","insecure_skip_verify":true,"compressed":true,"redact_headers":["authorization","X-Auth-Token"]}`,
+			wantCommand: `curl -k -X 'POST' -d 'hello\nworld' -H 'Authorization: REDACTED' -H 'X-Auth-Token: REDACTED' 'https://example.com' --compressed`,
+		},
+		{
+			name:        "heredoc body",
+			config:      `{"heredoc_body":true}`,
+			wantCommand: "{ printf '%s' \"$(cat)\"; } <<'EOF' | curl -X 'POST' --data-binary @- -H 'Authorization: Bearer secret' -H 'X-Auth-Token: private-token' 'https://example.com'\nhello\nworld\nEOF",
+		},
+		{
+			name:        "cmd shell",
+			config:      `{"shell":"cmd","redact_headers":["authorization","X-Auth-Token"]}`,
+			wantCommand: "REM write the body to body.bin before running the command\n" + `curl -X "POST" --data-binary "@body.bin" -H "Authorization: REDACTED" -H "X-Auth-Token: REDACTED" "https://example.com"`,
+		},
+		{
+			name:        "powershell",
+			config:      `{"shell":"powershell","redact_headers":["authorization","X-Auth-Token"]}`,
+			wantCommand: "# write the body to body.bin before running the command\n" + `curl.exe -X 'POST' --data-binary '@body.bin' -H 'Authorization: REDACTED' -H 'X-Auth-Token: REDACTED' 'https://example.com'`,
+		},
+		{
+			name:    "body too large",
+			config:  `{"max_body_size":5}`,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported shell",
+			config:  `{"shell":"fish"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			if err := json.Unmarshal([]byte(tt.config), &cfg); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+
+			req, _ := http.NewRequest("POST", "https://example.com", bytes.NewBufferString("hello\nworld"))
+			req.Header.Set("Authorization", "Bearer secret")
+			req.Header.Set("X-Auth-Token", "private-token")
+
+			command, err := GetCurlCommandWithConfig(req, cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetCurlCommandWithConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && command.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+		})
+	}
+}
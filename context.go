@@ -0,0 +1,22 @@
+package http2curl
+
+import "context"
+
+type contextKey struct{}
+
+// ContextWithOptions returns a copy of ctx carrying opts in addition to any
+// options already attached to ctx. Options found on a request's context are
+// applied by GetCurlCommand after the options passed to it.
+func ContextWithOptions(ctx context.Context, opts ...CurlOption) context.Context {
+	existing := contextOptions(ctx)
+	merged := make([]CurlOption, 0, len(existing)+len(opts))
+	merged = append(merged, existing...)
+	merged = append(merged, opts...)
+	return context.WithValue(ctx, contextKey{}, merged)
+}
+
+// contextOptions returns the options attached to ctx
+func contextOptions(ctx context.Context) []CurlOption {
+	opts, _ := ctx.Value(contextKey{}).([]CurlOption)
+	return opts
+}
@@ -0,0 +1,61 @@
+package http2curl
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestContextWithOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		ctx         func() context.Context
+		opts        []CurlOption
+		wantCommand string
+	}{
+		{
+			name:        "no context options",
+			ctx:         context.Background,
+			wantCommand: `curl -X 'GET' -H 'Authorization: Bearer secret' 'https://example.com'`,
+		},
+		{
+			name: "context options",
+			ctx: func() context.Context {
+				return ContextWithOptions(context.Background(), WithRedactedHeaders("Authorization"))
+			},
+			wantCommand: `curl -X 'GET' -H 'Authorization: REDACTED' 'https://example.com'`,
+		},
+		{
+			name: "nested context options are merged with call options",
+			ctx: func() context.Context {
+				ctx := ContextWithOptions(context.Background(), WithRedactedHeaders("Authorization"))
+				return ContextWithOptions(ctx, WithCompression())
+			},
+			opts:        []CurlOption{WithInsecureSkipVerify()},
+			wantCommand: `curl -k -X 'GET' -H 'Authorization: REDACTED' 'https://example.com' --compressed`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequestWithContext(tt.ctx(), "GET", "https://example.com", nil)
+			req.Header.Set("Authorization", "Bearer secret")
+
+			command, err := GetCurlCommand(req, tt.opts...)
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if command.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+
+			command, err = NewConverter(tt.opts...).Convert(req)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if command.String() != tt.wantCommand {
+				t.Errorf("Convert() Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package http2curl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithRejectControlChars makes command generation fail with
+// ErrControlCharacter when a header name or value contains control
+// characters such as CR, LF or NUL, instead of stripping them
+func WithRejectControlChars() CurlOption {
+	return func(c *CurlCommand) {
+		c.RejectControlChars = true
+	}
+}
+
+// isControlChar reports whether b is a control character other than tab,
+// which cannot appear in a header line without changing its meaning
+func isControlChar(b byte) bool {
+	return (b < 0x20 && b != '\t') || b == 0x7f
+}
+
+func hasControlChars(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if isControlChar(s[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripControlChars removes control characters from s, so a header cannot
+// inject extra lines into the request curl sends
+func stripControlChars(s string) string {
+	if !hasControlChars(s) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if !isControlChar(s[i]) {
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// sanitizeHeader strips control characters from a header, or reports them
+// with WithRejectControlChars
+func (c *CurlCommand) sanitizeHeader(key, value string) (string, string, error) {
+	if c.RejectControlChars && (hasControlChars(key) || hasControlChars(value)) {
+		return "", "", fmt.Errorf("%w: header %q", ErrControlCharacter, key)
+	}
+	stripped, strippedValue := stripControlChars(key), stripControlChars(value)
+	if stripped != key || strippedValue != value {
+		c.recordLoss(LossHeaderSanitized, stripped, "control characters stripped from header "+stripped)
+	}
+	return stripped, strippedValue, nil
+}
@@ -0,0 +1,90 @@
+package http2curl
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestHeaderControlCharacters(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      http.Header
+		trailer     http.Header
+		opts        []CurlOption
+		wantCommand string
+		wantErr     error
+	}{
+		{
+			name:        "CRLF injected header line",
+			header:      http.Header{"X-Name": {"tom\r\nX-Admin: true"}},
+			wantCommand: `curl -X 'GET' -H 'X-Name: tomX-Admin: true' 'http://example.com'`,
+		},
+		{
+			name:        "bare LF and NUL",
+			header:      http.Header{"X-Name": {"tom\nX-Admin: true\x00"}},
+			wantCommand: `curl -X 'GET' -H 'X-Name: tomX-Admin: true' 'http://example.com'`,
+		},
+		{
+			name:        "control characters in name",
+			header:      http.Header{"X-Name\r\nX-Admin": {"true"}},
+			wantCommand: `curl -X 'GET' -H 'X-NameX-Admin: true' 'http://example.com'`,
+		},
+		{
+			name:        "tabs kept",
+			header:      http.Header{"X-Name": {"tom\tjerry"}},
+			wantCommand: "curl -X 'GET' -H 'X-Name: tom\tjerry' 'http://example.com'",
+		},
+		{
+			name:    "trailer values",
+			trailer: http.Header{"X-Checksum": {"abc\r\nX-Admin: true"}},
+			wantCommand: "# trailer not sent by curl: X-Checksum: abcX-Admin: true\n" +
+				"curl -X 'GET' -H 'Trailer: X-Checksum' -H 'Transfer-Encoding: chunked' 'http://example.com'",
+		},
+		{
+			name:    "rejected",
+			header:  http.Header{"X-Name": {"tom\r\nX-Admin: true"}},
+			opts:    []CurlOption{WithRejectControlChars()},
+			wantErr: ErrControlCharacter,
+		},
+		{
+			name:    "rejected in trailer",
+			trailer: http.Header{"X-Checksum": {"abc\x00"}},
+			opts:    []CurlOption{WithRejectControlChars()},
+			wantErr: ErrControlCharacter,
+		},
+		{
+			name:        "redacted values are not rejected",
+			header:      http.Header{"Authorization": {"Bearer abc\r\n"}},
+			opts:        []CurlOption{WithRejectControlChars(), WithRedactedHeaders("Authorization")},
+			wantCommand: `curl -X 'GET' -H 'Authorization: REDACTED' 'http://example.com'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "http://example.com", nil)
+			req.Header, req.Trailer = tt.header, tt.trailer
+			if req.Header == nil {
+				req.Header = http.Header{}
+			}
+			command, err := GetCurlCommand(req, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetCurlCommand() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && command.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestModifiedHeaderControlCharacters(t *testing.T) {
+	command := &CurlCommand{Method: "GET", URL: "http://example.com"}
+	command.SetHeader("X-Name", "tom\r\nX-Admin: true")
+
+	want := `curl -X 'GET' -H 'X-Name: tomX-Admin: true' 'http://example.com'`
+	if got := command.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,30 @@
+package http2curl
+
+import "net/http"
+
+// Converter generates curl commands using a fixed set of options, avoiding
+// the need to pass the same options on every call
+type Converter struct {
+	base *CurlCommand
+}
+
+// NewConverter returns a Converter applying the current default options
+// and opts to every conversion
+func NewConverter(opts ...CurlOption) *Converter {
+	base := &CurlCommand{}
+	base.applyDefaults()
+	for _, opt := range opts {
+		opt(base)
+	}
+	return &Converter{base: base}
+}
+
+// Convert generates a curl command for req. Additional options are applied
+// after the Converter's options.
+func (cv *Converter) Convert(req *http.Request, opts ...CurlOption) (*CurlCommand, error) {
+	command := cv.base.Clone()
+	for _, opt := range opts {
+		opt(command)
+	}
+	return command.generate(req)
+}
@@ -0,0 +1,73 @@
+package http2curl
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestConverter(t *testing.T) {
+	converter := NewConverter(WithInsecureSkipVerify(), WithCompression())
+
+	tests := []struct {
+		name        string
+		setupReq    func() *http.Request
+		opts        []CurlOption
+		wantCommand string
+	}{
+		{
+			name: "converter options",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "https://example.com", nil)
+				return req
+			},
+			wantCommand: `curl -k -X 'GET' 'https://example.com' --compressed`,
+		},
+		{
+			name: "additional options",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "https://example.com", bytes.NewBufferString("hello\nworld"))
+				return req
+			},
+			opts:        []CurlOption{WithEscapedNewlines()},
+			wantCommand: `printf 'hello\nworld' | curl -k -X 'POST' --data-binary @- 'https://example.com' --compressed`,
+		},
+		{
+			name: "additional options do not leak into later conversions",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "https://example.com", bytes.NewBufferString("hello\nworld"))
+				return req
+			},
+			wantCommand: `curl -k -X 'POST' -d 'hello\nworld' 'https://example.com' --compressed`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := converter.Convert(tt.setupReq(), tt.opts...)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if command.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestConcurrentConverter(t *testing.T) {
+	converter := NewConverter(WithCompression())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest("GET", "http://example.com", nil)
+			command, _ := converter.Convert(req)
+			command.AddFlag("--fail")
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,54 @@
+package http2curl
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// WithCookieJar renders the cookies jar holds for the request URL with -b,
+// e.g. the session cookie an http.Client adds to requests as it sends them.
+// Cookies the request already carries in its Cookie header are left out,
+// and values are redacted when Cookie is redacted.
+func WithCookieJar(jar http.CookieJar) CurlOption {
+	return func(c *CurlCommand) {
+		c.cookieJar = jar
+	}
+}
+
+// addJarCookies sets Cookies from the cookie jar given with WithCookieJar,
+// looking them up by the URL the request was sent to as http.Client does,
+// or by requestURL, the URL reconstructed before WithURLRewriter rewrote
+// it, for server requests
+func (c *CurlCommand) addJarCookies(req *http.Request, requestURL string) error {
+	if c.cookieJar == nil || !c.headerAllowed("Cookie") {
+		return nil
+	}
+	u := req.URL
+	if u == nil || u.Host == "" {
+		var err error
+		if u, err = url.Parse(requestURL); err != nil {
+			return fmt.Errorf("url parse error: %w", err)
+		}
+	}
+	sent := make(map[string]bool)
+	for _, cookie := range req.Cookies() {
+		sent[cookie.Name] = true
+	}
+	redact := containsFold(c.RedactHeaders, "Cookie")
+	var pairs []string
+	for _, cookie := range c.cookieJar.Cookies(u) {
+		if sent[cookie.Name] {
+			continue
+		}
+		value := cookie.Value
+		if redact {
+			value = redactedValue
+			c.recordRedaction(RedactedHeader, "Cookie", 1)
+		}
+		pairs = append(pairs, cookie.Name+"="+value)
+	}
+	c.Cookies = strings.Join(pairs, "; ")
+	return nil
+}
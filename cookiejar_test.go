@@ -0,0 +1,96 @@
+package http2curl
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestWithCookieJar(t *testing.T) {
+	jar, _ := cookiejar.New(nil)
+	u, _ := url.Parse("http://example.com/")
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "session", Value: "abc123"},
+		{Name: "theme", Value: "dark"},
+	})
+
+	tests := []struct {
+		name   string
+		url    string
+		cookie string
+		opts   []CurlOption
+		want   string
+	}{
+		{
+			name: "jar cookies",
+			url:  "http://example.com/cats",
+			want: `curl -X 'GET' -b 'session=abc123; theme=dark' 'http://example.com/cats'`,
+		},
+		{
+			name:   "cookie already sent",
+			url:    "http://example.com/cats",
+			cookie: "theme=light",
+			want:   `curl -X 'GET' -H 'Cookie: theme=light' -b 'session=abc123' 'http://example.com/cats'`,
+		},
+		{
+			name: "other host",
+			url:  "http://example.org/cats",
+			want: `curl -X 'GET' 'http://example.org/cats'`,
+		},
+		{
+			name: "rewritten URL",
+			url:  "http://example.com/cats",
+			opts: []CurlOption{WithURLRewriter(func(u *url.URL) *url.URL {
+				u.Host = "localhost:8080"
+				return u
+			})},
+			want: `curl -X 'GET' -b 'session=abc123; theme=dark' 'http://localhost:8080/cats'`,
+		},
+		{
+			name: "redacted",
+			url:  "http://example.com/cats",
+			opts: []CurlOption{WithRedactedHeaders("Cookie")},
+			want: `curl -X 'GET' -b 'session=REDACTED; theme=REDACTED' 'http://example.com/cats'`,
+		},
+		{
+			name: "excluded",
+			url:  "http://example.com/cats",
+			opts: []CurlOption{WithExcludeHeaders("Cookie")},
+			want: `curl -X 'GET' 'http://example.com/cats'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			if tt.cookie != "" {
+				req.Header.Set("Cookie", tt.cookie)
+			}
+			command, err := GetCurlCommand(req, append(tt.opts, WithCookieJar(jar))...)
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.want {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithCookieJarServerRequest(t *testing.T) {
+	jar, _ := cookiejar.New(nil)
+	u, _ := url.Parse("http://example.com/")
+	jar.SetCookies(u, []*http.Cookie{{Name: "session", Value: "abc123"}})
+
+	req := httptest.NewRequest("GET", "/cats", nil)
+	command, err := GetCurlCommand(req, WithCookieJar(jar))
+	if err != nil {
+		t.Fatalf("GetCurlCommand() error = %v", err)
+	}
+	want := `curl -X 'GET' -b 'session=abc123' 'http://example.com/cats'`
+	if got := command.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,44 @@
+package http2curl
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// ProtoDecoder renders a binary body, e.g. protobuf, msgpack or avro, as
+// readable JSON. It returns false for content types it does not decode or
+// bodies it cannot parse.
+type ProtoDecoder func(contentType string, body []byte) (string, bool)
+
+// WithProtoDecoder registers a decoder for binary bodies. A body it decodes
+// is shown as a comment while the command pipes the exact bytes to curl
+// from base64, e.g. echo 'CgNUb20=' | base64 --decode | curl --data-binary @-.
+// Decoders are tried in the order they were registered.
+func WithProtoDecoder(decode ProtoDecoder) CurlOption {
+	return func(c *CurlCommand) {
+		c.protoDecoders = append(c.protoDecoders, decode)
+	}
+}
+
+// decodeBody adds the comment of the first decoder accepting the body and
+// switches the body to base64 replay
+func (c *CurlCommand) decodeBody(contentType string) {
+	if !c.hasBody() {
+		return
+	}
+	for _, decode := range c.protoDecoders {
+		if decoded, ok := decode(contentType, []byte(c.Body.Data)); ok {
+			c.Comments = append(c.Comments, "body decoded from "+contentType+": "+decoded)
+			c.Body.Base64 = true
+			return
+		}
+	}
+}
+
+// writeBase64Pipeline writes the base64 decoding pipeline feeding the body
+// to curl through standard input
+func (c *CurlCommand) writeBase64Pipeline(b *strings.Builder) {
+	b.WriteString("echo ")
+	c.writeWord(b, base64.StdEncoding.EncodeToString([]byte(c.Body.Data)))
+	b.WriteString(" | base64 --decode | ")
+}
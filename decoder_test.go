@@ -0,0 +1,64 @@
+package http2curl
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestWithProtoDecoder(t *testing.T) {
+	// Decodes field 1 of a protobuf message holding a single short string
+	decodeName := func(contentType string, body []byte) (string, bool) {
+		if contentType != "application/x-protobuf" || len(body) < 2 || body[0] != 0x0a || int(body[1]) != len(body)-2 {
+			return "", false
+		}
+		return `{"name":"` + string(body[2:]) + `"}`, true
+	}
+	never := func(string, []byte) (string, bool) { return "", false }
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		opts        []CurlOption
+		wantCommand string
+	}{
+		{
+			name:        "decoded body",
+			contentType: "application/x-protobuf",
+			body:        []byte("\x0a\x03Tom"),
+			opts:        []CurlOption{WithProtoDecoder(never), WithProtoDecoder(decodeName)},
+			wantCommand: "# body decoded from application/x-protobuf: {\"name\":\"Tom\"}\n" +
+				`echo 'CgNUb20=' | base64 --decode | curl -X 'POST' --data-binary @- -H 'Content-Type: application/x-protobuf' 'http://example.com/cats'`,
+		},
+		{
+			name:        "other content type",
+			contentType: "text/plain",
+			body:        []byte("Tom"),
+			opts:        []CurlOption{WithProtoDecoder(decodeName)},
+			wantCommand: `curl -X 'POST' -d 'Tom' -H 'Content-Type: text/plain' 'http://example.com/cats'`,
+		},
+		{
+			name:        "decoded body with Content-Length",
+			contentType: "application/x-protobuf",
+			body:        []byte("\x0a\x03Tom"),
+			opts:        []CurlOption{WithProtoDecoder(decodeName), WithContentLength(), WithInlineComments()},
+			wantCommand: `echo 'CgNUb20=' | base64 --decode | curl -X 'POST' --data-binary @- -H 'Content-Type: application/x-protobuf' ` +
+				`-H 'Content-Length: 5' 'http://example.com/cats' # body decoded from application/x-protobuf: {"name":"Tom"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "http://example.com/cats", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			command, err := GetCurlCommand(req, tt.opts...)
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.wantCommand)
+			}
+		})
+	}
+}
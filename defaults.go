@@ -0,0 +1,26 @@
+package http2curl
+
+import "sync/atomic"
+
+// defaultOptions holds the options set with SetDefaultOptions
+var defaultOptions atomic.Pointer[[]CurlOption]
+
+// SetDefaultOptions sets options applied to every command before the
+// options passed to GetCurlCommand or NewConverter, e.g. organization-wide
+// redaction and body size policies set once during initialization. Each
+// call replaces the previous defaults; calling it without options clears
+// them. Converters keep the defaults set when they were created. It is safe
+// for concurrent use.
+func SetDefaultOptions(opts ...CurlOption) {
+	opts = append([]CurlOption(nil), opts...)
+	defaultOptions.Store(&opts)
+}
+
+// applyDefaults applies the options set with SetDefaultOptions to c
+func (c *CurlCommand) applyDefaults() {
+	if opts := defaultOptions.Load(); opts != nil {
+		for _, opt := range *opts {
+			opt(c)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package http2curl
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestSetDefaultOptions(t *testing.T) {
+	t.Cleanup(func() { SetDefaultOptions() })
+	SetDefaultOptions(WithRedactedHeaders("Authorization"), WithMaxBodySize(1024), WithCompression())
+
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+
+	tests := []struct {
+		name    string
+		convert func() (*CurlCommand, error)
+		want    string
+	}{
+		{
+			name:    "GetCurlCommand",
+			convert: func() (*CurlCommand, error) { return GetCurlCommand(req) },
+			want:    `curl -X 'GET' -H 'Authorization: REDACTED' 'http://example.com' --compressed`,
+		},
+		{
+			name: "per-call override",
+			convert: func() (*CurlCommand, error) {
+				return GetCurlCommand(req, func(c *CurlCommand) { c.EnableCompression = false })
+			},
+			want: `curl -X 'GET' -H 'Authorization: REDACTED' 'http://example.com'`,
+		},
+		{
+			name:    "Converter",
+			convert: func() (*CurlCommand, error) { return NewConverter(WithInsecureSkipVerify()).Convert(req) },
+			want:    `curl -X 'GET' -H 'Authorization: REDACTED' 'http://example.com' --compressed`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := tt.convert()
+			if err != nil {
+				t.Fatalf("convert error = %v", err)
+			}
+			if got := command.String(); got != tt.want {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.want)
+			}
+		})
+	}
+
+	SetDefaultOptions()
+	command, _ := GetCurlCommand(req)
+	if want := `curl -X 'GET' -H 'Authorization: Bearer secret' 'http://example.com'`; command.String() != want {
+		t.Errorf("after clearing defaults Got:\n%s\nWant:\n%s", command, want)
+	}
+}
+
+func TestSetDefaultOptionsConcurrent(t *testing.T) {
+	t.Cleanup(func() { SetDefaultOptions() })
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetDefaultOptions(WithCompression())
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := GetCurlCommand(req); err != nil {
+				t.Errorf("GetCurlCommand() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,233 @@
+package http2curl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// Diff generates the curl commands for a and b and returns a unified diff of
+// their method, URL, headers, flags and body, or an empty string when they
+// describe the same request. JSON bodies are compared key by key.
+func Diff(a, b *http.Request, opts ...CurlOption) (string, error) {
+	ca, err := GetCurlCommand(a, opts...)
+	if err != nil {
+		return "", err
+	}
+	defer ca.RemoveTempFiles()
+	cb, err := GetCurlCommand(b, opts...)
+	if err != nil {
+		return "", err
+	}
+	defer cb.RemoveTempFiles()
+	return unifiedDiff("a", "b", ca.diffLines(), cb.diffLines()), nil
+}
+
+// diffLines returns the command as lines suitable for diffing
+func (c *CurlCommand) diffLines() []string {
+	lines := []string{"method: " + c.Method, "url: " + c.URL}
+	for _, h := range headerLines(c.Headers) {
+		lines = append(lines, "header: "+h)
+	}
+	for _, flag := range c.Flags {
+		lines = append(lines, "flag: "+flag)
+	}
+	if c.Body != nil {
+		lines = append(lines, "body:")
+		for _, line := range strings.Split(normalizeJSON(c.Body.Data), "\n") {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return lines
+}
+
+// normalizeJSON indents JSON documents with sorted keys, returning other data
+// unchanged
+func normalizeJSON(data string) string {
+	var v interface{}
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil || decoder.More() {
+		return data
+	}
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return data
+	}
+	return string(out)
+}
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// unifiedDiff returns the unified diff turning a into b, or an empty string
+// when they are equal
+func unifiedDiff(nameA, nameB string, a, b []string) string {
+	ops := diffOps(nil, a, b)
+	changed := false
+	for _, op := range ops {
+		if op.kind != ' ' {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", nameA, nameB)
+	lineA, lineB, counted := 1, 1, 0 // Line numbers at ops[counted]
+	for start := 0; start < len(ops); {
+		// Find the next change and extend the hunk until changes are more
+		// than twice the context apart
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		from := first - diffContext
+		if from < start {
+			from = start
+		}
+		to, unchanged := first, 0
+		for k := first; k < len(ops) && unchanged <= 2*diffContext; k++ {
+			if ops[k].kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+				to = k + 1
+			}
+		}
+		to += diffContext
+		if to > len(ops) {
+			to = len(ops)
+		}
+
+		for _, op := range ops[counted:from] {
+			if op.kind != '+' {
+				lineA++
+			}
+			if op.kind != '-' {
+				lineB++
+			}
+		}
+		counted = from
+		countA, countB := 0, 0
+		for _, op := range ops[from:to] {
+			if op.kind != '+' {
+				countA++
+			}
+			if op.kind != '-' {
+				countB++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", lineA, countA, lineB, countB)
+		for _, op := range ops[from:to] {
+			fmt.Fprintf(&out, "%c%s\n", op.kind, op.line)
+		}
+		start = to
+	}
+	return out.String()
+}
+
+// diffOps appends the edits turning a into b to ops, using Myers' linear
+// space algorithm so large bodies are diffed without a quadratic table
+func diffOps(ops []diffOp, a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		ops = append(ops, diffOp{' ', a[prefix]})
+		prefix++
+	}
+	a, b = a[prefix:], b[prefix:]
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+	case len(b) == 0:
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+	default:
+		// Without a common prefix or suffix, at least two edits are needed
+		// and the middle snake splits both sides into smaller problems
+		x, y, u, v := middleSnake(a, b)
+		ops = diffOps(ops, a[:x], b[:y])
+		for _, line := range a[x:u] {
+			ops = append(ops, diffOp{' ', line})
+		}
+		ops = diffOps(ops, a[u:], b[v:])
+	}
+	for _, line := range common {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// middleSnake returns the start and end of the snake in the middle of a
+// shortest edit script turning a into b, searching from both ends at once
+func middleSnake(a, b []string) (x, y, u, v int) {
+	n, m := len(a), len(b)
+	max := (n + m + 1) / 2
+	delta := n - m
+	odd := delta%2 != 0
+	off := max + 1
+	forward := make([]int, 2*max+3)  // Furthest x on each diagonal k = x-y
+	backward := make([]int, 2*max+3) // Same, from the ends of a and b
+	for d := 0; d <= max; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[off+k-1] < forward[off+k+1]) {
+				x = forward[off+k+1]
+			} else {
+				x = forward[off+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[off+k] = x
+			if c := delta - k; odd && c >= -(d-1) && c <= d-1 && x+backward[off+c] >= n {
+				return startX, startY, x, y
+			}
+		}
+		for c := -d; c <= d; c += 2 {
+			var x int
+			if c == -d || (c != d && backward[off+c-1] < backward[off+c+1]) {
+				x = backward[off+c+1]
+			} else {
+				x = backward[off+c-1] + 1
+			}
+			y := x - c
+			startX, startY := x, y
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+			backward[off+c] = x
+			if k := delta - c; !odd && k >= -d && k <= d && x+forward[off+k] >= n {
+				return n - x, m - y, n - startX, m - startY
+			}
+		}
+	}
+	return n, m, n, m // Unreachable: the paths meet within max steps
+}
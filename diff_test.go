@@ -0,0 +1,152 @@
+package http2curl
+
+import (
+	"bytes"
+	"math/rand"
+	"net/http"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	newRequest := func(body string, headers map[string]string) *http.Request {
+		req, _ := http.NewRequest("POST", "http://example.com/cats", bytes.NewBufferString(body))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+
+	tests := []struct {
+		name     string
+		a, b     *http.Request
+		wantDiff string
+	}{
+		{
+			name:     "identical requests",
+			a:        newRequest(`{"a":1}`, map[string]string{"Accept": "*/*"}),
+			b:        newRequest(`{"a":1}`, map[string]string{"Accept": "*/*"}),
+			wantDiff: "",
+		},
+		{
+			name:     "JSON bodies differing only in key order",
+			a:        newRequest(`{"a":1,"b":2}`, nil),
+			b:        newRequest(`{"b":2, "a":1}`, nil),
+			wantDiff: "",
+		},
+		{
+			name: "differing header",
+			a:    newRequest("", map[string]string{"Accept": "*/*", "Content-Type": "application/json"}),
+			b:    newRequest("", map[string]string{"Accept": "*/*", "Content-Type": "text/plain"}),
+			wantDiff: "--- a\n+++ b\n" +
+				"@@ -1,4 +1,4 @@\n" +
+				" method: POST\n" +
+				" url: http://example.com/cats\n" +
+				" header: Accept: */*\n" +
+				"-header: Content-Type: application/json\n" +
+				"+header: Content-Type: text/plain\n",
+		},
+		{
+			name: "differing JSON field",
+			a:    newRequest(`{"name":"tom","age":3,"color":"grey","owner":"jerry"}`, nil),
+			b:    newRequest(`{"name":"tom","age":4,"color":"grey","owner":"jerry"}`, nil),
+			wantDiff: "--- a\n+++ b\n" +
+				"@@ -2,7 +2,7 @@\n" +
+				" url: http://example.com/cats\n" +
+				" body:\n" +
+				"   {\n" +
+				`-    "age": 3,` + "\n" +
+				`+    "age": 4,` + "\n" +
+				`     "color": "grey",` + "\n" +
+				`     "name": "tom",` + "\n" +
+				`     "owner": "jerry"` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Diff(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			if got != tt.wantDiff {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.wantDiff)
+			}
+		})
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] >= cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestDiffOpsShortest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(3)))
+		}
+		return lines
+	}
+	for run := 0; run < 2000; run++ {
+		a, b := randomLines(), randomLines()
+		var gotA, gotB []string
+		edits := 0
+		for _, op := range diffOps(nil, a, b) {
+			if op.kind != '+' {
+				gotA = append(gotA, op.line)
+			}
+			if op.kind != '-' {
+				gotB = append(gotB, op.line)
+			}
+			if op.kind != ' ' {
+				edits++
+			}
+		}
+		if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("diffOps(%q, %q) does not turn a into b", a, b)
+		}
+		if want := len(a) + len(b) - 2*lcsLength(a, b); edits != want {
+			t.Fatalf("diffOps(%q, %q) makes %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+func TestDiffLargeBodies(t *testing.T) {
+	a := largeJSON(200 << 10)
+	b := strings.ReplaceAll(a, "grey", "gray")
+	ra, _ := http.NewRequest("POST", "http://example.com/cats", strings.NewReader(a))
+	rb, _ := http.NewRequest("POST", "http://example.com/cats", strings.NewReader(b))
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	got, err := Diff(ra, rb)
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if !strings.Contains(got, `-      "color": "grey",`) || !strings.Contains(got, `+      "color": "gray",`) {
+		t.Errorf("Diff() of large bodies misses the changed lines:\n%.500s", got)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 256<<20 {
+		t.Errorf("Diff() of 200 KB bodies allocated %d MiB", allocated>>20)
+	}
+}
@@ -0,0 +1,60 @@
+// Package echomw provides echo middleware attaching the curl command of each
+// incoming request to its context, with http2curl.SensitiveHeaders redacted.
+//
+//	e := echo.New()
+//	e.Use(echomw.Middleware(func(command *http2curl.CurlCommand, status int) {
+//		log.Printf("%d, replay with: %s", status, command)
+//	}))
+package echomw
+
+import (
+	"net/http"
+
+	"github.com/chodges15/http2curl/v3"
+	"github.com/labstack/echo/v4"
+)
+
+// ContextKey is the echo context key holding the curl command
+const ContextKey = "http2curl.command"
+
+// Middleware returns echo middleware storing the curl command of each request
+// on the echo context and the request context. If log is not nil it is
+// called for requests answered with a 5xx status, including handler errors.
+func Middleware(log http2curl.ServerErrorLogger, opts ...http2curl.CurlOption) echo.MiddlewareFunc {
+	converter := http2curl.NewMiddlewareConverter(opts...)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			command, err := converter.Convert(req)
+			if err != nil {
+				return next(c)
+			}
+			c.Set(ContextKey, command)
+			c.SetRequest(req.WithContext(http2curl.ContextWithCommand(req.Context(), command)))
+
+			err = next(c)
+			if log == nil {
+				return err
+			}
+			status := c.Response().Status
+			if err != nil && !c.Response().Committed {
+				// The error handler has not written the response yet
+				status = http.StatusInternalServerError
+				if he, ok := err.(*echo.HTTPError); ok {
+					status = he.Code
+				}
+			}
+			if status >= http.StatusInternalServerError {
+				log(command, status)
+			}
+			return err
+		}
+	}
+}
+
+// Command returns the curl command stored by Middleware, or nil if there is
+// none
+func Command(c echo.Context) *http2curl.CurlCommand {
+	command, _ := c.Get(ContextKey).(*http2curl.CurlCommand)
+	return command
+}
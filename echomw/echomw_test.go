@@ -0,0 +1,53 @@
+package echomw
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chodges15/http2curl/v3"
+	"github.com/labstack/echo/v4"
+)
+
+func TestMiddleware(t *testing.T) {
+	var logged []string
+	var fromContext, fromRequest *http2curl.CurlCommand
+	e := echo.New()
+	e.Use(Middleware(func(command *http2curl.CurlCommand, status int) {
+		logged = append(logged, http.StatusText(status)+": "+command.String())
+	}))
+	e.POST("/cats", func(c echo.Context) error {
+		fromContext = Command(c)
+		fromRequest = http2curl.CommandFromContext(c.Request().Context())
+		return c.NoContent(http.StatusCreated)
+	})
+	e.GET("/fail", func(c echo.Context) error {
+		return errors.New("boom")
+	})
+	e.GET("/unavailable", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusServiceUnavailable)
+	})
+
+	req := httptest.NewRequest("POST", "http://example.com/cats", strings.NewReader("meow"))
+	req.Header.Set("Authorization", "Bearer secret")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://example.com/fail", nil))
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://example.com/unavailable", nil))
+
+	want := `curl -X 'POST' -d 'meow' -H 'Authorization: REDACTED' 'http://example.com/cats'`
+	if fromContext == nil || fromContext.String() != want {
+		t.Errorf("Got:\n%v\nWant:\n%s", fromContext, want)
+	}
+	if fromRequest != fromContext {
+		t.Errorf("request context command = %v, want the echo context command", fromRequest)
+	}
+	wantLogged := []string{
+		`Internal Server Error: curl -X 'GET' 'http://example.com/fail'`,
+		`Service Unavailable: curl -X 'GET' 'http://example.com/unavailable'`,
+	}
+	if len(logged) != 2 || logged[0] != wantLogged[0] || logged[1] != wantLogged[1] {
+		t.Errorf("Got:\n%q\nWant:\n%q", logged, wantLogged)
+	}
+}
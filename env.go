@@ -0,0 +1,91 @@
+package http2curl
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DefaultEnvPrefix is the environment variable prefix OptionsFromEnv uses
+// when none is given
+const DefaultEnvPrefix = "HTTP2CURL"
+
+// OptionsFromEnv returns the options configured by environment variables
+// named after the JSON keys of Config, upper-cased and prefixed with prefix
+// (DefaultEnvPrefix when empty), e.g. HTTP2CURL_REDACT_HEADERS=Authorization,Cookie
+// or HTTP2CURL_SHELL=powershell, so logging can be tuned per deployment.
+// HTTP2CURL_MAX_BODY is accepted for HTTP2CURL_MAX_BODY_SIZE. Lists are
+// comma separated and booleans accept the values of strconv.ParseBool.
+func OptionsFromEnv(prefix string) ([]CurlOption, error) {
+	cfg, err := configFromEnv(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Options()
+}
+
+// envAliases are the shorter names accepted for some variables, without
+// the prefix
+var envAliases = map[string]string{
+	"MAX_BODY_SIZE": "MAX_BODY",
+}
+
+// configFromEnv fills a Config from the environment
+func configFromEnv(prefix string) (Config, error) {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	prefix = strings.TrimSuffix(prefix, "_") + "_"
+
+	var cfg Config
+	v := reflect.ValueOf(&cfg).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		key, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		name := prefix + strings.ToUpper(key)
+		value := os.Getenv(name)
+		if alias, ok := envAliases[strings.ToUpper(key)]; ok && value == "" {
+			name = prefix + alias
+			value = os.Getenv(name)
+		}
+		if value == "" {
+			continue
+		}
+		if err := setFromEnv(v.Field(i), value); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return cfg, nil
+}
+
+// setFromEnv parses value into field
+func setFromEnv(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
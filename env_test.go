@@ -0,0 +1,106 @@
+package http2curl
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOptionsFromEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		prefix      string
+		env         map[string]string
+		wantCommand string
+		wantErr     bool
+	}{
+		{
+			name:        "no variables",
+			wantCommand: `curl -X 'POST' -d 'meow' -H 'Authorization: Bearer secret' -H 'X-Request-Id: 42' 'https://example.com/cats'`,
+		},
+		{
+			name: "default prefix",
+			env: map[string]string{
+				"HTTP2CURL_REDACT_HEADERS":       " Authorization, ",
+				"HTTP2CURL_EXCLUDE_HEADERS":      "X-Request-Id",
+				"HTTP2CURL_INSECURE_SKIP_VERIFY": "true",
+				"HTTP2CURL_SHELL":                "bash",
+				"HTTP2CURL_MAX_BODY_SIZE":        "65536",
+			},
+			wantCommand: `curl -k -X 'POST' -d 'meow' -H 'Authorization: REDACTED' 'https://example.com/cats'`,
+		},
+		{
+			name:        "custom prefix",
+			prefix:      "BILLING_",
+			env:         map[string]string{"BILLING_COMPRESSED": "1", "HTTP2CURL_COMPRESSED": "0"},
+			wantCommand: `curl -X 'POST' -d 'meow' -H 'Authorization: Bearer secret' -H 'X-Request-Id: 42' 'https://example.com/cats' --compressed`,
+		},
+		{
+			name: "powershell",
+			env: map[string]string{
+				"HTTP2CURL_SHELL":          "powershell",
+				"HTTP2CURL_REDACT_HEADERS": "Authorization",
+			},
+			wantCommand: `curl.exe -X 'POST' --data-raw 'meow' -H 'Authorization: REDACTED' -H 'X-Request-Id: 42' 'https://example.com/cats'`,
+		},
+		{
+			name:        "max body size wins over max body",
+			env:         map[string]string{"HTTP2CURL_MAX_BODY_SIZE": "65536", "HTTP2CURL_MAX_BODY": "3"},
+			wantCommand: `curl -X 'POST' -d 'meow' -H 'Authorization: Bearer secret' -H 'X-Request-Id: 42' 'https://example.com/cats'`,
+		},
+		{
+			name:    "invalid max body",
+			env:     map[string]string{"HTTP2CURL_MAX_BODY": "64k"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid number",
+			env:     map[string]string{"HTTP2CURL_MAX_BODY_SIZE": "64k"},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported shell",
+			env:     map[string]string{"HTTP2CURL_SHELL": "fish"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			opts, err := OptionsFromEnv(tt.prefix)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OptionsFromEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			req, _ := http.NewRequest("POST", "https://example.com/cats", strings.NewReader("meow"))
+			req.Header.Set("Authorization", "Bearer secret")
+			req.Header.Set("X-Request-Id", "42")
+			command, err := GetCurlCommand(req, opts...)
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestOptionsFromEnvMaxBody(t *testing.T) {
+	t.Setenv("HTTP2CURL_MAX_BODY", "3")
+	opts, err := OptionsFromEnv("")
+	if err != nil {
+		t.Fatalf("OptionsFromEnv() error = %v", err)
+	}
+	req, _ := http.NewRequest("POST", "https://example.com/cats", strings.NewReader("meow"))
+	if _, err := GetCurlCommand(req, opts...); !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("GetCurlCommand() error = %v, want %v", err, ErrBodyTooLarge)
+	}
+}
@@ -0,0 +1,190 @@
+package http2curl
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ParseEnvoyTap returns the replay command of the request captured by an
+// Envoy tap, given a TraceWrapper holding an http_buffered_trace in JSON,
+// as written by the file per tap sink with the JSON_BODY_AS_BYTES or
+// JSON_BODY_AS_STRING format. Streamed traces are not supported. Bodies the
+// tap truncated are noted in a comment.
+func ParseEnvoyTap(trace []byte, opts ...CurlOption) (*CurlCommand, error) {
+	var wrapper map[string]interface{}
+	if err := json.Unmarshal(trace, &wrapper); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEnvoyFormat, err)
+	}
+	if protoField(wrapper, "http_streamed_trace_segment") != nil {
+		return nil, fmt.Errorf("%w: streamed tap traces are not supported, use a buffered tap", ErrEnvoyFormat)
+	}
+	buffered, _ := protoField(wrapper, "http_buffered_trace").(map[string]interface{})
+	message, _ := protoField(buffered, "request").(map[string]interface{})
+	if message == nil {
+		return nil, fmt.Errorf("%w: no http_buffered_trace request", ErrEnvoyFormat)
+	}
+
+	header := tapHeaders(protoField(message, "headers"))
+	method, target := header.Get(":method"), header.Get(":path")
+	scheme, host := header.Get(":scheme"), header.Get(":authority")
+	for key := range header {
+		if strings.HasPrefix(key, ":") {
+			delete(header, key)
+		}
+	}
+	if host == "" {
+		host = header.Get("Host")
+		header.Del("Host")
+	}
+	if scheme == "" {
+		scheme = "http"
+	}
+	if method == "" || target == "" || host == "" {
+		return nil, fmt.Errorf("%w: request without :method, :path or :authority", ErrEnvoyFormat)
+	}
+
+	var body io.Reader
+	if b, _ := protoField(message, "body").(map[string]interface{}); b != nil {
+		data, err := tapBody(b)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+		if truncated, _ := protoField(b, "truncated").(bool); truncated {
+			opts = append(opts, WithComment("body truncated by the Envoy tap"))
+		}
+	}
+	req, err := http.NewRequest(method, scheme+"://"+host+target, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	if trailer := tapHeaders(protoField(message, "trailers")); len(trailer) > 0 {
+		req.Trailer = trailer
+	}
+	return GetCurlCommand(req, opts...)
+}
+
+// ParseEnvoyAccessLogs returns the replay commands of the HTTP access log
+// entries of an Envoy gRPC access log service StreamAccessLogsMessage in
+// JSON, or of a single HTTPAccessLogEntry. Access log entries carry no
+// body, so the commands have none.
+func ParseEnvoyAccessLogs(msg []byte, opts ...CurlOption) ([]*CurlCommand, error) {
+	var message map[string]interface{}
+	if err := json.Unmarshal(msg, &message); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEnvoyFormat, err)
+	}
+	entries := []interface{}{message}
+	if logs, _ := protoField(message, "http_logs").(map[string]interface{}); logs != nil {
+		entries, _ = protoField(logs, "log_entry").([]interface{})
+	}
+	commands := make([]*CurlCommand, 0, len(entries))
+	for i, e := range entries {
+		entry, _ := e.(map[string]interface{})
+		request, _ := protoField(entry, "request").(map[string]interface{})
+		if request == nil {
+			return nil, fmt.Errorf("%w: log entry %d has no request", ErrEnvoyFormat, i)
+		}
+		command, err := accessLogEntryCommand(request, opts)
+		if err != nil {
+			return nil, fmt.Errorf("log entry %d: %w", i, err)
+		}
+		commands = append(commands, command)
+	}
+	return commands, nil
+}
+
+// accessLogEntryCommand returns the command of the HTTPRequestProperties of
+// an access log entry
+func accessLogEntryCommand(request map[string]interface{}, opts []CurlOption) (*CurlCommand, error) {
+	r := accessLogRecord{
+		method: protoString(request, "request_method"),
+		scheme: protoString(request, "scheme"),
+		host:   protoString(request, "authority"),
+		target: protoString(request, "original_path"),
+	}
+	if r.target == "" {
+		r.target = protoString(request, "path")
+	}
+	if r.host == "" {
+		return nil, fmt.Errorf("%w: request without authority", ErrEnvoyFormat)
+	}
+	r.setHeader("User-Agent", protoString(request, "user_agent"))
+	r.setHeader("Referer", protoString(request, "referer"))
+	r.setHeader("X-Forwarded-For", protoString(request, "forwarded_for"))
+	r.setHeader("X-Request-Id", protoString(request, "request_id"))
+	if headers, _ := protoField(request, "request_headers").(map[string]interface{}); headers != nil {
+		for _, key := range sortedMapKeys(headers) {
+			if value, ok := headers[key].(string); ok && !strings.HasPrefix(key, ":") {
+				r.setHeader(key, value)
+			}
+		}
+	}
+	return r.command(opts)
+}
+
+// protoField returns the field of a protobuf message in JSON, named in
+// either snake_case or the lowerCamelCase of protojson
+func protoField(message map[string]interface{}, name string) interface{} {
+	if value, ok := message[name]; ok {
+		return value
+	}
+	for key, value := range message {
+		if strings.EqualFold(key, strings.ReplaceAll(name, "_", "")) {
+			return value
+		}
+	}
+	return nil
+}
+
+func protoString(message map[string]interface{}, name string) string {
+	s, _ := protoField(message, name).(string)
+	return s
+}
+
+// tapHeaders returns the headers of a list of key/value HeaderValue
+// messages
+func tapHeaders(list interface{}) http.Header {
+	header := http.Header{}
+	items, _ := list.([]interface{})
+	for _, item := range items {
+		h, _ := item.(map[string]interface{})
+		key := protoString(h, "key")
+		if key == "" {
+			continue
+		}
+		if !strings.HasPrefix(key, ":") {
+			key = http.CanonicalHeaderKey(key)
+		}
+		header[key] = append(header[key], protoString(h, "value"))
+	}
+	return header
+}
+
+// tapBody returns the data of a tap Body message
+func tapBody(body map[string]interface{}) ([]byte, error) {
+	if s, ok := protoField(body, "as_string").(string); ok {
+		return []byte(s), nil
+	}
+	encoded, _ := protoField(body, "as_bytes").(string)
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: body: %w", ErrEnvoyFormat, err)
+	}
+	return data, nil
+}
+
+func sortedMapKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
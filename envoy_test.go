@@ -0,0 +1,84 @@
+package http2curl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseEnvoyTap(t *testing.T) {
+	tests := []struct {
+		name  string
+		trace string
+		want  string
+	}{
+		{
+			name: "body as bytes",
+			trace: `{"http_buffered_trace":{"request":{"headers":[{"key":":authority","value":"cats.internal"},` +
+				`{"key":":path","value":"/v1/cats"},{"key":":method","value":"POST"},{"key":":scheme","value":"https"},` +
+				`{"key":"content-type","value":"application/json"},{"key":"x-request-id","value":"5f1c2a"}],` +
+				`"body":{"truncated":false,"as_bytes":"eyJuYW1lIjoidG9tIn0="}},"response":{}}}`,
+			want: `curl -X 'POST' -d '{"name":"tom"}' -H 'Content-Type: application/json' -H 'X-Request-Id: 5f1c2a' 'https://cats.internal/v1/cats'`,
+		},
+		{
+			name: "protojson body as string truncated",
+			trace: `{"httpBufferedTrace":{"request":{"headers":[{"key":":authority","value":"cats.internal"},` +
+				`{"key":":path","value":"/v1/cats"},{"key":":method","value":"PUT"}],` +
+				`"body":{"truncated":true,"asString":"tom"}}}}`,
+			want: "# body truncated by the Envoy tap\n" +
+				`curl -X 'PUT' -d 'tom' 'http://cats.internal/v1/cats'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := ParseEnvoyTap([]byte(tt.trace))
+			if err != nil {
+				t.Fatalf("ParseEnvoyTap() error = %v", err)
+			}
+			if got := command.String(); got != tt.want {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEnvoyTapErrors(t *testing.T) {
+	for _, trace := range []string{
+		`{"http_streamed_trace_segment":{"trace_id":"1"}}`,
+		`{"http_buffered_trace":{"request":{"headers":[{"key":":method","value":"GET"}]}}}`,
+		`{"http_buffered_trace":{"request":{"headers":[{"key":":method","value":"GET"},{"key":":path","value":"/"},` +
+			`{"key":":authority","value":"a"}],"body":{"as_bytes":"!"}}}}`,
+		`not json`,
+	} {
+		if _, err := ParseEnvoyTap([]byte(trace)); !errors.Is(err, ErrEnvoyFormat) {
+			t.Errorf("ParseEnvoyTap(%q) error = %v, want %v", trace, err, ErrEnvoyFormat)
+		}
+	}
+}
+
+func TestParseEnvoyAccessLogs(t *testing.T) {
+	msg := `{"identifier":{"log_name":"als"},"http_logs":{"log_entry":[` +
+		`{"request":{"request_method":"GET","scheme":"https","authority":"cats.internal","path":"/v1/cats",` +
+		`"user_agent":"grpc-go/1.60","request_id":"5f1c2a","request_headers":{"x-api-version":"2",":path":"/v1/cats"}}},` +
+		`{"request":{"requestMethod":"DELETE","authority":"cats.internal","path":"/v1/cats/1","originalPath":"/cats/1"}}]}}`
+	commands, err := ParseEnvoyAccessLogs([]byte(msg))
+	if err != nil {
+		t.Fatalf("ParseEnvoyAccessLogs() error = %v", err)
+	}
+	want := []string{
+		`curl -X 'GET' -H 'User-Agent: grpc-go/1.60' -H 'X-Api-Version: 2' -H 'X-Request-Id: 5f1c2a' 'https://cats.internal/v1/cats'`,
+		`curl -X 'DELETE' 'http://cats.internal/cats/1'`,
+	}
+	if len(commands) != len(want) {
+		t.Fatalf("ParseEnvoyAccessLogs() returned %d commands, want %d", len(commands), len(want))
+	}
+	for i, command := range commands {
+		if got := command.String(); got != want[i] {
+			t.Errorf("Got:\n%s\nWant:\n%s", got, want[i])
+		}
+	}
+
+	if _, err := ParseEnvoyAccessLogs([]byte(`{"http_logs":{"log_entry":[{"request":{"path":"/"}}]}}`)); !errors.Is(err, ErrEnvoyFormat) {
+		t.Errorf("ParseEnvoyAccessLogs() error = %v, want %v", err, ErrEnvoyFormat)
+	}
+}
@@ -0,0 +1,61 @@
+package http2curl
+
+import "errors"
+
+var (
+	// ErrConflictingOptions is returned when the given options cannot be
+	// combined into a working command
+	ErrConflictingOptions = errors.New("conflicting options")
+
+	// ErrBodyRead is returned when the request body cannot be read
+	ErrBodyRead = errors.New("buffer read error")
+
+	// ErrGzipDecompress is returned when a GZIP body cannot be decompressed
+	ErrGzipDecompress = errors.New("gzip decompression failed")
+
+	// ErrBodyTooLarge is returned when the request body exceeds the size set
+	// with WithMaxBodySize
+	ErrBodyTooLarge = errors.New("body too large")
+
+	// ErrUnsupportedEncoding is returned with WithStrict, wrapped in
+	// ErrLossyConversion, when automatic decompression is enabled and the
+	// body uses a Content-Encoding that cannot be decoded. Without
+	// WithStrict such bodies are sent as received and reported by Warnings.
+	ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+
+	// ErrNoRequest is returned when a response does not carry the request
+	// that produced it
+	ErrNoRequest = errors.New("response has no request")
+
+	// ErrUnsupportedCompatVersion is returned when WithCompatVersion names a
+	// version whose output this release cannot reproduce
+	ErrUnsupportedCompatVersion = errors.New("unsupported compat version")
+
+	// ErrControlCharacter is returned with WithRejectControlChars when a
+	// header contains control characters such as CR, LF or NUL
+	ErrControlCharacter = errors.New("control character in header")
+
+	// ErrSecretDetected is returned with WithRejectSecrets when a secret
+	// scanner finds a secret in the command
+	ErrSecretDetected = errors.New("secret detected")
+
+	// ErrInvalidVariable is returned when a variable name set with
+	// WithVariable is not a valid shell identifier
+	ErrInvalidVariable = errors.New("invalid variable name")
+
+	// ErrWireFormat is returned by a WireTransport when the bytes written to
+	// the connection cannot be parsed as an HTTP/1.x request
+	ErrWireFormat = errors.New("unreadable wire format")
+
+	// ErrAccessLogFormat is returned by ParseAccessLog when a log record is
+	// in none of the supported formats
+	ErrAccessLogFormat = errors.New("unrecognized access log format")
+
+	// ErrEnvoyFormat is returned when an Envoy tap trace or access log
+	// message cannot be read
+	ErrEnvoyFormat = errors.New("unreadable envoy message")
+
+	// ErrLossyConversion is returned with WithStrict when the command would
+	// not send the request exactly as it was
+	ErrLossyConversion = errors.New("lossy conversion")
+)
@@ -0,0 +1,36 @@
+package http2curl
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// expectedHeaders are the response headers noted by
+// GetCurlCommandWithExpectation, others being too volatile to compare
+var expectedHeaders = []string{"Content-Type", "Location"}
+
+// GetCurlCommandWithExpectation generates a self-verifying curl command for
+// req: it fails on error statuses, prints the status code, and notes the
+// status and main headers of the observed resp in comments. A -w format set
+// with WithWriteOut replaces the status code.
+func GetCurlCommandWithExpectation(req *http.Request, resp *http.Response, opts ...CurlOption) (*CurlCommand, error) {
+	command, err := GetCurlCommand(req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	command.AddFlag("--fail-with-body")
+	if command.WriteOut == "" {
+		command.WriteOut = `\n%{http_code}\n`
+	}
+	if resp == nil {
+		return command, nil
+	}
+
+	command.Comments = append(command.Comments, fmt.Sprintf("expect status: %d", resp.StatusCode))
+	for _, k := range expectedHeaders {
+		if value := resp.Header.Get(k); value != "" {
+			command.Comments = append(command.Comments, fmt.Sprintf("expect header: %s: %s", k, value))
+		}
+	}
+	return command, nil
+}
@@ -0,0 +1,70 @@
+package http2curl
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetCurlCommandWithExpectation(t *testing.T) {
+	tests := []struct {
+		name        string
+		resp        *http.Response
+		opts        []CurlOption
+		wantCommand string
+	}{
+		{
+			name: "response with headers",
+			resp: &http.Response{
+				StatusCode: http.StatusCreated,
+				Header: http.Header{
+					"Content-Type": {"application/json"},
+					"Location":     {"/users/42"},
+					"Date":         {"Mon, 02 Jan 2006 15:04:05 GMT"},
+				},
+			},
+			wantCommand: "# expect status: 201\n" +
+				"# expect header: Content-Type: application/json\n" +
+				"# expect header: Location: /users/42\n" +
+				`curl -X 'GET' 'http://example.com/users' -w '\n%{http_code}\n' --fail-with-body`,
+		},
+		{
+			name:        "no response",
+			wantCommand: `curl -X 'GET' 'http://example.com/users' -w '\n%{http_code}\n' --fail-with-body`,
+		},
+		{
+			name:        "windows",
+			opts:        []CurlOption{WithWindowsCurl()},
+			wantCommand: `curl -X "GET" "http://example.com/users" -w "\n%%{http_code}\n" --fail-with-body`,
+		},
+		{
+			name:        "write-out",
+			opts:        []CurlOption{WithWriteOut(HealthCheckWriteOut)},
+			wantCommand: `curl -X 'GET' 'http://example.com/users' -w '%{http_code} %{time_total}\n' --fail-with-body`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "http://example.com/users", nil)
+			command, err := GetCurlCommandWithExpectation(req, tt.resp, tt.opts...)
+			if err != nil {
+				t.Fatalf("GetCurlCommandWithExpectation() error = %v", err)
+			}
+			if command.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestGetCurlCommandWithExpectationConfigFile(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com/users", nil)
+	command, err := GetCurlCommandWithExpectation(req, nil)
+	if err != nil {
+		t.Fatalf("GetCurlCommandWithExpectation() error = %v", err)
+	}
+	want := "request = \"GET\"\nurl = \"http://example.com/users\"\nwrite-out = \"\\\\n%{http_code}\\\\n\"\nfail-with-body\n"
+	if got := command.ConfigFileContents(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
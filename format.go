@@ -0,0 +1,52 @@
+package http2curl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// lineBreak separates the flags of MultiLineString
+const lineBreak = " \\\n  "
+
+// MultiLineString returns the command with each flag on its own
+// backslash-continued line
+func (c *CurlCommand) MultiLineString() string {
+	if c.WindowsCurl {
+		return c.renderSeparated(windowsLineBreak)
+	}
+	if c.PowerShell {
+		return c.renderSeparated(powerShellLineBreak)
+	}
+	return c.renderSeparated(lineBreak)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning String
+func (c *CurlCommand) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// WriteTo implements io.WriterTo, writing String to w
+func (c *CurlCommand) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, c.String())
+	return int64(n), err
+}
+
+// Format implements fmt.Formatter. %v and %s give String, %+v gives
+// MultiLineString and the other verbs format String as a string, e.g. %q.
+func (c *CurlCommand) Format(f fmt.State, verb rune) {
+	if c == nil {
+		io.WriteString(f, "<nil>")
+		return
+	}
+	s := c.String()
+	if verb == 'v' && f.Flag('+') {
+		s = c.MultiLineString()
+	}
+	fmt.Fprintf(f, fmt.FormatString(f, verb), s)
+}
+
+// takesParameter reports whether a is a flag followed by a parameter
+func takesParameter(a arg) bool {
+	return a.style == argRaw && strings.HasPrefix(a.value, "-") && !booleanFlags[a.value]
+}
@@ -0,0 +1,94 @@
+package http2curl
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var (
+	_ encoding.TextMarshaler = (*CurlCommand)(nil)
+	_ io.WriterTo            = (*CurlCommand)(nil)
+	_ fmt.Formatter          = (*CurlCommand)(nil)
+)
+
+func TestFormat(t *testing.T) {
+	req, _ := http.NewRequest("PUT", "https://example.com/cats", strings.NewReader(`{"name":"Tom"}`))
+	req.Header.Set("Content-Type", "application/json")
+	command, _ := GetCurlCommand(req, WithInsecureSkipVerify())
+
+	oneLine := `curl -k -X 'PUT' -d '{"name":"Tom"}' -H 'Content-Type: application/json' 'https://example.com/cats'`
+	multiLine := "curl \\\n" +
+		"  -k \\\n" +
+		"  -X 'PUT' \\\n" +
+		`  -d '{"name":"Tom"}' \` + "\n" +
+		"  -H 'Content-Type: application/json' \\\n" +
+		"  'https://example.com/cats'"
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "%v", want: oneLine},
+		{format: "%s", want: oneLine},
+		{format: "%+v", want: multiLine},
+		{format: "%q", want: fmt.Sprintf("%q", oneLine)},
+		{format: "%10.4s", want: "      curl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := fmt.Sprintf(tt.format, command); got != tt.want {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatNil(t *testing.T) {
+	var command *CurlCommand
+	if got := fmt.Sprintf("%v", command); got != "<nil>" {
+		t.Errorf("Sprintf(%%v, nil) = %q, want %q", got, "<nil>")
+	}
+}
+
+func TestMultiLineString(t *testing.T) {
+	req, _ := http.NewRequest("POST", "http://example.com", strings.NewReader("line one\nline two\n"))
+	command, _ := GetCurlCommand(req, WithHeredocBody(), WithTrace("", false))
+	command.AddFlag("--max-time", "10")
+
+	want := "curl \\\n" +
+		"  -X 'POST' \\\n" +
+		"  --data-binary @- \\\n" +
+		"  'http://example.com' \\\n" +
+		"  --trace 'curl-trace.txt' \\\n" +
+		"  --trace-time \\\n" +
+		"  --max-time 10 <<'EOF'\n" +
+		"line one\n" +
+		"line two\n" +
+		"EOF"
+	if got := command.MultiLineString(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
+
+func TestMarshalTextAndWriteTo(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	command, _ := GetCurlCommand(req)
+	want := command.String()
+
+	text, err := command.MarshalText()
+	if err != nil || string(text) != want {
+		t.Errorf("MarshalText() = %q, %v, want %q, nil", text, err, want)
+	}
+
+	var buf bytes.Buffer
+	n, err := command.WriteTo(&buf)
+	if err != nil || buf.String() != want || n != int64(len(want)) {
+		t.Errorf("WriteTo() = %d, %v writing %q, want %d, nil writing %q", n, err, buf.String(), len(want), want)
+	}
+}
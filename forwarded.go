@@ -0,0 +1,66 @@
+package http2curl
+
+import (
+	"net/http"
+	"strings"
+)
+
+// WithForwardedHeaders reconstructs the scheme and host of server side
+// requests from the Forwarded, X-Forwarded-Proto and X-Forwarded-Host headers
+// set by load balancers, and notes the original client in a comment
+func WithForwardedHeaders() CurlOption {
+	return func(c *CurlCommand) {
+		c.ForwardedHeaders = true
+		c.annotators = append(c.annotators, func(req *http.Request) string {
+			if req.URL.Scheme != "" {
+				return ""
+			}
+			if client := forwardedValues(req).client; client != "" {
+				return "client: " + client
+			}
+			return ""
+		})
+	}
+}
+
+type forwarded struct {
+	proto  string
+	host   string
+	client string
+}
+
+// forwardedValues returns the values of the proxy closest to the client,
+// preferring the Forwarded header over X-Forwarded-*
+func forwardedValues(req *http.Request) forwarded {
+	var fwd forwarded
+	if header := req.Header.Get("Forwarded"); header != "" {
+		element, _, _ := strings.Cut(header, ",")
+		for _, pair := range strings.Split(element, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+			value = strings.Trim(value, `"`)
+			switch strings.ToLower(key) {
+			case "proto":
+				fwd.proto = strings.ToLower(value)
+			case "host":
+				fwd.host = value
+			case "for":
+				fwd.client = value
+			}
+		}
+	}
+	if fwd.proto == "" {
+		fwd.proto = strings.ToLower(firstListValue(req.Header.Get("X-Forwarded-Proto")))
+	}
+	if fwd.host == "" {
+		fwd.host = firstListValue(req.Header.Get("X-Forwarded-Host"))
+	}
+	if fwd.client == "" {
+		fwd.client = firstListValue(req.Header.Get("X-Forwarded-For"))
+	}
+	return fwd
+}
+
+func firstListValue(value string) string {
+	first, _, _ := strings.Cut(value, ",")
+	return strings.TrimSpace(first)
+}
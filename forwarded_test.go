@@ -0,0 +1,70 @@
+package http2curl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithForwardedHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		setupReq    func() *http.Request
+		wantCommand string
+	}{
+		{
+			name: "X-Forwarded headers",
+			setupReq: func() *http.Request {
+				req := httptest.NewRequest("GET", "/orders", nil)
+				req.Host = "orders-7d9f8-xk2pl:8080"
+				req.Header.Set("X-Forwarded-Proto", "https")
+				req.Header.Set("X-Forwarded-Host", "api.example.com, lb.internal")
+				req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+				return req
+			},
+			wantCommand: "# client: 203.0.113.7\n" +
+				"curl -X 'GET' -H 'X-Forwarded-For: 203.0.113.7, 10.0.0.1' -H 'X-Forwarded-Host: api.example.com, lb.internal' -H 'X-Forwarded-Proto: https' 'https://api.example.com/orders'",
+		},
+		{
+			name: "Forwarded header takes precedence",
+			setupReq: func() *http.Request {
+				req := httptest.NewRequest("GET", "/orders", nil)
+				req.Host = "orders-7d9f8-xk2pl:8080"
+				req.Header.Set("Forwarded", `for="[2001:db8:cafe::17]";proto=HTTPS;host=api.example.com, for=10.0.0.1`)
+				req.Header.Set("X-Forwarded-Host", "other.example.com")
+				return req
+			},
+			wantCommand: "# client: [2001:db8:cafe::17]\n" +
+				`curl -X 'GET' -H 'Forwarded: for="[2001:db8:cafe::17]";proto=HTTPS;host=api.example.com, for=10.0.0.1' -H 'X-Forwarded-Host: other.example.com' 'https://api.example.com/orders'`,
+		},
+		{
+			name: "no forwarded headers",
+			setupReq: func() *http.Request {
+				return httptest.NewRequest("GET", "/orders", nil)
+			},
+			wantCommand: `curl -X 'GET' 'http://example.com/orders'`,
+		},
+		{
+			name: "client side request",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://orders.internal/orders", nil)
+				req.Header.Set("X-Forwarded-Host", "api.example.com")
+				req.Header.Set("X-Forwarded-For", "203.0.113.7")
+				return req
+			},
+			wantCommand: `curl -X 'GET' -H 'X-Forwarded-For: 203.0.113.7' -H 'X-Forwarded-Host: api.example.com' 'http://orders.internal/orders'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := GetCurlCommand(tt.setupReq(), WithForwardedHeaders())
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if command.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+		})
+	}
+}
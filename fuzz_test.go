@@ -0,0 +1,72 @@
+package http2curl
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// printArgs stands in for curl, printing its arguments terminated by NUL
+// bytes followed by its standard input
+const printArgs = `curl() { printf '%s\0' "$@"; cat; }` + "\n"
+
+// FuzzGetCurlCommand checks that bash parses rendered commands back into
+// the arguments they were rendered from, and pipes the body unchanged when
+// it is read from standard input, whatever the bytes of the request short
+// of the losses reported as LossBodyNUL. Its
+// corpus, in testdata/fuzz/FuzzGetCurlCommand, is kept in the go test fuzz
+// v1 format for reuse by other tools.
+func FuzzGetCurlCommand(f *testing.F) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		f.Skip("bash not found")
+	}
+	f.Fuzz(func(t *testing.T, method, target, value, body string, doubleQuotes, heredoc bool) {
+		req, err := http.NewRequest(method, "http://example.com"+target, strings.NewReader(body))
+		if err != nil {
+			t.Skip()
+		}
+		req.Header.Set("X-Fuzz", value)
+		var opts []CurlOption
+		if doubleQuotes {
+			opts = append(opts, WithDoubleQuoteEscaping())
+		}
+		if heredoc {
+			opts = append(opts, WithHeredocBody())
+		}
+		command, err := GetCurlCommand(req, opts...)
+		if err != nil {
+			t.Skip()
+		}
+		for _, w := range command.Warnings() {
+			if w.Kind == LossBodyNUL {
+				t.Skip() // No shell word holds a NUL byte
+			}
+		}
+		rendered := command.String()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, bash, "-c", printArgs+rendered).Output()
+		if err != nil {
+			t.Fatalf("bash -c %q error = %v", rendered, err)
+		}
+		args := command.args()
+		words := bytes.SplitN(out, []byte{0}, len(args)+1)
+		if len(words) != len(args)+1 {
+			t.Fatalf("%q parsed into %q, want %d arguments", rendered, words, len(args))
+		}
+		for i, a := range args {
+			if string(words[i]) != a.value {
+				t.Errorf("argument %d of %q = %q, want %q", i, rendered, words[i], a.value)
+			}
+		}
+		if stdin := string(words[len(args)]); command.readsStdin() && stdin != command.Body.Data {
+			t.Errorf("%q pipes %q, want %q", rendered, stdin, command.Body.Data)
+		}
+	})
+}
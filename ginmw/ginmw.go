@@ -0,0 +1,46 @@
+// Package ginmw provides gin middleware attaching the curl command of each
+// incoming request to its context, with http2curl.SensitiveHeaders redacted.
+//
+//	router := gin.New()
+//	router.Use(ginmw.Middleware(func(command *http2curl.CurlCommand, status int) {
+//		log.Printf("%d, replay with: %s", status, command)
+//	}))
+package ginmw
+
+import (
+	"net/http"
+
+	"github.com/chodges15/http2curl/v3"
+	"github.com/gin-gonic/gin"
+)
+
+// ContextKey is the gin context key holding the curl command
+const ContextKey = "http2curl.command"
+
+// Middleware returns gin middleware storing the curl command of each request
+// on the gin context and the request context. If log is not nil it is called
+// for requests answered with a 5xx status.
+func Middleware(log http2curl.ServerErrorLogger, opts ...http2curl.CurlOption) gin.HandlerFunc {
+	converter := http2curl.NewMiddlewareConverter(opts...)
+	return func(c *gin.Context) {
+		command, err := converter.Convert(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+		c.Set(ContextKey, command)
+		c.Request = c.Request.WithContext(http2curl.ContextWithCommand(c.Request.Context(), command))
+		c.Next()
+
+		if status := c.Writer.Status(); log != nil && status >= http.StatusInternalServerError {
+			log(command, status)
+		}
+	}
+}
+
+// Command returns the curl command stored by Middleware, or nil if there is
+// none
+func Command(c *gin.Context) *http2curl.CurlCommand {
+	command, _ := c.Value(ContextKey).(*http2curl.CurlCommand)
+	return command
+}
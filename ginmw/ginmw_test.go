@@ -0,0 +1,47 @@
+package ginmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chodges15/http2curl/v3"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logged []string
+	var fromContext, fromRequest *http2curl.CurlCommand
+	router := gin.New()
+	router.Use(Middleware(func(command *http2curl.CurlCommand, status int) {
+		logged = append(logged, http.StatusText(status)+": "+command.String())
+	}))
+	router.POST("/cats", func(c *gin.Context) {
+		fromContext = Command(c)
+		fromRequest = http2curl.CommandFromContext(c.Request.Context())
+		c.Status(http.StatusCreated)
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest("POST", "http://example.com/cats", strings.NewReader("meow"))
+	req.Header.Set("Cookie", "session=secret")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://example.com/fail", nil))
+
+	want := `curl -X 'POST' -d 'meow' -H 'Cookie: REDACTED' 'http://example.com/cats'`
+	if fromContext == nil || fromContext.String() != want {
+		t.Errorf("Got:\n%v\nWant:\n%s", fromContext, want)
+	}
+	if fromRequest != fromContext {
+		t.Errorf("request context command = %v, want the gin context command", fromRequest)
+	}
+	wantLogged := []string{`Internal Server Error: curl -X 'GET' 'http://example.com/fail'`}
+	if len(logged) != 1 || logged[0] != wantLogged[0] {
+		t.Errorf("Got:\n%q\nWant:\n%q", logged, wantLogged)
+	}
+}
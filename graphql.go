@@ -0,0 +1,83 @@
+package http2curl
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// graphQLKeys are the GraphQL-over-HTTP envelope fields in rendering order
+var graphQLKeys = []string{"query", "operationName", "variables", "extensions"}
+
+// WithGraphQL renders GraphQL request bodies readably: the query is printed
+// as comment lines above the command and the envelope, with indented
+// variables, is passed through a here-document
+func WithGraphQL() CurlOption {
+	return func(c *CurlCommand) {
+		c.GraphQL = true
+	}
+}
+
+// formatGraphQL rewrites a GraphQL JSON body, leaving other bodies untouched
+func (c *CurlCommand) formatGraphQL() {
+	if !c.hasBody() {
+		return
+	}
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(c.Body.Data), &envelope); err != nil {
+		return
+	}
+	var query string
+	if err := json.Unmarshal(envelope["query"], &query); err != nil || query == "" {
+		return
+	}
+
+	keys := append([]string(nil), graphQLKeys...)
+	var extra []string
+	for k := range envelope {
+		if !containsString(graphQLKeys, k) {
+			extra = append(extra, k)
+		}
+	}
+	sort.Strings(extra)
+	keys = append(keys, extra...)
+
+	var b bytes.Buffer
+	b.WriteString("{")
+	first := true
+	for _, k := range keys {
+		raw, ok := envelope[k]
+		if !ok {
+			continue
+		}
+		if !first {
+			b.WriteString(",")
+		}
+		first = false
+		name, _ := json.Marshal(k)
+		b.WriteString("\n  ")
+		b.Write(name)
+		b.WriteString(": ")
+		if err := json.Indent(&b, raw, "  ", "  "); err != nil {
+			return
+		}
+	}
+	b.WriteString("\n}")
+
+	c.Body.Data = b.String()
+	c.HeredocBody = true
+	c.Comments = append(c.Comments, "GraphQL query:")
+	for _, line := range strings.Split(strings.TrimSpace(query), "\n") {
+		c.Comments = append(c.Comments, "  "+strings.TrimRight(line, " \t\r"))
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
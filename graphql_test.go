@@ -0,0 +1,64 @@
+package http2curl
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestWithGraphQL(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		opts        []CurlOption
+		wantCommand string
+		wantErr     bool
+	}{
+		{
+			name: "query with variables",
+			body: `{"variables":{"id":"1","first":10},"query":"query GetUser($id: ID!) {\n  user(id: $id) {\n    name\n  }\n}","operationName":"GetUser"}`,
+			wantCommand: "# GraphQL query:\n" +
+				"#   query GetUser($id: ID!) {\n" +
+				"#     user(id: $id) {\n" +
+				"#       name\n" +
+				"#     }\n" +
+				"#   }\n" +
+				"{ printf '%s' \"$(cat)\"; } <<'EOF' | curl -X 'POST' --data-binary @- -H 'Content-Type: application/json' 'http://example.com/graphql'\n" +
+				"{\n" +
+				`  "query": "query GetUser($id: ID!) {\n  user(id: $id) {\n    name\n  }\n}",` + "\n" +
+				`  "operationName": "GetUser",` + "\n" +
+				`  "variables": {` + "\n" +
+				`    "id": "1",` + "\n" +
+				`    "first": 10` + "\n" +
+				`  }` + "\n" +
+				"}\n" +
+				"EOF",
+		},
+		{
+			name:        "non GraphQL JSON body",
+			body:        `{"hello":"world"}`,
+			wantCommand: `curl -X 'POST' -d '{"hello":"world"}' -H 'Content-Type: application/json' 'http://example.com/graphql'`,
+		},
+		{
+			name:    "conflicts with escaped newlines",
+			body:    `{"query":"{ me { name } }"}`,
+			opts:    []CurlOption{WithEscapedNewlines()},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "http://example.com/graphql", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			command, err := GetCurlCommand(req, append([]CurlOption{WithGraphQL()}, tt.opts...)...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetCurlCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && command.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package http2curl
+
+import (
+	"net/http"
+	"strings"
+)
+
+// http2ConnectionHeaders lists the connection-specific headers HTTP/2 does
+// not carry (RFC 9113 section 8.2.2), which curl manages itself
+var http2ConnectionHeaders = map[string]bool{
+	"Connection":        true,
+	"Http2-Settings":    true,
+	"Keep-Alive":        true,
+	"Proxy-Connection":  true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+}
+
+// WithHTTP2Flags renders requests received, or sent through a Transport,
+// over HTTP/2 with --http2, or with --http2-prior-knowledge when they went
+// in cleartext, so the command reaches h2c upstreams that do not accept
+// HTTP/1.1
+func WithHTTP2Flags() CurlOption {
+	return func(c *CurlCommand) {
+		c.HTTP2Flags = true
+	}
+}
+
+// WithH2CPriorKnowledge renders http:// requests that went over h2c
+// (cleartext HTTP/2) with --http2-prior-knowledge, leaving https:// requests
+// to curl's ALPN negotiation
+func WithH2CPriorKnowledge() CurlOption {
+	return func(c *CurlCommand) {
+		c.H2CPriorKnowledge = true
+	}
+}
+
+// isHTTP2 reports whether req was received over HTTP/2
+func isHTTP2(req *http.Request) bool {
+	return req.ProtoMajor == 2
+}
+
+// http2Authority returns the host of an HTTP/2 server request: req.Host as
+// set by net/http from :authority, or the :authority or Host header left by
+// servers and proxies that do not translate them
+func http2Authority(req *http.Request) string {
+	if req.Host != "" {
+		return req.Host
+	}
+	if authority := req.Header.Get(":authority"); authority != "" {
+		return authority
+	}
+	return req.Header.Get("Host")
+}
+
+// skipHTTP2Header reports whether header k of an HTTP/2 request is dropped:
+// pseudo-headers, connection-specific headers, TE other than "trailers" and
+// a Host header repeating the authority
+func skipHTTP2Header(req *http.Request, k string, values []string) bool {
+	switch {
+	case strings.HasPrefix(k, ":"), http2ConnectionHeaders[k]:
+		return true
+	case k == "Te":
+		return len(values) != 1 || !strings.EqualFold(values[0], "trailers")
+	case k == "Host":
+		return len(values) == 1 && strings.EqualFold(values[0], http2Authority(req))
+	}
+	return false
+}
+
+// httpVersionFlag returns the HTTP/2 flag selected for req by HTTP2Flags
+// and H2CPriorKnowledge, if any
+func (c *CurlCommand) httpVersionFlag(req *http.Request) string {
+	if !isHTTP2(req) {
+		return ""
+	}
+	flag := http2Flag(c.URL)
+	if c.HTTP2Flags || (c.H2CPriorKnowledge && flag == "--http2-prior-knowledge") {
+		return flag
+	}
+	return ""
+}
+
+// http2Flag returns the curl flag selecting HTTP/2 for url
+func http2Flag(url string) string {
+	if strings.HasPrefix(url, "https://") {
+		return "--http2"
+	}
+	return "--http2-prior-knowledge"
+}
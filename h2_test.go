@@ -0,0 +1,139 @@
+package http2curl
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTP2ServerRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		tls    bool
+		host   string
+		header http.Header
+		opts   []CurlOption
+		want   string
+	}{
+		{
+			name: "connection headers dropped",
+			host: "example.com",
+			header: http.Header{
+				"Connection":     {"keep-alive"},
+				"Keep-Alive":     {"timeout=5"},
+				"Upgrade":        {"h2c"},
+				"Http2-Settings": {"AAMAAABkAAQCAAAAAAIAAAAA"},
+				"Te":             {"gzip"},
+				"X-Cat":          {"meow"},
+			},
+			want: `curl -X 'GET' -H 'X-Cat: meow' 'http://example.com/cats'`,
+		},
+		{
+			name:   "te trailers kept",
+			host:   "example.com",
+			header: http.Header{"Te": {"trailers"}},
+			want:   `curl -X 'GET' -H 'Te: trailers' 'http://example.com/cats'`,
+		},
+		{
+			name:   "authority pseudo-header",
+			header: http.Header{":authority": {"example.com:8443"}, ":path": {"/cats"}},
+			tls:    true,
+			want:   `curl -X 'GET' 'https://example.com:8443/cats'`,
+		},
+		{
+			name:   "host header repeating authority",
+			host:   "example.com",
+			header: http.Header{"Host": {"example.com"}},
+			want:   `curl -X 'GET' 'http://example.com/cats'`,
+		},
+		{
+			name:   "host header differing from authority",
+			host:   "example.com",
+			header: http.Header{"Host": {"other.example.com"}},
+			want:   `curl -X 'GET' -H 'Host: other.example.com' 'http://example.com/cats'`,
+		},
+		{
+			name: "h2c prior knowledge",
+			host: "example.com",
+			opts: []CurlOption{WithHTTP2Flags()},
+			want: `curl --http2-prior-knowledge -X 'GET' 'http://example.com/cats'`,
+		},
+		{
+			name: "h2 over tls",
+			host: "example.com",
+			tls:  true,
+			opts: []CurlOption{WithHTTP2Flags()},
+			want: `curl --http2 -X 'GET' 'https://example.com/cats'`,
+		},
+		{
+			name: "h2c with WithH2CPriorKnowledge",
+			host: "example.com",
+			opts: []CurlOption{WithH2CPriorKnowledge()},
+			want: `curl --http2-prior-knowledge -X 'GET' 'http://example.com/cats'`,
+		},
+		{
+			name: "h2 over tls with WithH2CPriorKnowledge",
+			host: "example.com",
+			tls:  true,
+			opts: []CurlOption{WithH2CPriorKnowledge()},
+			want: `curl -X 'GET' 'https://example.com/cats'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/cats", nil)
+			req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/2.0", 2, 0
+			req.Host = tt.host
+			req.Header = tt.header
+			if req.Header == nil {
+				req.Header = http.Header{}
+			}
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			command, err := GetCurlCommand(req, tt.opts...)
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.want {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestH2CPriorKnowledgeTransport(t *testing.T) {
+	h2c := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{Proto: "HTTP/2.0", ProtoMajor: 2, StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+	var got string
+	client := &http.Client{Transport: NewTransport(h2c, func(command *CurlCommand, _ *http.Response, _ error) {
+		got = command.String()
+	}, WithCommandOptions(WithH2CPriorKnowledge()))}
+	resp, err := client.Get("http://grpc-gateway.internal/v1/cats")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	want := `curl --http2-prior-knowledge -X 'GET' 'http://grpc-gateway.internal/v1/cats'`
+	if got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
+
+func TestHTTP2FlagsIgnoresHTTP1(t *testing.T) {
+	req := httptest.NewRequest("GET", "/cats", nil)
+	req.Header.Set("Connection", "keep-alive")
+	command, _ := GetCurlCommand(req, WithHTTP2Flags())
+	want := `curl -X 'GET' -H 'Connection: keep-alive' 'http://example.com/cats'`
+	if got := command.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,49 @@
+package http2curl
+
+import "time"
+
+// HealthCheckWriteOut is the -w format of ForHealthCheck, printing the
+// status code and total time of the request on one line
+const HealthCheckWriteOut = `%{http_code} %{time_total}\n`
+
+// WithOutputToNull discards the response body with -o /dev/null
+func WithOutputToNull() CurlOption {
+	return func(c *CurlCommand) {
+		c.OutputFile = "/dev/null"
+	}
+}
+
+// WithWriteOut prints format after the request completes with -w, e.g.
+// "%{http_code}\n"
+func WithWriteOut(format string) CurlOption {
+	return func(c *CurlCommand) {
+		c.WriteOut = format
+	}
+}
+
+// WithFailQuietly renders -fsS, so curl prints no progress meter, only
+// errors, and exits non-zero on HTTP errors
+func WithFailQuietly() CurlOption {
+	return func(c *CurlCommand) {
+		c.FailQuietly = true
+	}
+}
+
+// ForHealthCheck bundles options turning a captured request into a probe
+// for cron or Nagios: curl fails on HTTP errors and timeouts, discards the
+// body and prints only the status code and total time, e.g.
+// curl -X 'GET' 'http://example.com/healthz' -fsS -o '/dev/null'
+// -w '%{http_code} %{time_total}\n' --max-time 5
+func ForHealthCheck(timeout time.Duration) CurlOption {
+	opts := []CurlOption{
+		WithFailQuietly(),
+		WithOutputToNull(),
+		WithWriteOut(HealthCheckWriteOut),
+		WithMaxTime(timeout),
+	}
+	return func(c *CurlCommand) {
+		for _, opt := range opts {
+			opt(c)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package http2curl
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestForHealthCheck(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com/healthz", nil)
+	command, err := GetCurlCommand(req, ForHealthCheck(5*time.Second))
+	if err != nil {
+		t.Fatalf("GetCurlCommand() error = %v", err)
+	}
+	want := `curl --max-time 5 -X 'GET' 'http://example.com/healthz' -fsS -o '/dev/null' -w '%{http_code} %{time_total}\n'`
+	if got := command.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+
+	wantConfig := "max-time = \"5\"\nrequest = \"GET\"\nurl = \"http://example.com/healthz\"\n" +
+		"fail\nsilent\nshow-error\noutput = \"/dev/null\"\nwrite-out = \"%{http_code} %{time_total}\\\\n\"\n"
+	if got := command.ConfigFileContents(); got != wantConfig {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, wantConfig)
+	}
+}
@@ -0,0 +1,65 @@
+// Package heimdallhook generates the curl command of every attempt made by a
+// heimdall client, including retries, for use in logging and error reports.
+//
+//	client := httpclient.NewClient(
+//		httpclient.WithHTTPClient(heimdallhook.NewDoer(http.DefaultClient, logAttempt)),
+//		httpclient.WithRetryCount(3),
+//	)
+package heimdallhook
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/chodges15/http2curl/v3"
+	"github.com/gojek/heimdall/v7"
+)
+
+// LogFunc receives the curl command of an attempt along with its outcome.
+// The command is nil if it could not be generated.
+type LogFunc func(command *http2curl.CurlCommand, resp *http.Response, err error)
+
+// Doer is a heimdall.Doer generating the curl command of each request
+type Doer struct {
+	next      heimdall.Doer
+	log       LogFunc
+	converter *http2curl.Converter
+}
+
+// NewDoer returns a Doer sending requests with next and passing their curl
+// command to log
+func NewDoer(next heimdall.Doer, log LogFunc, opts ...http2curl.CurlOption) *Doer {
+	return &Doer{next: next, log: log, converter: http2curl.NewConverter(opts...)}
+}
+
+// Do sends req with the wrapped Doer. The body heimdall rewinds between
+// retries is read here and a copy sent instead, so retries still carry it.
+func (d *Doer) Do(req *http.Request) (*http.Response, error) {
+	send := req
+	var data []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if data, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		send = req.Clone(req.Context())
+		send.Body = io.NopCloser(bytes.NewReader(data))
+		send.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		}
+	}
+
+	render := req.Clone(req.Context())
+	if data != nil {
+		render.Body = io.NopCloser(bytes.NewReader(data))
+	}
+	command, err := d.converter.Convert(render)
+	if err != nil {
+		command = nil
+	}
+
+	resp, err := d.next.Do(send)
+	d.log(command, resp, err)
+	return resp, err
+}
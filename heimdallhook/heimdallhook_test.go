@@ -0,0 +1,55 @@
+package heimdallhook
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chodges15/http2curl/v3"
+	"github.com/gojek/heimdall/v7"
+	"github.com/gojek/heimdall/v7/httpclient"
+)
+
+func TestDoer(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	var logged []string
+	doer := NewDoer(http.DefaultClient, func(command *http2curl.CurlCommand, resp *http.Response, err error) {
+		if err != nil {
+			t.Errorf("attempt error = %v", err)
+			return
+		}
+		logged = append(logged, resp.Status+": "+command.String())
+	}, http2curl.WithRedactedHeaders("Authorization"))
+	client := httpclient.NewClient(
+		httpclient.WithHTTPClient(doer),
+		httpclient.WithRetryCount(1),
+		httpclient.WithRetrier(heimdall.NewRetrier(heimdall.NewConstantBackoff(time.Millisecond, 0))),
+	)
+
+	resp, err := client.Post(server.URL+"/cats", bytes.NewBufferString("meow"), http.Header{"Authorization": {"Bearer secret"}})
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if len(bodies) != 2 || bodies[0] != "meow" || bodies[1] != "meow" {
+		t.Errorf("bodies received = %q, want the body on both attempts", bodies)
+	}
+	command := `curl -X 'POST' -d 'meow' -H 'Authorization: REDACTED' '` + server.URL + `/cats'`
+	want := []string{"503 Service Unavailable: " + command, "200 OK: " + command}
+	if len(logged) != len(want) || logged[0] != want[0] || logged[1] != want[1] {
+		t.Errorf("Got:\n%q\nWant:\n%q", logged, want)
+	}
+}
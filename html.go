@@ -0,0 +1,63 @@
+package http2curl
+
+import (
+	"html"
+	"strings"
+)
+
+// htmlClasses are the span classes of the parts of HTMLString
+var htmlClasses = map[themePart]string{
+	partFlag:     "curl-flag",
+	partMethod:   "curl-method",
+	partHeader:   "curl-header",
+	partURL:      "curl-url",
+	partBody:     "curl-body",
+	partComment:  "curl-comment",
+	partRedacted: "curl-redacted",
+}
+
+// HTMLString returns String HTML-escaped, with its parts wrapped in spans
+// of the classes curl-flag, curl-method, curl-header, curl-url, curl-body,
+// curl-comment and curl-redacted for styling, e.g. within <pre><code>. The
+// text content of the result is String, ready for copy buttons.
+func (c *CurlCommand) HTMLString() string {
+	p := &htmlPainter{}
+	text := c.renderPainted(" ", p)
+
+	var b strings.Builder
+	b.Grow(len(text) + len(p.marks)*16)
+	pos := 0
+	for _, m := range p.marks {
+		b.WriteString(html.EscapeString(text[pos:m.pos]))
+		pos = m.pos
+		if m.open {
+			b.WriteString(`<span class="` + htmlClasses[m.part] + `">`)
+		} else {
+			b.WriteString("</span>")
+		}
+	}
+	b.WriteString(html.EscapeString(text[pos:]))
+	return b.String()
+}
+
+// htmlMark is the start or end of a part at a position of the rendered
+// command
+type htmlMark struct {
+	pos  int
+	part themePart
+	open bool
+}
+
+// htmlPainter records where parts start and end, for HTMLString to wrap
+// them in spans once the rendered text is escaped
+type htmlPainter struct {
+	marks []htmlMark
+}
+
+func (h *htmlPainter) open(b *strings.Builder, part themePart) {
+	h.marks = append(h.marks, htmlMark{pos: b.Len(), part: part, open: true})
+}
+
+func (h *htmlPainter) close(b *strings.Builder, part themePart) {
+	h.marks = append(h.marks, htmlMark{pos: b.Len(), part: part})
+}
@@ -0,0 +1,31 @@
+package http2curl
+
+import (
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestHTMLString(t *testing.T) {
+	req, _ := http.NewRequest("POST", "http://example.com/cats?a=1&b=2", strings.NewReader("<b>meow</b>"))
+	req.Header.Set("Authorization", "Bearer secret")
+	command, err := GetCurlCommand(req, WithRedactedHeaders("Authorization"), WithComment("cats & dogs"))
+	if err != nil {
+		t.Fatalf("GetCurlCommand() error = %v", err)
+	}
+
+	want := `<span class="curl-comment"># cats &amp; dogs</span>` + "\n" +
+		`curl <span class="curl-flag">-X</span> <span class="curl-method">&#39;POST&#39;</span> ` +
+		`<span class="curl-flag">-d</span> <span class="curl-body">&#39;&lt;b&gt;meow&lt;/b&gt;&#39;</span> ` +
+		`<span class="curl-flag">-H</span> <span class="curl-header">&#39;Authorization: <span class="curl-redacted">REDACTED</span>&#39;</span> ` +
+		`<span class="curl-url">&#39;http://example.com/cats?a=1&amp;b=2&#39;</span>`
+	got := command.HTMLString()
+	if got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+	if text := html.UnescapeString(regexp.MustCompile(`</?span[^>]*>`).ReplaceAllString(got, "")); text != command.String() {
+		t.Errorf("text content Got:\n%s\nWant:\n%s", text, command.String())
+	}
+}
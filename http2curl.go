@@ -3,32 +3,19 @@ package http2curl
 import (
 	"bytes"
 	"compress/gzip"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
-// CurlCommand holds configuration options for curl command generation
-type CurlCommand struct {
-	Command            []string
-	InsecureSkipVerify bool // -k
-	EnableCompression  bool // --compressed
-	AutoDecompressGZIP bool // Automatically decompress GZIP request
-	EscapedNewlines    bool // Escape newline characters in the curl command
-}
-
-// append appends a string to the CurlCommand
-func (c *CurlCommand) append(newSlice ...string) {
-	c.Command = append(c.Command, newSlice...)
-}
-
-// String returns a ready to copy/paste command
-func (c *CurlCommand) String() string {
-	return strings.Join(c.Command, " ")
-}
-
 // CurlOption defines the functional option type
 type CurlOption func(command *CurlCommand)
 
@@ -46,7 +33,18 @@ func WithCompression() CurlOption {
 	}
 }
 
-// WithAutoDecompressGZIP enables automatic GZIP decompression
+// WithAutoCompressedFlag replaces an Accept-Encoding header asking for gzip
+// or br with the --compressed flag, so curl decompresses the response like
+// Go's transport does
+func WithAutoCompressedFlag() CurlOption {
+	return func(c *CurlCommand) {
+		c.AutoCompressedFlag = true
+	}
+}
+
+// WithAutoDecompressGZIP decompresses bodies with a gzip or x-gzip
+// Content-Encoding. Bodies in other encodings are sent as received, which
+// Warnings reports, or fail with ErrUnsupportedEncoding with WithStrict.
 func WithAutoDecompressGZIP() CurlOption {
 	return func(c *CurlCommand) {
 		c.AutoDecompressGZIP = true
@@ -54,100 +52,677 @@ func WithAutoDecompressGZIP() CurlOption {
 }
 
 // WithEscapedNewlines enables retaining newline characters in your curl command
-// by passing them as '\n' through "echo -e" and having curl read the body from standard input
+// by passing them as '\n' through printf and having curl read the body from
+// standard input. Backslashes and percent signs are escaped, so the body is
+// sent exactly; with CompatV1 it is piped through "echo -e" instead.
 func WithEscapedNewlines() CurlOption {
 	return func(c *CurlCommand) {
 		c.EscapedNewlines = true
 	}
 }
 
-// GetCurlCommand generates curl command with configurable options
-func GetCurlCommand(req *http.Request, opts ...CurlOption) (*CurlCommand, error) {
-	command := &CurlCommand{}
-	command.append("curl")
+// WithContentLength emits a Content-Length header computed from the body
+// curl will send. By default curl computes it and the request's own
+// Content-Length, which may be stale after decompression, is dropped.
+func WithContentLength() CurlOption {
+	return func(c *CurlCommand) {
+		c.ContentLength = true
+	}
+}
+
+// WithBracketedIPv6 wraps IPv6 literal hosts in brackets even when the
+// request URL omitted them
+func WithBracketedIPv6() CurlOption {
+	return func(c *CurlCommand) {
+		c.BracketIPv6 = true
+	}
+}
+
+// WithPunycodeHost renders internationalized host names in their ASCII
+// compatible (punycode) form, which older curl builds require
+func WithPunycodeHost() CurlOption {
+	return func(c *CurlCommand) {
+		c.PunycodeHost = true
+	}
+}
+
+// WithUnicodeHost renders internationalized host names as Unicode, decoding
+// punycode labels
+func WithUnicodeHost() CurlOption {
+	return func(c *CurlCommand) {
+		c.UnicodeHost = true
+	}
+}
+
+// WithExactURL renders the path and query exactly as sent instead of
+// re-encoding them, adding --path-as-is when the path has dot segments.
+// Signed URLs break when the encoding changes.
+func WithExactURL() CurlOption {
+	return func(c *CurlCommand) {
+		c.ExactURL = true
+	}
+}
+
+// WithFragment includes the URL fragment in commands generated from server
+// side requests. Client side URLs always keep their fragment.
+func WithFragment() CurlOption {
+	return func(c *CurlCommand) {
+		c.IncludeFragment = true
+	}
+}
+
+// WithLongRequestFlag renders the method with --request instead of -X, which
+// some shell completions handle better for extension methods like PROPFIND
+func WithLongRequestFlag() CurlOption {
+	return func(c *CurlCommand) {
+		c.LongRequestFlag = true
+	}
+}
+
+// WithDoubleQuoteEscaping quotes values with double quotes, escaping ",
+// $, ` and backslashes, for commands embedded in YAML or JSON strings and
+// Dockerfiles where single quotes are awkward to nest
+func WithDoubleQuoteEscaping() CurlOption {
+	return func(c *CurlCommand) {
+		c.DoubleQuotes = true
+	}
+}
+
+// WithSuppressedHeaders keeps curl from sending its default value for the
+// given headers, rendering -H 'Key:', e.g. WithSuppressedHeaders("Accept")
+// when the request had no Accept header. Keys present in the request are
+// sent as usual.
+func WithSuppressedHeaders(keys ...string) CurlOption {
+	return func(c *CurlCommand) {
+		c.SuppressHeaders = append(c.SuppressHeaders, keys...)
+	}
+}
+
+// WithChunkedBody replays bodies of unknown length, which Go's transport
+// sends with chunked transfer encoding, the same way: the body is streamed
+// to curl through standard input with printf and a Transfer-Encoding:
+// chunked header, so server bugs specific to chunked uploads reproduce
+func WithChunkedBody() CurlOption {
+	return func(c *CurlCommand) {
+		c.ChunkedBody = true
+	}
+}
+
+// hasUnknownLength reports whether the body of req is sent chunked
+func hasUnknownLength(req *http.Request) bool {
+	for _, encoding := range req.TransferEncoding {
+		if encoding == "chunked" {
+			return true
+		}
+	}
+	return req.ContentLength < 0 || (req.ContentLength == 0 && req.Body != nil && req.Body != http.NoBody)
+}
+
+// WithFormFields renders application/x-www-form-urlencoded bodies with one
+// -d per field, e.g. -d 'name=Tom' -d 'age=3', which curl joins with &, so
+// single fields are easy to comment out or tweak while debugging
+func WithFormFields() CurlOption {
+	return func(c *CurlCommand) {
+		c.FormFields = true
+	}
+}
+
+// isFormBody reports whether body is an urlencoded form curl can rebuild
+// from its fields
+func isFormBody(contentType, body string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.EqualFold(strings.TrimSpace(mediaType), "application/x-www-form-urlencoded") &&
+		!strings.ContainsAny(body, "\r\n")
+}
 
-	decompressedBody := false
+// DefaultTraceFile is the file WithTrace writes to when no path is given
+const DefaultTraceFile = "curl-trace.txt"
+
+// WithTrace makes curl write a full wire trace to path with --trace, or
+// readable with --trace-ascii when ascii is set, timestamped with
+// --trace-time. An empty path writes to DefaultTraceFile.
+func WithTrace(path string, ascii bool) CurlOption {
+	return func(c *CurlCommand) {
+		if path == "" {
+			path = DefaultTraceFile
+		}
+		c.TraceFile = path
+		c.TraceASCII = ascii
+	}
+}
+
+// WithMaxTime limits the whole transfer to d with --max-time, e.g. 2.5s
+// renders --max-time 2.5
+func WithMaxTime(d time.Duration) CurlOption {
+	return func(c *CurlCommand) {
+		c.MaxTime = d
+	}
+}
+
+// WithConnectTimeout limits the time curl spends connecting to d with
+// --connect-timeout
+func WithConnectTimeout(d time.Duration) CurlOption {
+	return func(c *CurlCommand) {
+		c.ConnectTimeout = d
+	}
+}
+
+// WithMaxBodySize makes command generation fail with ErrBodyTooLarge when
+// the request body is larger than n bytes
+func WithMaxBodySize(n int64) CurlOption {
+	return func(c *CurlCommand) {
+		c.MaxBodySize = n
+	}
+}
+
+// WithMaxDecompressedSize guards automatic decompression against
+// decompression bombs, making command generation fail with ErrBodyTooLarge
+// when a body decompresses to more than n bytes. Set WithTruncatedDecompression
+// to render the first n bytes instead.
+func WithMaxDecompressedSize(n int64) CurlOption {
+	return func(c *CurlCommand) {
+		c.MaxDecompressedSize = n
+	}
+}
+
+// WithTruncatedDecompression renders bodies over the WithMaxDecompressedSize
+// limit cut to the limit, with a comment marking the truncation, instead of
+// failing
+func WithTruncatedDecompression() CurlOption {
+	return func(c *CurlCommand) {
+		c.TruncateDecompressed = true
+	}
+}
+
+// WithRedactedHeaders replaces the values of the given headers with
+// REDACTED, keeping credentials out of logged commands
+func WithRedactedHeaders(keys ...string) CurlOption {
+	return func(c *CurlCommand) {
+		c.RedactHeaders = append(c.RedactHeaders, keys...)
+	}
+}
+
+// HeaderTransformer rewrites a request header before it is rendered. It
+// returns the new key and values, and false to drop the header.
+type HeaderTransformer func(key string, values []string) (string, []string, bool)
+
+// WithHeaderTransformer registers a function invoked for every header, e.g.
+// to drop internal headers or normalize authentication. Transformers run in
+// the order they were registered, before redaction.
+func WithHeaderTransformer(transform HeaderTransformer) CurlOption {
+	return func(c *CurlCommand) {
+		c.headerTransformers = append(c.headerTransformers, transform)
+	}
+}
+
+// WithIncludeHeaders restricts the rendered headers to the given names
+func WithIncludeHeaders(keys ...string) CurlOption {
+	return func(c *CurlCommand) {
+		c.IncludeHeaders = append(c.IncludeHeaders, keys...)
+	}
+}
+
+// WithExcludeHeaders drops the given headers from the command
+func WithExcludeHeaders(keys ...string) CurlOption {
+	return func(c *CurlCommand) {
+		c.ExcludeHeaders = append(c.ExcludeHeaders, keys...)
+	}
+}
+
+// WithoutDefaultGoHeaders drops the User-Agent and Accept-Encoding headers
+// added by Go's HTTP transport, as seen on server side requests, when they
+// still have their default values
+func WithoutDefaultGoHeaders() CurlOption {
+	return WithHeaderTransformer(func(key string, values []string) (string, []string, bool) {
+		if len(values) != 1 {
+			return key, values, true
+		}
+		switch {
+		case strings.EqualFold(key, "User-Agent") && strings.HasPrefix(values[0], "Go-http-client/"):
+			return key, values, false
+		case strings.EqualFold(key, "Accept-Encoding") && values[0] == "gzip":
+			return key, values, false
+		}
+		return key, values, true
+	})
+}
+
+// WithURLRewriter registers a function rewriting the request URL before it
+// is rendered, e.g. to swap internal hostnames for externally reachable ones.
+// The function receives a copy of the URL and may modify and return it.
+func WithURLRewriter(rewrite func(*url.URL) *url.URL) CurlOption {
+	return func(c *CurlCommand) {
+		c.urlRewriters = append(c.urlRewriters, rewrite)
+	}
+}
+
+// WithHeredocBody passes the body verbatim to curl through a here-document,
+// keeping multi-line bodies readable. A here-document always ends with a
+// newline, so bodies that do not are piped with printf '%s' instead.
+func WithHeredocBody() CurlOption {
+	return func(c *CurlCommand) {
+		c.HeredocBody = true
+	}
+}
+
+// WithCurlJSON passes JSON bodies with --json (curl 7.82.0+), which implies
+// the JSON Content-Type and Accept headers. Bodies are passed verbatim,
+// newlines included.
+func WithCurlJSON() CurlOption {
+	return func(c *CurlCommand) {
+		c.CurlJSON = true
+	}
+}
+
+// GetCurlCommand generates curl command with configurable options, applied
+// after those set with SetDefaultOptions
+func GetCurlCommand(req *http.Request, opts ...CurlOption) (*CurlCommand, error) {
+	command := &CurlCommand{cache: &renderCache{}}
+	command.applyDefaults()
 
 	// Apply options
 	for _, opt := range opts {
 		opt(command)
 	}
 
-	// Configure SSL verification
-	if command.InsecureSkipVerify && req.URL.Scheme == "https" {
-		command.append("-k")
+	return command.generate(req)
+}
+
+// generate fills in the command from req using the options already applied
+// to the command
+func (c *CurlCommand) generate(req *http.Request) (*CurlCommand, error) {
+	start := time.Now()
+	err := c.build(req)
+	c.duration = time.Since(start)
+	c.observe(err)
+	if err != nil {
+		c.RemoveTempFiles()
+		return nil, err
+	}
+	return c, nil
+}
+
+// build fills in the command from req
+func (c *CurlCommand) build(req *http.Request) error {
+	decompressedBody, rewrittenBody := false, false
+	transcodedContentType := ""
+
+	// Apply options attached to the request context
+	for _, opt := range contextOptions(req.Context()) {
+		opt(c)
+	}
+
+	if err := c.validateOptions(); err != nil {
+		return err
 	}
 
-	command.append("-X", bashEscape(req.Method))
+	c.Method = req.Method
 
-	// Process request body
-	if req.Body != nil {
-		var buff bytes.Buffer
-		if _, err := buff.ReadFrom(req.Body); err != nil {
-			return nil, fmt.Errorf("buffer read error: %w", err)
+	// Process request body, unless curl reads it from a file
+	if c.BodyFile == "" && !c.compatV1() {
+		c.BodyFile = fileBodyPath(req.Body)
+	}
+	if req.Body != nil && c.BodyFile == "" {
+		var data []byte
+		var raw string
+		truncated := false
+		if c.BodyPeek > 0 {
+			peeked, more, err := c.peekBody(req)
+			if err != nil {
+				return err
+			}
+			data, raw, truncated = peeked, string(peeked), more
+		} else {
+			buff := getBuffer()
+			defer putBuffer(buff)
+			body := io.Reader(req.Body)
+			if c.MaxBodySize > 0 {
+				body = io.LimitReader(req.Body, c.MaxBodySize+1)
+			}
+			n, err := buff.ReadFrom(body)
+			c.bodyBytes = n
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrBodyRead, err)
+			}
+			if c.MaxBodySize > 0 && int64(buff.Len()) > c.MaxBodySize {
+				req.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(bytes.Clone(buff.Bytes())), req.Body), req.Body}
+				return fmt.Errorf("%w: more than %d bytes", ErrBodyTooLarge, c.MaxBodySize)
+			}
+			// A single copy of the body is shared by the restored request body
+			// and the command, the buffer going back to the pool
+			data, raw = buff.Bytes(), buff.String()
+			req.Body = io.NopCloser(strings.NewReader(raw))
 		}
-		req.Body = io.NopCloser(bytes.NewBuffer(buff.Bytes()))
 
-		// Handle GZIP decompression if enabled
-		if command.AutoDecompressGZIP && req.Header.Get("Content-Encoding") == "gzip" {
-			decompressed, err := decompressGZIP(buff.Bytes())
+		// Handle GZIP decompression if enabled, other encodings being sent
+		// as received
+		encoding := strings.TrimSpace(req.Header.Get("Content-Encoding"))
+		gzipped := strings.EqualFold(encoding, "gzip") || strings.EqualFold(encoding, "x-gzip")
+		if c.AutoDecompressGZIP && gzipped && !truncated {
+			decompressed, err := c.decompressGZIP(data)
 			if err != nil {
-				return nil, err
+				return err
 			}
-			buff.Reset()
-			buff.Write(decompressed)
+			data, raw = decompressed, string(decompressed)
 			decompressedBody = true
 		}
 
-		if buff.Len() > 0 {
-			escapedBody := bashEscape(buff.String())
-			escapedBody = strings.ReplaceAll(escapedBody, "\n", "\\n")
-			if command.EscapedNewlines {
-				echoCommand := []string{fmt.Sprintf("echo -e %s", escapedBody)}
-				echoCommand = append(echoCommand, "|")
-				command.Command = append(echoCommand, command.Command...)
-				command.append("-d", "@-") // Read from standard input
-			} else {
-				escapedBody = strings.ReplaceAll(escapedBody, "\n", "\\n")
-				command.append("-d", escapedBody)
+		if c.CurlJSON && len(data) > 0 && !truncated && !c.bodyTruncated && !json.Valid(data) {
+			return fmt.Errorf("%w: WithCurlJSON requires a JSON body", ErrConflictingOptions)
+		}
+
+		if len(raw) > 0 {
+			c.Body = &BodySpec{Data: raw, Chunked: c.ChunkedBody && hasUnknownLength(req)}
+		}
+		if c.NormalizeJSON && !truncated {
+			c.normalizeJSON()
+		}
+		if c.GraphQL {
+			c.formatGraphQL()
+		}
+		if c.PrettyXML {
+			c.formatXML(req.Header.Get("Content-Type"))
+		}
+		if c.TranscodeBody {
+			transcodedContentType = c.transcodeBody(req.Header.Get("Content-Type"))
+		}
+		rewrittenBody = c.hasBody() && c.Body.Data != raw
+		if !truncated {
+			c.decodeBody(req.Header.Get("Content-Type"))
+		}
+		if c.PreserveMultipartBoundary {
+			c.preserveMultipart(req.Header.Get("Content-Type"))
+		} else if c.MultipartForm && !truncated {
+			if err := c.buildFormParts(req.Header.Get("Content-Type")); err != nil {
+				return err
 			}
 		}
+		if c.FormFields && !truncated && c.hasBody() && !c.CurlJSON && isFormBody(req.Header.Get("Content-Type"), c.Body.Data) {
+			c.Body.Fields = true
+		}
 	}
 
 	// Add headers
+	if c.Headers == nil {
+		c.Headers = make([]Header, 0, len(req.Header)+len(req.Trailer))
+	}
 	for _, k := range sortedKeys(req.Header) {
-		if decompressedBody && (k == "Content-Encoding" || k == "Content-Length") {
+		if k == "Content-Length" && (!c.compatV1() || decompressedBody || c.ContentLength) {
+			continue // Computed by curl, or rendered by WithContentLength
+		}
+		if decompressedBody && k == "Content-Encoding" {
+			continue
+		}
+		if isHTTP2(req) && skipHTTP2Header(req, k, req.Header[k]) {
+			continue // Carried by the HTTP/2 framing, not as headers
+		}
+		key, values, keep := k, req.Header[k], true
+		for _, transform := range c.headerTransformers {
+			if key, values, keep = transform(key, values); !keep {
+				break
+			}
+		}
+		if !keep || !c.headerAllowed(key) {
+			c.recordLoss(LossHeaderDropped, key, "header dropped: "+key)
+			continue
+		}
+		if c.AutoCompressedFlag && strings.EqualFold(key, "Accept-Encoding") && acceptsCompression(values) {
+			c.EnableCompression = true
 			continue
 		}
-		command.append("-H", bashEscape(fmt.Sprintf("%s: %s", k, strings.Join(req.Header[k], " "))))
+		if containsFold(c.RedactHeaders, key) {
+			values = []string{redactedValue}
+			c.recordRedaction(RedactedHeader, key, 1)
+		}
+		for _, value := range c.headerValues(key, values) {
+			key, value, err := c.sanitizeHeader(key, value)
+			if err != nil {
+				return err
+			}
+			c.Headers = append(c.Headers, Header{Key: key, Value: value})
+		}
+	}
+	if transcodedContentType != "" {
+		for i := range c.Headers {
+			if strings.EqualFold(c.Headers[i].Key, "Content-Type") {
+				c.Headers[i].Value = transcodedContentType
+			}
+		}
+	}
+	if len(c.FormParts) > 0 {
+		c.RemoveHeader("Content-Type") // Set by curl with its own boundary
+	}
+	if c.Body != nil && c.Body.Chunked && !c.hasHeader("Transfer-Encoding") {
+		c.Headers = append(c.Headers, Header{Key: "Transfer-Encoding", Value: "chunked"})
+	}
+	if !c.compatV1() {
+		if err := c.addTrailers(req.Trailer); err != nil {
+			return err
+		}
+	}
+	sort.SliceStable(c.Headers, func(i, j int) bool { return c.Headers[i].Key < c.Headers[j].Key })
+
+	if c.compatV1() {
+		c.URL = v1RequestURL(req)
+	} else {
+		c.URL = c.requestURL(req)
+		c.applyRequestTarget(req)
+	}
+	if err := c.applyTransportProxy(req); err != nil {
+		return err
+	}
+	if flag := c.httpVersionFlag(req); flag != "" {
+		c.HTTPVersion = flag
+	}
+	requestURL := c.URL
+	if len(c.urlRewriters) > 0 {
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			return fmt.Errorf("url parse error: %w", err)
+		}
+		for _, rewrite := range c.urlRewriters {
+			if rewritten := rewrite(u); rewritten != nil {
+				u = rewritten
+			}
+		}
+		c.URL = u.String()
+	}
+	if err := c.addResolve(req.Context(), c.URL); err != nil {
+		return err
+	}
+	if err := c.addJarCookies(req, requestURL); err != nil {
+		return err
+	}
+
+	c.annotate(req)
+	c.addTimings(req)
+	if err := c.scanSecrets(); err != nil {
+		return err
+	}
+	c.substituteVariables()
+	c.applyWindowsBody()
+	c.fitLength()
+	c.recordLosses(req, rewrittenBody, decompressedBody)
+	if c.Strict {
+		return c.checkStrict()
 	}
 
-	command.append(bashEscape(requestURL(req)))
+	return nil
+}
 
-	if command.EnableCompression {
-		command.append("--compressed")
+// addTrailers announces request trailers with a Trailer header and a chunked
+// upload. curl cannot send trailers, so their values are noted in comments.
+func (c *CurlCommand) addTrailers(trailer http.Header) error {
+	if len(trailer) == 0 {
+		return nil
+	}
+	keys := sortedKeys(trailer)
+	if !c.hasHeader("Trailer") {
+		c.Headers = append(c.Headers, Header{Key: "Trailer", Value: strings.Join(keys, ", ")})
+	}
+	if !c.hasHeader("Transfer-Encoding") {
+		c.Headers = append(c.Headers, Header{Key: "Transfer-Encoding", Value: "chunked"})
+	}
+	for _, k := range keys {
+		values := trailer[k]
+		if containsFold(c.RedactHeaders, k) {
+			values = []string{redactedValue}
+			c.recordRedaction(RedactedTrailer, k, 1)
+		}
+		for _, value := range c.headerValues(k, values) {
+			if value == "" {
+				value = "(set after the body is sent)"
+			}
+			k, value, err := c.sanitizeHeader(k, value)
+			if err != nil {
+				return err
+			}
+			c.Comments = append(c.Comments, fmt.Sprintf("trailer not sent by curl: %s: %s", k, value))
+		}
 	}
+	return nil
+}
 
-	return command, nil
+// emptyValue stands in for a header without values
+var emptyValue = []string{""}
+
+// headerValues returns the values of a header as rendered. Cookie values are
+// joined with "; " like a single Cookie header; other headers keep one line
+// per value, so values containing commas or spaces (Set-Cookie, signatures)
+// are sent unchanged.
+func (c *CurlCommand) headerValues(key string, values []string) []string {
+	switch {
+	case len(values) == 0:
+		return emptyValue
+	case len(values) == 1:
+		return values
+	case c.compatV1():
+		return []string{strings.Join(values, " ")}
+	case strings.EqualFold(key, "Cookie"):
+		return []string{strings.Join(values, "; ")}
+	default:
+		return values
+	}
 }
 
+func (c *CurlCommand) hasHeader(key string) bool {
+	for _, h := range c.Headers {
+		if strings.EqualFold(h.Key, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerAllowed reports whether the include and exclude lists allow the header
+func (c *CurlCommand) headerAllowed(key string) bool {
+	if len(c.IncludeHeaders) > 0 && !containsFold(c.IncludeHeaders, key) {
+		return false
+	}
+	return !containsFold(c.ExcludeHeaders, key)
+}
+
+// validateOptions checks for option combinations that would render a broken command
+func (c *CurlCommand) validateOptions() error {
+	if err := validateCompatVersion(c.CompatVersion); err != nil {
+		return err
+	}
+	if err := c.validateVariables(); err != nil {
+		return err
+	}
+	if err := c.validateTLS(); err != nil {
+		return err
+	}
+	if c.EscapedNewlines && c.HeredocBody {
+		return fmt.Errorf("%w: WithEscapedNewlines and WithHeredocBody both read the body from standard input", ErrConflictingOptions)
+	}
+	if c.EscapedNewlines && c.GraphQL {
+		return fmt.Errorf("%w: WithEscapedNewlines and WithGraphQL both read the body from standard input", ErrConflictingOptions)
+	}
+	if c.EscapedNewlines && c.PrettyXML {
+		return fmt.Errorf("%w: WithEscapedNewlines and WithPrettyXML both read the body from standard input", ErrConflictingOptions)
+	}
+	if c.PunycodeHost && c.UnicodeHost {
+		return fmt.Errorf("%w: WithPunycodeHost and WithUnicodeHost select different host forms", ErrConflictingOptions)
+	}
+	return nil
+}
+
+// acceptsCompression reports whether Accept-Encoding values include an
+// encoding curl decodes with --compressed
+func acceptsCompression(values []string) bool {
+	for _, value := range values {
+		for _, encoding := range strings.Split(value, ",") {
+			encoding, _, _ = strings.Cut(encoding, ";")
+			switch strings.ToLower(strings.TrimSpace(encoding)) {
+			case "gzip", "br":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redactedValue replaces redacted values in the command
+const redactedValue = "REDACTED"
+
 // Helper functions
+// bufferPool holds the buffers request bodies are read into
+var bufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// maxPooledBuffer is the capacity above which buffers are left to the
+// garbage collector rather than pinning memory in the pool
+const maxPooledBuffer = 1 << 20
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buff *bytes.Buffer) {
+	if buff.Cap() > maxPooledBuffer {
+		return
+	}
+	buff.Reset()
+	bufferPool.Put(buff)
+}
+
 func bashEscape(str string) string {
 	return `'` + strings.Replace(str, `'`, `'\''`, -1) + `'`
 }
 
-func decompressGZIP(data []byte) ([]byte, error) {
+func (c *CurlCommand) decompressGZIP(data []byte) ([]byte, error) {
 	gzReader, err := gzip.NewReader(bytes.NewReader(data))
 	if err != nil {
-		return nil, fmt.Errorf("gzip decompression failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrGzipDecompress, err)
 	}
-	defer func(gzReader *gzip.Reader) {
-		err := gzReader.Close()
-		if err != nil {
+	defer gzReader.Close()
+	decompressed, err := c.readDecompressed(gzReader)
+	if err != nil && !errors.Is(err, ErrBodyTooLarge) {
+		return nil, fmt.Errorf("%w: %w", ErrGzipDecompress, err)
+	}
+	return decompressed, err
+}
 
-		}
-	}(gzReader)
-	return io.ReadAll(gzReader)
+// readDecompressed reads a decompressing reader up to MaxDecompressedSize,
+// failing with ErrBodyTooLarge or truncating the output past it
+func (c *CurlCommand) readDecompressed(r io.Reader) ([]byte, error) {
+	limit := c.MaxDecompressedSize
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	decompressed, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil || int64(len(decompressed)) <= limit {
+		return decompressed, err
+	}
+	if !c.TruncateDecompressed {
+		return nil, fmt.Errorf("%w: more than %d bytes decompressed", ErrBodyTooLarge, limit)
+	}
+	c.bodyTruncated = true
+	c.Comments = append(c.Comments, fmt.Sprintf("decompressed body truncated to %d bytes", limit))
+	return decompressed[:limit], nil
 }
 
 func sortedKeys(h http.Header) []string {
@@ -159,13 +734,104 @@ func sortedKeys(h http.Header) []string {
 	return keys
 }
 
-func requestURL(req *http.Request) string {
+func (c *CurlCommand) requestURL(req *http.Request) string {
 	if req.URL.Scheme == "" {
-		scheme := "http"
+		scheme, host := "http", req.Host
+		if isHTTP2(req) {
+			host = http2Authority(req)
+		}
 		if req.TLS != nil {
 			scheme = "https"
 		}
-		return fmt.Sprintf("%s://%s%s", scheme, req.Host, req.URL.Path)
+		if c.ForwardedHeaders {
+			fwd := forwardedValues(req)
+			if fwd.proto == "http" || fwd.proto == "https" {
+				scheme = fwd.proto
+			}
+			if fwd.host != "" {
+				host = fwd.host
+			}
+		}
+		path := req.URL.Path
+		if c.ExactURL {
+			path = exactRequestURI(req)
+		} else if req.URL.ForceQuery || req.URL.RawQuery != "" {
+			path += "?" + req.URL.RawQuery
+		}
+		if c.IncludeFragment && req.URL.Fragment != "" && !strings.Contains(path, "#") {
+			path += "#" + req.URL.EscapedFragment()
+		}
+		return fmt.Sprintf("%s://%s%s", scheme, c.formatHost(host), path)
+	}
+	if req.URL.Opaque != "" {
+		return req.URL.String()
+	}
+	if c.ExactURL {
+		prefix := req.URL.Scheme + "://"
+		if req.URL.User != nil {
+			prefix += req.URL.User.String() + "@"
+		}
+		return prefix + c.formatHost(req.URL.Host) + exactRequestURI(req)
+	}
+
+	// Splice the formatted host in place of the one written by URL.String
+	rendered := req.URL.String()
+	prefix := req.URL.Scheme + "://"
+	if req.URL.User != nil {
+		prefix += req.URL.User.String() + "@"
+	}
+	escapedHost := strings.TrimPrefix((&url.URL{Host: req.URL.Host}).String(), "//")
+	if !strings.HasPrefix(rendered, prefix+escapedHost) {
+		return rendered
+	}
+	return prefix + c.formatHost(req.URL.Host) + rendered[len(prefix)+len(escapedHost):]
+}
+
+// exactRequestURI returns the path and query of req encoded exactly as they
+// were received or set, rather than as re-encoded by URL.String
+func exactRequestURI(req *http.Request) string {
+	if req.URL.Scheme == "" && strings.HasPrefix(req.RequestURI, "/") {
+		return req.RequestURI
+	}
+	path := req.URL.RawPath
+	if path == "" {
+		path = req.URL.EscapedPath()
+	}
+	if req.URL.ForceQuery || req.URL.RawQuery != "" {
+		path += "?" + req.URL.RawQuery
+	}
+	if req.URL.Fragment != "" {
+		path += "#" + req.URL.EscapedFragment()
+	}
+	return path
+}
+
+// formatHost percent-encodes the zone identifier of IPv6 literal hosts,
+// brackets them when BracketIPv6 is set, and converts internationalized
+// names according to PunycodeHost and UnicodeHost
+func (c *CurlCommand) formatHost(host string) string {
+	if c.BracketIPv6 {
+		host = bracketIPv6(host)
+	}
+	if c.PunycodeHost || c.UnicodeHost {
+		host = hostToASCII(host)
+	}
+	host = strings.TrimPrefix((&url.URL{Host: host}).String(), "//")
+	if c.UnicodeHost {
+		host = hostToUnicode(host)
+	}
+	return host
+}
+
+// bracketIPv6 wraps an IPv6 literal host, with an optional zone identifier,
+// in brackets
+func bracketIPv6(host string) string {
+	if strings.HasPrefix(host, "[") {
+		return host
+	}
+	addr, _, _ := strings.Cut(host, "%")
+	if ip := net.ParseIP(addr); ip != nil && ip.To4() == nil {
+		return "[" + host + "]"
 	}
-	return req.URL.String()
+	return host
 }
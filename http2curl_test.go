@@ -4,12 +4,18 @@ import (
 	"bytes"
 	"compress/gzip"
 	"crypto/tls"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os/exec"
+	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestGetCurlCommand(t *testing.T) {
@@ -32,6 +38,29 @@ func TestGetCurlCommand(t *testing.T) {
 			wantCommand: `curl -X 'POST' -d '{"test":"gzip"}' ` +
 				`'http://example.com'`,
 		},
+		{
+			name: "GZIP decompression truncated at the limit",
+			setupReq: func() *http.Request {
+				body := compressData([]byte(`{"test":"gzip"}`))
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewReader(body))
+				req.Header.Set("Content-Encoding", "gzip")
+				return req
+			},
+			opts: []CurlOption{WithAutoDecompressGZIP(), WithMaxDecompressedSize(8), WithTruncatedDecompression()},
+			wantCommand: "# decompressed body truncated to 8 bytes\n" +
+				`curl -X 'POST' -d '{"test":' 'http://example.com'`,
+		},
+		{
+			name: "GZIP decompression within the limit",
+			setupReq: func() *http.Request {
+				body := compressData([]byte(`{"test":"gzip"}`))
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewReader(body))
+				req.Header.Set("Content-Encoding", "gzip")
+				return req
+			},
+			opts:        []CurlOption{WithAutoDecompressGZIP(), WithMaxDecompressedSize(15)},
+			wantCommand: `curl -X 'POST' -d '{"test":"gzip"}' 'http://example.com'`,
+		},
 		{
 			name: "Invalid GZIP data with auto-decompress",
 			setupReq: func() *http.Request {
@@ -109,7 +138,25 @@ func TestGetCurlCommand(t *testing.T) {
 				return req
 			},
 			opts:        []CurlOption{WithEscapedNewlines()},
-			wantCommand: `echo -e 'hello\nworld' | curl -X 'POST' -d @- -H 'Content-Type: application/json' 'http://www.example.com/abc/def.ghi?jlk=mno&pqr=stu'`,
+			wantCommand: `printf 'hello\nworld' | curl -X 'POST' --data-binary @- -H 'Content-Type: application/json' 'http://www.example.com/abc/def.ghi?jlk=mno&pqr=stu'`,
+		},
+		{
+			name: "escape sequences in body with escaped newlines",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://www.example.com", bytes.NewBufferString("-n 50%\\t off\n"))
+				return req
+			},
+			opts:        []CurlOption{WithEscapedNewlines()},
+			wantCommand: `printf '\055n 50%%\\t off\n' | curl -X 'POST' --data-binary @- 'http://www.example.com'`,
+		},
+		{
+			name: "escaped newlines with compat v1",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://www.example.com", bytes.NewBufferString("hello\nworld"))
+				return req
+			},
+			opts:        []CurlOption{WithEscapedNewlines(), WithCompatVersion(CompatV1)},
+			wantCommand: `echo -e 'hello\nworld' | curl -X 'POST' -d @- 'http://www.example.com'`,
 		},
 		{
 			name: "newline in body without escaped newlines",
@@ -163,6 +210,624 @@ func TestGetCurlCommand(t *testing.T) {
 			},
 			wantCommand: `curl -X 'GET' -H 'Accept-Encoding: gzip' -H 'User-Agent: Go-http-client/1.1' 'http://example.com/'`,
 		},
+		{
+			name: "heredoc body",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("hello\nworld"))
+				return req
+			},
+			opts:        []CurlOption{WithHeredocBody()},
+			wantCommand: "{ printf '%s' \"$(cat)\"; } <<'EOF' | curl -X 'POST' --data-binary @- 'http://example.com'\nhello\nworld\nEOF",
+		},
+		{
+			name: "heredoc body containing delimiter",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("EOF\nEOF0"))
+				return req
+			},
+			opts:        []CurlOption{WithHeredocBody()},
+			wantCommand: "{ printf '%s' \"$(cat)\"; } <<'EOF1' | curl -X 'POST' --data-binary @- 'http://example.com'\nEOF\nEOF0\nEOF1",
+		},
+		{
+			name: "curl JSON body",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString(`{"hello":"world"}`))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("X-Auth-Token", "private-token")
+				return req
+			},
+			opts:        []CurlOption{WithCurlJSON()},
+			wantCommand: `curl -X 'POST' --json '{"hello":"world"}' -H 'X-Auth-Token: private-token' 'http://example.com'`,
+		},
+		{
+			name: "curl JSON indented body",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("{\n  \"a\": 1\n}"))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			},
+			opts:        []CurlOption{WithCurlJSON()},
+			wantCommand: "curl -X 'POST' --json '{\n  \"a\": 1\n}' 'http://example.com'",
+		},
+		{
+			name: "curl JSON with non-JSON body",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("age=10"))
+				return req
+			},
+			opts:    []CurlOption{WithCurlJSON()},
+			wantErr: true,
+		},
+		{
+			name: "escaped newlines with heredoc body",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("hello\nworld"))
+				return req
+			},
+			opts:    []CurlOption{WithEscapedNewlines(), WithHeredocBody()},
+			wantErr: true,
+		},
+		{
+			name: "header transformers",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com", nil)
+				req.Header.Set("X-Internal-Route", "pod-1")
+				req.Header.Set("Authorization", "Token secret")
+				req.Header.Set("Accept", "*/*")
+				return req
+			},
+			opts: []CurlOption{
+				WithHeaderTransformer(func(key string, values []string) (string, []string, bool) {
+					return key, values, !strings.HasPrefix(key, "X-Internal-")
+				}),
+				WithHeaderTransformer(func(key string, values []string) (string, []string, bool) {
+					if key == "Authorization" {
+						return "X-Api-Key", []string{strings.TrimPrefix(values[0], "Token ")}, true
+					}
+					return key, values, true
+				}),
+				WithRedactedHeaders("X-Api-Key"),
+			},
+			wantCommand: `curl -X 'GET' -H 'Accept: */*' -H 'X-Api-Key: REDACTED' 'http://example.com'`,
+		},
+		{
+			name: "URL rewriter",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://payments.default.svc.cluster.local:8080/charges?id=1", nil)
+				return req
+			},
+			opts: []CurlOption{
+				WithURLRewriter(func(u *url.URL) *url.URL {
+					u.Scheme = "https"
+					u.Host = strings.Replace(u.Hostname(), ".default.svc.cluster.local", ".example.com", 1)
+					return u
+				}),
+			},
+			wantCommand: `curl -X 'GET' 'https://payments.example.com/charges?id=1'`,
+		},
+		{
+			name: "URL rewriter for server side request",
+			setupReq: func() *http.Request {
+				return httptest.NewRequest("GET", "/charges", nil)
+			},
+			opts: []CurlOption{
+				WithURLRewriter(func(u *url.URL) *url.URL {
+					u.Host = "payments.example.com"
+					return u
+				}),
+			},
+			wantCommand: `curl -X 'GET' 'http://payments.example.com/charges'`,
+		},
+		{
+			name: "server side request headers without Go defaults",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com/", nil)
+				req.Header.Set("Accept-Encoding", "gzip")
+				req.Header.Set("User-Agent", "Go-http-client/1.1")
+				req.Header.Set("Accept", "*/*")
+				return req
+			},
+			opts:        []CurlOption{WithoutDefaultGoHeaders()},
+			wantCommand: `curl -X 'GET' -H 'Accept: */*' 'http://example.com/'`,
+		},
+		{
+			name: "caller set headers kept without Go defaults",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com/", nil)
+				req.Header.Set("Accept-Encoding", "gzip, br")
+				req.Header.Set("User-Agent", "my-client/2.0")
+				return req
+			},
+			opts:        []CurlOption{WithoutDefaultGoHeaders()},
+			wantCommand: `curl -X 'GET' -H 'Accept-Encoding: gzip, br' -H 'User-Agent: my-client/2.0' 'http://example.com/'`,
+		},
+		{
+			name: "include headers",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com/", nil)
+				req.Header.Set("Accept", "*/*")
+				req.Header.Set("Authorization", "Bearer token")
+				req.Header.Set("Baggage", "userId=alice")
+				return req
+			},
+			opts:        []CurlOption{WithIncludeHeaders("accept", "Authorization")},
+			wantCommand: `curl -X 'GET' -H 'Accept: */*' -H 'Authorization: Bearer token' 'http://example.com/'`,
+		},
+		{
+			name: "exclude headers",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com/", nil)
+				req.Header.Set("Accept", "*/*")
+				req.Header.Set("Baggage", "userId=alice")
+				req.Header.Set("Traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+				return req
+			},
+			opts:        []CurlOption{WithExcludeHeaders("baggage", "Traceparent")},
+			wantCommand: `curl -X 'GET' -H 'Accept: */*' 'http://example.com/'`,
+		},
+		{
+			name: "auto compressed flag",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com/", nil)
+				req.Header.Set("Accept-Encoding", "br;q=1.0, gzip;q=0.8")
+				req.Header.Set("Accept", "*/*")
+				return req
+			},
+			opts:        []CurlOption{WithAutoCompressedFlag()},
+			wantCommand: `curl -X 'GET' -H 'Accept: */*' 'http://example.com/' --compressed`,
+		},
+		{
+			name: "auto compressed flag with other encoding",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com/", nil)
+				req.Header.Set("Accept-Encoding", "identity")
+				return req
+			},
+			opts:        []CurlOption{WithAutoCompressedFlag()},
+			wantCommand: `curl -X 'GET' -H 'Accept-Encoding: identity' 'http://example.com/'`,
+		},
+		{
+			name: "stale Content-Length dropped after decompression",
+			setupReq: func() *http.Request {
+				body := compressData([]byte(`{"test":"gzip"}`))
+				req := httptest.NewRequest("POST", "http://example.com", bytes.NewReader(body))
+				req.Header.Set("Content-Encoding", "gzip")
+				req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+				return req
+			},
+			opts:        []CurlOption{WithAutoDecompressGZIP()},
+			wantCommand: `curl -X 'POST' -d '{"test":"gzip"}' 'http://example.com'`,
+		},
+		{
+			name: "recomputed Content-Length after decompression",
+			setupReq: func() *http.Request {
+				body := compressData([]byte(`{"test":"gzip"}`))
+				req := httptest.NewRequest("POST", "http://example.com", bytes.NewReader(body))
+				req.Header.Set("Content-Encoding", "gzip")
+				req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+				return req
+			},
+			opts:        []CurlOption{WithAutoDecompressGZIP(), WithContentLength()},
+			wantCommand: `curl -X 'POST' -d '{"test":"gzip"}' -H 'Content-Length: 15' 'http://example.com'`,
+		},
+		{
+			name: "recomputed Content-Length for rendered newlines",
+			setupReq: func() *http.Request {
+				req := httptest.NewRequest("POST", "http://example.com", bytes.NewBufferString("hello\nworld"))
+				req.Header.Set("Content-Length", "11")
+				return req
+			},
+			opts:        []CurlOption{WithContentLength()},
+			wantCommand: `curl -X 'POST' -d 'hello\nworld' -H 'Content-Length: 12' 'http://example.com'`,
+		},
+		{
+			name: "recomputed Content-Length for heredoc body",
+			setupReq: func() *http.Request {
+				req := httptest.NewRequest("POST", "http://example.com", bytes.NewBufferString("hello\nworld"))
+				return req
+			},
+			opts:        []CurlOption{WithContentLength(), WithHeredocBody()},
+			wantCommand: "{ printf '%s' \"$(cat)\"; } <<'EOF' | curl -X 'POST' --data-binary @- -H 'Content-Length: 11' 'http://example.com'\nhello\nworld\nEOF",
+		},
+		{
+			name: "IPv6 host",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://[2001:db8::1]:8080/status", nil)
+				return req
+			},
+			wantCommand: `curl -g -X 'GET' 'http://[2001:db8::1]:8080/status'`,
+		},
+		{
+			name: "IPv6 link-local host with zone",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://[fe80::1%25en0]:8080/status", nil)
+				return req
+			},
+			wantCommand: `curl -g -X 'GET' 'http://[fe80::1%25en0]:8080/status'`,
+		},
+		{
+			name: "server side IPv6 link-local host with zone",
+			setupReq: func() *http.Request {
+				req := httptest.NewRequest("GET", "/status", nil)
+				req.Host = "[fe80::1%en0]:8080"
+				return req
+			},
+			wantCommand: `curl -g -X 'GET' 'http://[fe80::1%25en0]:8080/status'`,
+		},
+		{
+			name: "unbracketed IPv6 host",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com/status", nil)
+				req.URL.Host = "fe80::1%en0"
+				return req
+			},
+			opts:        []CurlOption{WithBracketedIPv6()},
+			wantCommand: `curl -g -X 'GET' 'http://[fe80::1%25en0]/status'`,
+		},
+		{
+			name: "server side unbracketed IPv6 host",
+			setupReq: func() *http.Request {
+				req := httptest.NewRequest("GET", "/status", nil)
+				req.Host = "2001:db8::1"
+				return req
+			},
+			opts:        []CurlOption{WithBracketedIPv6()},
+			wantCommand: `curl -g -X 'GET' 'http://[2001:db8::1]/status'`,
+		},
+		{
+			name: "punycode host",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "https://user@bücher.example:8443/straße?q=ä", nil)
+				return req
+			},
+			opts:        []CurlOption{WithPunycodeHost()},
+			wantCommand: `curl -X 'GET' 'https://user@xn--bcher-kva.example:8443/stra%C3%9Fe?q=ä'`,
+		},
+		{
+			name: "unicode host",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "https://xn--bcher-kva.example/", nil)
+				return req
+			},
+			opts:        []CurlOption{WithUnicodeHost()},
+			wantCommand: `curl -X 'GET' 'https://bücher.example/'`,
+		},
+		{
+			name: "server side punycode host",
+			setupReq: func() *http.Request {
+				req := httptest.NewRequest("GET", "/", nil)
+				req.Host = "bücher.example"
+				return req
+			},
+			opts:        []CurlOption{WithPunycodeHost()},
+			wantCommand: `curl -X 'GET' 'http://xn--bcher-kva.example/'`,
+		},
+		{
+			name: "punycode and unicode host",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "https://bücher.example/", nil)
+				return req
+			},
+			opts:    []CurlOption{WithPunycodeHost(), WithUnicodeHost()},
+			wantErr: true,
+		},
+		{
+			name: "re-encoded URL",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com/a|b%2fc?sig=abc%2Bdef&x=%7e", nil)
+				return req
+			},
+			wantCommand: `curl -X 'GET' 'http://example.com/a%7Cb/c?sig=abc%2Bdef&x=%7e'`,
+		},
+		{
+			name: "exact URL",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com/a|b%2fc?sig=abc%2Bdef&x=%7e", nil)
+				return req
+			},
+			opts:        []CurlOption{WithExactURL()},
+			wantCommand: `curl -X 'GET' 'http://example.com/a|b%2fc?sig=abc%2Bdef&x=%7e'`,
+		},
+		{
+			name: "exact URL with dot segments",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com/static/../admin/./index", nil)
+				return req
+			},
+			opts:        []CurlOption{WithExactURL()},
+			wantCommand: `curl --path-as-is -X 'GET' 'http://example.com/static/../admin/./index'`,
+		},
+		{
+			name: "server side exact URL",
+			setupReq: func() *http.Request {
+				return httptest.NewRequest("GET", "/files/a%2Fb?sig=abc%2Bdef", nil)
+			},
+			opts:        []CurlOption{WithExactURL()},
+			wantCommand: `curl -X 'GET' 'http://example.com/files/a%2Fb?sig=abc%2Bdef'`,
+		},
+		{
+			name: "server side request with query",
+			setupReq: func() *http.Request {
+				return httptest.NewRequest("GET", "/search?q=go+curl&page=2", nil)
+			},
+			wantCommand: `curl -X 'GET' 'http://example.com/search?q=go+curl&page=2'`,
+		},
+		{
+			name: "server side request with fragment",
+			setupReq: func() *http.Request {
+				req := httptest.NewRequest("GET", "/search?q=go", nil)
+				req.URL.Fragment = "results"
+				return req
+			},
+			wantCommand: `curl -X 'GET' 'http://example.com/search?q=go'`,
+		},
+		{
+			name: "server side request with included fragment",
+			setupReq: func() *http.Request {
+				req := httptest.NewRequest("GET", "/search?q=go", nil)
+				req.URL.Fragment = "results"
+				return req
+			},
+			opts:        []CurlOption{WithFragment()},
+			wantCommand: `curl -X 'GET' 'http://example.com/search?q=go#results'`,
+		},
+		{
+			name: "WebDAV PROPFIND with Depth",
+			setupReq: func() *http.Request {
+				body := `<?xml version="1.0"?><propfind xmlns="DAV:"><allprop/></propfind>`
+				req, _ := http.NewRequest("PROPFIND", "http://dav.example.com/docs/", bytes.NewBufferString(body))
+				req.Header.Set("Depth", "1")
+				req.Header.Set("Content-Type", "application/xml")
+				return req
+			},
+			wantCommand: `curl -X 'PROPFIND' -d '<?xml version="1.0"?><propfind xmlns="DAV:"><allprop/></propfind>' -H 'Content-Type: application/xml' -H 'Depth: 1' 'http://dav.example.com/docs/'`,
+		},
+		{
+			name: "WebDAV MKCOL",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("MKCOL", "http://dav.example.com/docs/new/", nil)
+				return req
+			},
+			wantCommand: `curl -X 'MKCOL' 'http://dav.example.com/docs/new/'`,
+		},
+		{
+			name: "WebDAV MOVE with Destination",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("MOVE", "http://dav.example.com/docs/a.txt", nil)
+				req.Header.Set("Destination", "http://dav.example.com/docs/b.txt")
+				req.Header.Set("Overwrite", "F")
+				return req
+			},
+			wantCommand: `curl -X 'MOVE' -H 'Destination: http://dav.example.com/docs/b.txt' -H 'Overwrite: F' 'http://dav.example.com/docs/a.txt'`,
+		},
+		{
+			name: "REPORT with long request flag",
+			setupReq: func() *http.Request {
+				body := `<C:calendar-query xmlns:C="urn:ietf:params:xml:ns:caldav"/>`
+				req, _ := http.NewRequest("REPORT", "http://dav.example.com/cal/", bytes.NewBufferString(body))
+				req.Header.Set("Depth", "1")
+				return req
+			},
+			opts:        []CurlOption{WithLongRequestFlag()},
+			wantCommand: `curl --request 'REPORT' -d '<C:calendar-query xmlns:C="urn:ietf:params:xml:ns:caldav"/>' -H 'Depth: 1' 'http://dav.example.com/cal/'`,
+		},
+		{
+			name: "PATCH request",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPatch, "http://example.com/users/1", bytes.NewBufferString(`[{"op":"replace","path":"/name","value":"o'neill"}]`))
+				req.Header.Set("Content-Type", "application/json-patch+json")
+				return req
+			},
+			wantCommand: `curl -X 'PATCH' -d '[{"op":"replace","path":"/name","value":"o'\''neill"}]' -H 'Content-Type: application/json-patch+json' 'http://example.com/users/1'`,
+		},
+		{
+			name: "trailers",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("PUT", "http://example.com/upload", bytes.NewBufferString("data"))
+				req.Trailer = http.Header{
+					"X-Checksum":  {"sha256=3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7"},
+					"X-Signature": nil,
+				}
+				return req
+			},
+			wantCommand: "# trailer not sent by curl: X-Checksum: sha256=3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7\n" +
+				"# trailer not sent by curl: X-Signature: (set after the body is sent)\n" +
+				"curl -X 'PUT' -d 'data' -H 'Trailer: X-Checksum, X-Signature' -H 'Transfer-Encoding: chunked' 'http://example.com/upload'",
+		},
+		{
+			name: "redacted trailers",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("PUT", "http://example.com/upload", bytes.NewBufferString("data"))
+				req.Header.Set("Trailer", "X-Signature")
+				req.Trailer = http.Header{"X-Signature": {"secret"}}
+				return req
+			},
+			opts: []CurlOption{WithRedactedHeaders("X-Signature")},
+			wantCommand: "# trailer not sent by curl: X-Signature: REDACTED\n" +
+				"curl -X 'PUT' -d 'data' -H 'Trailer: X-Signature' -H 'Transfer-Encoding: chunked' 'http://example.com/upload'",
+		},
+		{
+			name: "multiple cookie values",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com", nil)
+				req.Header["Cookie"] = []string{"session=abc", "theme=dark mode"}
+				return req
+			},
+			wantCommand: `curl -X 'GET' -H 'Cookie: session=abc; theme=dark mode' 'http://example.com'`,
+		},
+		{
+			name: "multiple values keep one line each",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com", nil)
+				req.Header["Set-Cookie"] = []string{"a=1; Expires=Wed, 21 Oct 2015 07:28:00 GMT", "b=2; Path=/"}
+				req.Header["Signature"] = []string{`sig1=:abc=:`, `sig2=:d, e=:`}
+				return req
+			},
+			wantCommand: `curl -X 'GET' -H 'Set-Cookie: a=1; Expires=Wed, 21 Oct 2015 07:28:00 GMT' -H 'Set-Cookie: b=2; Path=/' ` +
+				`-H 'Signature: sig1=:abc=:' -H 'Signature: sig2=:d, e=:' 'http://example.com'`,
+		},
+		{
+			name: "multiple values redacted once",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com", nil)
+				req.Header["Cookie"] = []string{"session=abc", "theme=dark"}
+				return req
+			},
+			opts:        []CurlOption{WithRedactedHeaders("Cookie")},
+			wantCommand: `curl -X 'GET' -H 'Cookie: REDACTED' 'http://example.com'`,
+		},
+		{
+			name: "multiple trailer values",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("PUT", "http://example.com/upload", bytes.NewBufferString("data"))
+				req.Trailer = http.Header{"Set-Cookie": {"a=1, b", "c=2"}}
+				return req
+			},
+			wantCommand: "# trailer not sent by curl: Set-Cookie: a=1, b\n" +
+				"# trailer not sent by curl: Set-Cookie: c=2\n" +
+				"curl -X 'PUT' -d 'data' -H 'Trailer: Set-Cookie' -H 'Transfer-Encoding: chunked' 'http://example.com/upload'",
+		},
+		{
+			name: "multiple values space joined in compat mode",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com", nil)
+				req.Header["Cookie"] = []string{"session=abc", "theme=dark"}
+				return req
+			},
+			opts:        []CurlOption{WithCompatVersion(CompatV1)},
+			wantCommand: `curl -X 'GET' -H 'Cookie: session=abc theme=dark' 'http://example.com'`,
+		},
+		{
+			name: "trace",
+			setupReq: func() *http.Request {
+				return httptest.NewRequest("GET", "http://example.com", nil)
+			},
+			opts:        []CurlOption{WithTrace("", false), WithCompression()},
+			wantCommand: `curl -X 'GET' 'http://example.com' --compressed --trace 'curl-trace.txt' --trace-time`,
+		},
+		{
+			name: "timeouts",
+			setupReq: func() *http.Request {
+				return httptest.NewRequest("GET", "https://example.com", nil)
+			},
+			opts:        []CurlOption{WithMaxTime(2500 * time.Millisecond), WithConnectTimeout(250 * time.Millisecond), WithInsecureSkipVerify()},
+			wantCommand: `curl -k --connect-timeout 0.25 --max-time 2.5 -X 'GET' 'https://example.com'`,
+		},
+		{
+			name: "whole second timeout",
+			setupReq: func() *http.Request {
+				return httptest.NewRequest("GET", "http://example.com", nil)
+			},
+			opts:        []CurlOption{WithMaxTime(10 * time.Second)},
+			wantCommand: `curl --max-time 10 -X 'GET' 'http://example.com'`,
+		},
+		{
+			name: "ASCII trace",
+			setupReq: func() *http.Request {
+				return httptest.NewRequest("GET", "http://example.com", nil)
+			},
+			opts:        []CurlOption{WithTrace("/tmp/dump it.txt", true)},
+			wantCommand: `curl -X 'GET' 'http://example.com' --trace-ascii '/tmp/dump it.txt' --trace-time`,
+		},
+		{
+			name: "empty header value",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com", nil)
+				req.Header["X-Empty"] = []string{""}
+				req.Header.Set("X-Blank", "  ")
+				return req
+			},
+			wantCommand: `curl -X 'GET' -H 'X-Blank;' -H 'X-Empty;' 'http://example.com'`,
+		},
+		{
+			name: "empty header value in compat mode",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com", nil)
+				req.Header["X-Empty"] = []string{""}
+				return req
+			},
+			opts:        []CurlOption{WithCompatVersion(CompatV1)},
+			wantCommand: `curl -X 'GET' -H 'X-Empty: ' 'http://example.com'`,
+		},
+		{
+			name: "suppressed curl default headers",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "http://example.com", nil)
+				req.Header.Set("User-Agent", "cats/1.0")
+				return req
+			},
+			opts:        []CurlOption{WithSuppressedHeaders("Accept", "User-Agent")},
+			wantCommand: `curl -X 'GET' -H 'User-Agent: cats/1.0' -H 'Accept:' 'http://example.com'`,
+		},
+		{
+			name: "double quote escaping",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com/cats?name=o'neill", bytes.NewBufferString(`{"cost":"$5","path":"C:\\cats","cmd":"`+"`id`"+`"}`))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			},
+			opts:        []CurlOption{WithDoubleQuoteEscaping()},
+			wantCommand: `curl -X "POST" -d "{\"cost\":\"\$5\",\"path\":\"C:\\\\cats\",\"cmd\":\"\` + "`id\\`" + `\"}" -H "Content-Type: application/json" "http://example.com/cats?name=o'neill"`,
+		},
+		{
+			name: "double quote escaping with escaped newlines",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("line one\nline \"two\""))
+				return req
+			},
+			opts:        []CurlOption{WithDoubleQuoteEscaping(), WithEscapedNewlines()},
+			wantCommand: `printf "line one\\nline \"two\"" | curl -X "POST" --data-binary @- "http://example.com"`,
+		},
+		{
+			name: "chunked body",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", io.MultiReader(strings.NewReader("line one\nit's two")))
+				return req
+			},
+			opts:        []CurlOption{WithChunkedBody()},
+			wantCommand: `printf '%s' 'line one` + "\n" + `it'\''s two' | curl -X 'POST' --data-binary @- -H 'Transfer-Encoding: chunked' 'http://example.com'`,
+		},
+		{
+			name: "chunked server side body",
+			setupReq: func() *http.Request {
+				req := httptest.NewRequest("PUT", "http://example.com/cats", strings.NewReader("meow"))
+				req.ContentLength = -1
+				req.TransferEncoding = []string{"chunked"}
+				return req
+			},
+			opts:        []CurlOption{WithChunkedBody()},
+			wantCommand: `printf '%s' 'meow' | curl -X 'PUT' --data-binary @- -H 'Transfer-Encoding: chunked' 'http://example.com/cats'`,
+		},
+		{
+			name: "form fields",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com/cats", strings.NewReader("name=Tom&owner=O%27Neill&&note="))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+				return req
+			},
+			opts: []CurlOption{WithFormFields()},
+			wantCommand: `curl -X 'POST' -d 'name=Tom' -d 'owner=O%27Neill' -d '' -d 'note=' ` +
+				`-H 'Content-Type: application/x-www-form-urlencoded; charset=utf-8' 'http://example.com/cats'`,
+		},
+		{
+			name: "form fields option with JSON body",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com/cats", strings.NewReader(`{"a":"b&c"}`))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			},
+			opts:        []CurlOption{WithFormFields()},
+			wantCommand: `curl -X 'POST' -d '{"a":"b&c"}' -H 'Content-Type: application/json' 'http://example.com/cats'`,
+		},
+		{
+			name: "chunked body option with known length",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", strings.NewReader("meow"))
+				return req
+			},
+			opts:        []CurlOption{WithChunkedBody()},
+			wantCommand: `curl -X 'POST' -d 'meow' 'http://example.com'`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -180,6 +845,173 @@ func TestGetCurlCommand(t *testing.T) {
 	}
 }
 
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestGetCurlCommandErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		setupReq func() *http.Request
+		opts     []CurlOption
+		wantErr  error
+	}{
+		{
+			name: "conflicting options",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("data"))
+				return req
+			},
+			opts:    []CurlOption{WithEscapedNewlines(), WithHeredocBody()},
+			wantErr: ErrConflictingOptions,
+		},
+		{
+			name: "body read failure",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", errReader{})
+				return req
+			},
+			wantErr: ErrBodyRead,
+		},
+		{
+			name: "invalid GZIP data",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewReader([]byte{0x1, 0x2}))
+				req.Header.Set("Content-Encoding", "gzip")
+				return req
+			},
+			opts:    []CurlOption{WithAutoDecompressGZIP()},
+			wantErr: ErrGzipDecompress,
+		},
+		{
+			name: "truncated GZIP data",
+			setupReq: func() *http.Request {
+				body := compressData([]byte(`{"test":"gzip"}`))
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewReader(body[:len(body)-4]))
+				req.Header.Set("Content-Encoding", "gzip")
+				return req
+			},
+			opts:    []CurlOption{WithAutoDecompressGZIP()},
+			wantErr: ErrGzipDecompress,
+		},
+		{
+			name: "body too large",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("0123456789"))
+				return req
+			},
+			opts:    []CurlOption{WithMaxBodySize(5)},
+			wantErr: ErrBodyTooLarge,
+		},
+		{
+			name: "decompression bomb",
+			setupReq: func() *http.Request {
+				body := compressData(bytes.Repeat([]byte("0"), 1<<20))
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewReader(body))
+				req.Header.Set("Content-Encoding", "gzip")
+				return req
+			},
+			opts:    []CurlOption{WithAutoDecompressGZIP(), WithMaxDecompressedSize(1024)},
+			wantErr: ErrBodyTooLarge,
+		},
+		{
+			name: "unsupported encoding",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("data"))
+				req.Header.Set("Content-Encoding", "br")
+				return req
+			},
+			opts:    []CurlOption{WithAutoDecompressGZIP(), WithStrict()},
+			wantErr: ErrUnsupportedEncoding,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetCurlCommand(tt.setupReq(), tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetCurlCommand() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAutoDecompressEncodings(t *testing.T) {
+	tests := []struct {
+		name         string
+		encoding     string
+		body         []byte
+		wantCommand  string
+		wantWarnings []LossWarning
+	}{
+		{
+			name:        "x-gzip",
+			encoding:    "x-gzip",
+			body:        compressData([]byte("meow")),
+			wantCommand: `curl -X 'POST' -d 'meow' 'http://example.com'`,
+			wantWarnings: []LossWarning{
+				{Kind: LossBodyDecompressed, Name: "gzip", Detail: "gzip body sent decompressed"},
+			},
+		},
+		{
+			name:        "mixed case",
+			encoding:    "GZip",
+			body:        compressData([]byte("meow")),
+			wantCommand: `curl -X 'POST' -d 'meow' 'http://example.com'`,
+			wantWarnings: []LossWarning{
+				{Kind: LossBodyDecompressed, Name: "gzip", Detail: "gzip body sent decompressed"},
+			},
+		},
+		{
+			name:        "brotli",
+			encoding:    "br",
+			body:        []byte("data"),
+			wantCommand: `curl -X 'POST' -d 'data' -H 'Content-Encoding: br' 'http://example.com'`,
+			wantWarnings: []LossWarning{
+				{Kind: LossContentEncoding, Name: "br", Detail: "br body sent without decompression"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "http://example.com", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Encoding", tt.encoding)
+			command, err := GetCurlCommand(req, WithAutoDecompressGZIP())
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.wantCommand)
+			}
+			if got := command.Warnings(); !reflect.DeepEqual(got, tt.wantWarnings) {
+				t.Errorf("Warnings() = %+v, want %+v", got, tt.wantWarnings)
+			}
+		})
+	}
+
+	req, _ := http.NewRequest("POST", "http://example.com", strings.NewReader("data"))
+	req.Header.Set("Content-Encoding", "br")
+	_, err := GetCurlCommand(req, WithAutoDecompressGZIP(), WithStrict())
+	if !errors.Is(err, ErrUnsupportedEncoding) || !errors.Is(err, ErrLossyConversion) {
+		t.Errorf("GetCurlCommand() with WithStrict error = %v, want %v and %v", err, ErrUnsupportedEncoding, ErrLossyConversion)
+	}
+}
+
+func TestMaxBodySizeKeepsBody(t *testing.T) {
+	req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("0123456789"))
+	if _, err := GetCurlCommand(req, WithMaxBodySize(5)); err == nil {
+		t.Fatalf("GetCurlCommand() error = nil, want %v", ErrBodyTooLarge)
+	}
+
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != "0123456789" {
+		t.Errorf("request body = %q, want %q", body, "0123456789")
+	}
+}
+
 func TestConcurrentCommandGeneration(t *testing.T) {
 	var wg sync.WaitGroup
 	for i := 0; i < 100; i++ {
@@ -200,3 +1032,55 @@ func compressData(data []byte) []byte {
 	gz.Close()
 	return buf.Bytes()
 }
+
+// sentBody runs command with a curl stand-in printing its standard input,
+// returning the body curl would send with --data-binary @-
+func sentBody(t *testing.T, shell, command string) string {
+	t.Helper()
+	path, err := exec.LookPath(shell)
+	if err != nil {
+		t.Skipf("%s not found", shell)
+	}
+	out, err := exec.Command(path, "-c", "curl() { cat; }\n"+command).Output()
+	if err != nil {
+		t.Fatalf("%s -c %q error = %v", shell, command, err)
+	}
+	return string(out)
+}
+
+func TestCurlJSONBodyBytes(t *testing.T) {
+	body := "{\n  \"a\": [1, 2],\n  \"b\": \"it's\"\n}\n"
+	req, _ := http.NewRequest(http.MethodPost, "http://example.com", strings.NewReader(body))
+	command, err := GetCurlCommand(req, WithCurlJSON())
+	if err != nil {
+		t.Fatalf("GetCurlCommand() error = %v", err)
+	}
+	if len(command.Warnings()) != 0 {
+		t.Errorf("Warnings() = %+v, want none", command.Warnings())
+	}
+	// Print the --json argument instead of stdin
+	printJSON := `curl() { while [ "$1" != --json ]; do shift; done; printf '%s' "$2"; }` + "\n"
+	for _, shell := range []string{"bash", "sh"} {
+		if got := sentBody(t, shell, printJSON+command.String()); got != body {
+			t.Errorf("%s sends %q for body %q, rendered as:\n%s", shell, got, body, command)
+		}
+	}
+}
+
+func TestHeredocBodyBytes(t *testing.T) {
+	for _, body := range []string{"abc", "abc\n", "a\n\nb\n\n", "EOF\nx", "{\n  \"a\": 1\n}"} {
+		for _, shell := range []string{"bash", "sh"} {
+			req, _ := http.NewRequest(http.MethodPost, "http://example.com", strings.NewReader(body))
+			command, err := GetCurlCommand(req, WithHeredocBody(), WithContentLength())
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := sentBody(t, shell, command.String()); got != body {
+				t.Errorf("%s sends %q for body %q, rendered as:\n%s", shell, got, body, command)
+			}
+			if want := "Content-Length: " + strconv.Itoa(len(body)); !strings.Contains(command.String(), want) {
+				t.Errorf("command %s lacks %s", command, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Package http2curltest provides utilities for tests involving curl commands
+// generated by http2curl.
+package http2curltest
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chodges15/http2curl/v3"
+)
+
+// Recorder holds the curl commands of requests captured by CaptureHandler
+type Recorder struct {
+	mu       sync.Mutex
+	commands []*http2curl.CurlCommand
+	errs     []error
+}
+
+// CaptureHandler returns a handler recording every request as a curl command
+// before passing it to next, and the Recorder holding the commands
+func CaptureHandler(next http.Handler, opts ...http2curl.CurlOption) (*Recorder, http.Handler) {
+	r := &Recorder{}
+	converter := http2curl.NewConverter(opts...)
+	return r, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		command, err := converter.Convert(req)
+		r.mu.Lock()
+		if err != nil {
+			r.errs = append(r.errs, err)
+		} else {
+			r.commands = append(r.commands, command)
+		}
+		r.mu.Unlock()
+		next.ServeHTTP(w, req)
+	})
+}
+
+// Commands returns the commands recorded so far, in arrival order
+func (r *Recorder) Commands() []*http2curl.CurlCommand {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*http2curl.CurlCommand(nil), r.commands...)
+}
+
+// Errors returns the errors met while generating commands
+func (r *Recorder) Errors() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]error(nil), r.errs...)
+}
+
+// Reset discards the recorded commands and errors
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands = nil
+	r.errs = nil
+}
+
+// String returns the recorded commands, one per line
+func (r *Recorder) String() string {
+	var b strings.Builder
+	for _, command := range r.Commands() {
+		b.WriteString(command.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// DumpOnFailure logs the recorded commands when t has failed by the end of
+// the test
+func (r *Recorder) DumpOnFailure(t testing.TB) {
+	t.Helper()
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("requests received:\n%s", r)
+		}
+	})
+}
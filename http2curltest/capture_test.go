@@ -0,0 +1,42 @@
+package http2curltest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chodges15/http2curl/v3"
+)
+
+func TestCaptureHandler(t *testing.T) {
+	rec, handler := CaptureHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), http2curl.WithoutDefaultGoHeaders())
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	rec.DumpOnFailure(t)
+
+	req, _ := http.NewRequest("POST", server.URL+"/cats", bytes.NewBufferString("meow"))
+	req.Header.Set("X-Auth-Token", "private-token")
+	if _, err := http.DefaultClient.Do(req); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if _, err := http.Get(server.URL + "/dogs?name=rex"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	want := `curl -X 'POST' -d 'meow' -H 'X-Auth-Token: private-token' '` + server.URL + `/cats'` + "\n" +
+		`curl -X 'GET' '` + server.URL + `/dogs?name=rex'` + "\n"
+	if got := rec.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+	if len(rec.Errors()) != 0 {
+		t.Errorf("Errors() = %v, want none", rec.Errors())
+	}
+
+	rec.Reset()
+	if len(rec.Commands()) != 0 {
+		t.Errorf("Commands() after Reset() = %v, want none", rec.Commands())
+	}
+}
@@ -0,0 +1,126 @@
+package http2curltest
+
+import (
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/chodges15/http2curl/v3"
+)
+
+// UpdateEnv is the environment variable which, set to 1, makes AssertCommand
+// write golden files instead of comparing them
+const UpdateEnv = "HTTP2CURL_UPDATE"
+
+// updating reports whether golden files are written: with UpdateEnv set to
+// 1, or with an -update flag the test package defines set to true. The flag
+// is not defined here, which would clash with the test package's own.
+func updating() bool {
+	if os.Getenv(UpdateEnv) == "1" {
+		return true
+	}
+	f := flag.Lookup("update")
+	if f == nil {
+		return false
+	}
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return false
+	}
+	value, _ := getter.Get().(bool)
+	return value
+}
+
+// Scrubber normalizes volatile parts of a rendered command before it is
+// compared with a golden file
+type Scrubber func(command string) string
+
+// ScrubRegexp returns a Scrubber replacing matches of re with repl, which may
+// reference submatches as in regexp.Regexp.ReplaceAllString
+func ScrubRegexp(re *regexp.Regexp, repl string) Scrubber {
+	return func(command string) string {
+		return re.ReplaceAllString(command, repl)
+	}
+}
+
+// ScrubHeader returns a Scrubber replacing the value of the header key
+func ScrubHeader(key string) Scrubber {
+	re := regexp.MustCompile(`(?i)(-H '` + regexp.QuoteMeta(key) + `: )[^']*`)
+	return ScrubRegexp(re, "${1}<"+strings.ToLower(key)+">")
+}
+
+var boundaryPattern = regexp.MustCompile(`boundary=("?)([^"';\s]+)`)
+
+// ScrubBoundary replaces multipart boundaries, wherever they appear in the
+// command, with a fixed value
+func ScrubBoundary(command string) string {
+	for _, m := range boundaryPattern.FindAllStringSubmatch(command, -1) {
+		command = strings.ReplaceAll(command, m[2], "<boundary>")
+	}
+	return command
+}
+
+// DefaultScrubbers are the scrubbers used by AssertCommand: volatile headers,
+// multipart boundaries, RFC 3339 timestamps and UUIDs
+var DefaultScrubbers = defaultScrubbers()
+
+func defaultScrubbers() []Scrubber {
+	scrubbers := make([]Scrubber, 0, len(http2curl.VolatileHeaders)+3)
+	for _, key := range http2curl.VolatileHeaders {
+		scrubbers = append(scrubbers, ScrubHeader(key))
+	}
+	return append(scrubbers,
+		ScrubBoundary,
+		ScrubRegexp(regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`), "<timestamp>"),
+		ScrubRegexp(regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`), "<uuid>"),
+	)
+}
+
+// AssertCommand compares the curl command generated for req with the golden
+// file at goldenPath after applying DefaultScrubbers. Run the tests with
+// HTTP2CURL_UPDATE=1, or with -update if the test package defines that flag,
+// to write the golden file instead.
+func AssertCommand(t testing.TB, req *http.Request, goldenPath string, opts ...http2curl.CurlOption) {
+	t.Helper()
+	AssertCommandScrubbed(t, req, goldenPath, DefaultScrubbers, opts...)
+}
+
+// AssertCommandScrubbed is like AssertCommand but normalizes the command with
+// the given scrubbers
+func AssertCommandScrubbed(t testing.TB, req *http.Request, goldenPath string, scrubbers []Scrubber, opts ...http2curl.CurlOption) {
+	t.Helper()
+	command, err := http2curl.GetCurlCommand(req, opts...)
+	if err != nil {
+		t.Fatalf("GetCurlCommand() error = %v", err)
+		return
+	}
+	got := command.String()
+	for _, scrub := range scrubbers {
+		got = scrub(got)
+	}
+	got += "\n"
+
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+			t.Fatalf("creating golden file directory: %v", err)
+			return
+		}
+		if err := os.WriteFile(goldenPath, []byte(got), 0o644); err != nil {
+			t.Fatalf("writing golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("reading golden file: %v (run with HTTP2CURL_UPDATE=1 to create it)", err)
+		return
+	}
+	if got != string(want) {
+		t.Errorf("command does not match %s:\nGot:\n%s\nWant:\n%s", goldenPath, got, want)
+	}
+}
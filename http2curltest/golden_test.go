@@ -0,0 +1,109 @@
+package http2curltest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// updateFlag is defined like in test packages using AssertCommand, which
+// must not clash with the package
+var updateFlag = flag.Bool("update", false, "update golden files")
+
+// recordingTB captures failures instead of failing the test
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+func newMultipartRequest(boundary, requestID string) *http.Request {
+	body := "--" + boundary + "\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\n1\r\n--" + boundary + "--\r\n"
+	req, _ := http.NewRequest("POST", "http://example.com/upload", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+	req.Header.Set("X-Request-Id", requestID)
+	req.Header.Set("Date", "Mon, 02 Jan 2006 15:04:05 GMT")
+	return req
+}
+
+func TestAssertCommand(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "testdata", "upload.golden")
+
+	t.Setenv(UpdateEnv, "1")
+	AssertCommand(t, newMultipartRequest("a1b2c3", "req-1"), golden)
+	os.Unsetenv(UpdateEnv)
+
+	data, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	want := "curl -X 'POST' -d '--<boundary>\r\\nContent-Disposition: form-data; name=\"a\"\r\\n\r\\n1\r\\n--<boundary>--\r\\n' " +
+		`-H 'Content-Type: multipart/form-data; boundary=<boundary>' -H 'Date: <date>' -H 'X-Request-Id: <x-request-id>' 'http://example.com/upload'` + "\n"
+	if string(data) != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", data, want)
+	}
+
+	tests := []struct {
+		name        string
+		req         *http.Request
+		golden      string
+		wantFailure bool
+	}{
+		{
+			name:   "volatile parts differ",
+			req:    newMultipartRequest("zz99", "req-2"),
+			golden: golden,
+		},
+		{
+			name: "request differs",
+			req: func() *http.Request {
+				req := newMultipartRequest("a1b2c3", "req-1")
+				req.Header.Set("X-Extra", "1")
+				return req
+			}(),
+			golden:      golden,
+			wantFailure: true,
+		},
+		{
+			name:        "missing golden file",
+			req:         newMultipartRequest("a1b2c3", "req-1"),
+			golden:      filepath.Join(t.TempDir(), "missing.golden"),
+			wantFailure: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &recordingTB{TB: t}
+			AssertCommand(tb, tt.req, tt.golden)
+			if (len(tb.failures) > 0) != tt.wantFailure {
+				t.Errorf("failures = %q, wantFailure %v", tb.failures, tt.wantFailure)
+			}
+		})
+	}
+}
+
+func TestAssertCommandUpdateFlag(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "upload.golden")
+
+	*updateFlag = true
+	AssertCommand(t, newMultipartRequest("a1b2c3", "req-1"), golden)
+	*updateFlag = false
+
+	if _, err := os.Stat(golden); err != nil {
+		t.Errorf("golden file not written with -update: %v", err)
+	}
+}
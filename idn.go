@@ -0,0 +1,213 @@
+package http2curl
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Punycode parameters from RFC 3492
+const (
+	punyBase        = 36
+	punyTMin        = 1
+	punyTMax        = 26
+	punySkew        = 38
+	punyDamp        = 700
+	punyInitialBias = 72
+	punyInitialN    = 128
+	acePrefix       = "xn--"
+)
+
+var errInvalidPunycode = errors.New("invalid punycode")
+
+// hostToASCII converts the labels of an internationalized host name to
+// their ACE form, e.g. bücher.example to xn--bcher-kva.example
+func hostToASCII(host string) string {
+	return mapHostLabels(host, func(label string) string {
+		if isASCII(label) {
+			return label
+		}
+		return acePrefix + punyEncode([]rune(strings.ToLower(label)))
+	})
+}
+
+// hostToUnicode converts ACE labels of a host name back to Unicode
+func hostToUnicode(host string) string {
+	return mapHostLabels(host, func(label string) string {
+		if len(label) < len(acePrefix) || !strings.EqualFold(label[:len(acePrefix)], acePrefix) {
+			return label
+		}
+		decoded, err := punyDecode(label[len(acePrefix):])
+		if err != nil {
+			return label
+		}
+		return string(decoded)
+	})
+}
+
+// mapHostLabels applies mapping to each dot-separated label of host, leaving
+// any port and IP literals untouched
+func mapHostLabels(host string, mapping func(string) string) string {
+	if strings.HasPrefix(host, "[") {
+		return host
+	}
+	name, port := host, ""
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && strings.Count(host, ":") == 1 {
+		name, port = host[:i], host[i:]
+	}
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		labels[i] = mapping(label)
+	}
+	return strings.Join(labels, ".") + port
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// punyEncode implements the Punycode encoding of RFC 3492
+func punyEncode(input []rune) string {
+	var output strings.Builder
+	for _, r := range input {
+		if r < utf8.RuneSelf {
+			output.WriteRune(r)
+		}
+	}
+	basic := output.Len()
+	if basic > 0 {
+		output.WriteByte('-')
+	}
+
+	n, delta, bias := rune(punyInitialN), 0, punyInitialBias
+	for handled := basic; handled < len(input); {
+		m := rune(unicode.MaxRune)
+		for _, r := range input {
+			if r >= n && r < m {
+				m = r
+			}
+		}
+		delta += int(m-n) * (handled + 1)
+		n = m
+		for _, r := range input {
+			if r < n {
+				delta++
+			}
+			if r != n {
+				continue
+			}
+			q := delta
+			for k := punyBase; ; k += punyBase {
+				t := punyThreshold(k, bias)
+				if q < t {
+					break
+				}
+				output.WriteByte(punyDigit(t + (q-t)%(punyBase-t)))
+				q = (q - t) / (punyBase - t)
+			}
+			output.WriteByte(punyDigit(q))
+			bias = punyAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+		delta++
+		n++
+	}
+	return output.String()
+}
+
+// punyDecode implements the Punycode decoding of RFC 3492
+func punyDecode(input string) ([]rune, error) {
+	var output []rune
+	pos := 0
+	if b := strings.LastIndexByte(input, '-'); b >= 0 {
+		output = []rune(input[:b])
+		pos = b + 1
+	}
+
+	n, i, bias := rune(punyInitialN), 0, punyInitialBias
+	for pos < len(input) {
+		oldi, w := i, 1
+		for k := punyBase; ; k += punyBase {
+			if pos >= len(input) {
+				return nil, errInvalidPunycode
+			}
+			digit, ok := punyDigitValue(input[pos])
+			pos++
+			if !ok {
+				return nil, errInvalidPunycode
+			}
+			i += digit * w
+			t := punyThreshold(k, bias)
+			if digit < t {
+				break
+			}
+			w *= punyBase - t
+			if i > unicode.MaxRune || w > unicode.MaxRune {
+				return nil, errInvalidPunycode
+			}
+		}
+		bias = punyAdapt(i-oldi, len(output)+1, oldi == 0)
+		n += rune(i / (len(output) + 1))
+		if n > unicode.MaxRune {
+			return nil, errInvalidPunycode
+		}
+		i %= len(output) + 1
+		output = append(output, 0)
+		copy(output[i+1:], output[i:])
+		output[i] = n
+		i++
+	}
+	return output, nil
+}
+
+func punyThreshold(k, bias int) int {
+	switch {
+	case k <= bias:
+		return punyTMin
+	case k >= bias+punyTMax:
+		return punyTMax
+	default:
+		return k - bias
+	}
+}
+
+func punyAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punyBase-punyTMin)*punyTMax)/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
+
+func punyDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+func punyDigitValue(c byte) (int, bool) {
+	switch {
+	case 'a' <= c && c <= 'z':
+		return int(c - 'a'), true
+	case 'A' <= c && c <= 'Z':
+		return int(c - 'A'), true
+	case '0' <= c && c <= '9':
+		return int(c-'0') + 26, true
+	}
+	return 0, false
+}
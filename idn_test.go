@@ -0,0 +1,35 @@
+package http2curl
+
+import "testing"
+
+func TestPunycode(t *testing.T) {
+	tests := []struct {
+		unicode string
+		ascii   string
+	}{
+		{unicode: "bücher.example", ascii: "xn--bcher-kva.example"},
+		{unicode: "münchen.de:8080", ascii: "xn--mnchen-3ya.de:8080"},
+		{unicode: "例え.テスト", ascii: "xn--r8jz45g.xn--zckzah"},
+		{unicode: "example.com", ascii: "example.com"},
+		{unicode: "[fe80::1%en0]:8080", ascii: "[fe80::1%en0]:8080"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.unicode, func(t *testing.T) {
+			if got := hostToASCII(tt.unicode); got != tt.ascii {
+				t.Errorf("hostToASCII(%q) = %q, want %q", tt.unicode, got, tt.ascii)
+			}
+			if got := hostToUnicode(tt.ascii); got != tt.unicode {
+				t.Errorf("hostToUnicode(%q) = %q, want %q", tt.ascii, got, tt.unicode)
+			}
+		})
+	}
+}
+
+func TestPunycodeInvalid(t *testing.T) {
+	for _, host := range []string{"xn--bcher-kv!.example", "xn--99999999999.example"} {
+		if got := hostToUnicode(host); got != host {
+			t.Errorf("hostToUnicode(%q) = %q, want unchanged", host, got)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package http2curl
+
+import "strings"
+
+// LengthFallback describes how a command was shortened to fit the length
+// set with WithMaxCommandLength
+type LengthFallback int
+
+const (
+	// NoFallback means the command was rendered in full
+	NoFallback LengthFallback = iota
+	// BodyFileFallback means curl reads the body from BodyFile, which the
+	// caller writes with the contents of Body.Data
+	BodyFileFallback
+	// ConfigFileFallback means curl reads all options from ConfigFile, which
+	// the caller writes with the contents of ConfigFileContents
+	ConfigFileFallback
+)
+
+// Default file names used by WithMaxCommandLength
+const (
+	DefaultBodyFile   = "body.bin"
+	DefaultConfigFile = "curl.config"
+)
+
+// booleanFlags lists curl flags that take no parameter
+var booleanFlags = map[string]bool{
+	"-k": true, "-g": true, "-p": true,
+	"--compressed": true, "--path-as-is": true, "--trace-time": true,
+	"--http2": true, "--http2-prior-knowledge": true, "-fsS": true,
+	"--fail-with-body": true, "-L": true,
+}
+
+// longFlags maps short curl flags to their long names
+var longFlags = map[string]string{
+	"-X": "request", "-d": "data", "-H": "header", "-k": "insecure",
+	"-g": "globoff", "-p": "proxytunnel", "-x": "proxy", "-b": "cookie",
+	"-c": "cookie-jar", "-o": "output", "-w": "write-out", "-L": "location",
+}
+
+// combinedFlags maps combined short curl flags to the long names of each
+var combinedFlags = map[string][]string{
+	"-fsS": {"fail", "silent", "show-error"},
+}
+
+// WithMaxCommandLength keeps the rendered command within n bytes, e.g. the
+// 128 KiB Linux limit for a single argument. Longer commands read the body
+// from DefaultBodyFile and, if still too long, all options from
+// DefaultConfigFile; Fallback reports which files the caller must write.
+func WithMaxCommandLength(n int) CurlOption {
+	return func(c *CurlCommand) {
+		c.MaxCommandLength = n
+	}
+}
+
+// fitLength applies the length fallbacks when the command is too long
+func (c *CurlCommand) fitLength() {
+	if c.MaxCommandLength <= 0 || len(c.String()) <= c.MaxCommandLength {
+		return
+	}
+	if c.hasBody() {
+		c.BodyFile = DefaultBodyFile
+		c.Fallback = BodyFileFallback
+		if len(c.String()) <= c.MaxCommandLength {
+			return
+		}
+	}
+	c.ConfigFile = DefaultConfigFile
+	c.Fallback = ConfigFileFallback
+}
+
+// ConfigFileContents returns the command's options in curl config file
+// format, as read with curl -K. Variables are replaced with their values,
+// since curl does not expand them in config files.
+func (c *CurlCommand) ConfigFileContents() string {
+	var b strings.Builder
+	args := c.args()
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a.style != argRaw || !strings.HasPrefix(a.value, "-") {
+			b.WriteString("url = " + configQuote(c.expandPlaceholders(a.value)) + "\n")
+			continue
+		}
+		if names, ok := combinedFlags[a.value]; ok {
+			b.WriteString(strings.Join(names, "\n") + "\n")
+			continue
+		}
+		name := strings.TrimLeft(a.value, "-")
+		if long, ok := longFlags[a.value]; ok {
+			name = long
+		}
+		if !booleanFlags[a.value] && i+1 < len(args) &&
+			(args[i+1].style != argRaw || !strings.HasPrefix(args[i+1].value, "-")) {
+			b.WriteString(name + " = " + configQuote(c.expandPlaceholders(args[i+1].value)) + "\n")
+			i++
+			continue
+		}
+		b.WriteString(name + "\n")
+	}
+	b.WriteString(c.nextConfigContents())
+	return b.String()
+}
+
+// configQuote quotes a value for a curl config file
+func configQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(value) + `"`
+}
@@ -0,0 +1,82 @@
+package http2curl
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithMaxCommandLength(t *testing.T) {
+	tests := []struct {
+		name         string
+		setupReq     func() *http.Request
+		maxLength    int
+		wantFallback LengthFallback
+		wantCommand  string
+		wantConfig   string
+	}{
+		{
+			name: "short command",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("data"))
+				return req
+			},
+			maxLength:    120,
+			wantFallback: NoFallback,
+			wantCommand:  `curl -X 'POST' -d 'data' 'http://example.com' --compressed`,
+		},
+		{
+			name: "long body",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString(strings.Repeat("a", 200)))
+				req.Header.Set("Content-Type", "text/plain")
+				return req
+			},
+			maxLength:    120,
+			wantFallback: BodyFileFallback,
+			wantCommand:  `curl -X 'POST' --data-binary '@body.bin' -H 'Content-Type: text/plain' 'http://example.com' --compressed`,
+		},
+		{
+			name: "long headers",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("GET", "https://example.com/search?q=a", nil)
+				req.Header.Set("Cookie", strings.Repeat("c", 100))
+				req.Header.Set("X-Quote", `say "hi"`)
+				return req
+			},
+			maxLength:    120,
+			wantFallback: ConfigFileFallback,
+			wantCommand:  `curl -K 'curl.config'`,
+			wantConfig: "insecure\n" +
+				"request = \"GET\"\n" +
+				"header = \"Cookie: " + strings.Repeat("c", 100) + "\"\n" +
+				"header = \"X-Quote: say \\\"hi\\\"\"\n" +
+				"url = \"https://example.com/search?q=a\"\n" +
+				"compressed\n" +
+				"max-time = \"10\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := GetCurlCommand(tt.setupReq(),
+				WithMaxCommandLength(tt.maxLength), WithInsecureSkipVerify(), WithCompression())
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if command.Fallback != tt.wantFallback {
+				t.Errorf("Fallback = %v, want %v", command.Fallback, tt.wantFallback)
+			}
+			if tt.wantConfig != "" {
+				command.AddFlag("--max-time", "10")
+				if got := command.ConfigFileContents(); got != tt.wantConfig {
+					t.Errorf("ConfigFileContents() Got:\n%s\nWant:\n%s", got, tt.wantConfig)
+				}
+			}
+			if command.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package http2curl
+
+import (
+	"errors"
+	"time"
+)
+
+// Metrics receives a Measurement of each command generation, e.g. to export
+// the overhead of curl logging to a monitoring system
+type Metrics interface {
+	ObserveGeneration(m Measurement)
+}
+
+// Measurement describes a single command generation
+type Measurement struct {
+	Duration   time.Duration // Time spent generating the command
+	BodyBytes  int64         // Request body bytes buffered
+	Truncated  bool          // Body over MaxBodySize or BodyPeek, or command over MaxCommandLength
+	Redactions int           // Header values replaced with REDACTED
+	Err        error         // Generation error, nil on success
+}
+
+// WithMetrics reports a Measurement of every command generation to m
+func WithMetrics(m Metrics) CurlOption {
+	return func(c *CurlCommand) {
+		c.metrics = m
+	}
+}
+
+// observe reports the generation to the configured Metrics
+func (c *CurlCommand) observe(err error) {
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.ObserveGeneration(Measurement{
+		Duration:   c.duration,
+		BodyBytes:  c.bodyBytes,
+		Truncated:  c.Fallback != NoFallback || c.bodyTruncated || errors.Is(err, ErrBodyTooLarge),
+		Redactions: c.redactionCount(),
+		Err:        err,
+	})
+}
+
+// Stats describes the cost of a generated command
+type Stats struct {
+	BytesRead      int64         // Request body bytes read during generation
+	BodySize       int           // Size of the body passed on the command line, 0 when curl reads it from a file
+	RenderedLength int           // Length of the command as returned by String
+	Duration       time.Duration // Time spent generating the command, rendering excluded
+}
+
+// Stats returns the cost of the command, so callers can enforce budgets and
+// export metrics without measuring it again. It renders the command if it
+// was not rendered yet.
+func (c *CurlCommand) Stats() Stats {
+	stats := Stats{
+		BytesRead:      c.bodyBytes,
+		RenderedLength: len(c.String()),
+		Duration:       c.duration,
+	}
+	if c.BodyFile == "" && c.hasBody() {
+		stats.BodySize = len(c.Body.Data)
+	}
+	return stats
+}
@@ -0,0 +1,125 @@
+package http2curl
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+type recordingMetrics []Measurement
+
+func (r *recordingMetrics) ObserveGeneration(m Measurement) {
+	*r = append(*r, m)
+}
+
+func TestWithMetrics(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		opts           []CurlOption
+		wantBodyBytes  int64
+		wantTruncated  bool
+		wantRedactions int
+		wantErr        error
+	}{
+		{
+			name:           "body and redacted headers",
+			body:           "meow",
+			opts:           []CurlOption{WithRedactedHeaders("Authorization", "Cookie")},
+			wantBodyBytes:  4,
+			wantRedactions: 2,
+		},
+		{
+			name:          "body too large",
+			body:          "meow meow",
+			opts:          []CurlOption{WithMaxBodySize(4)},
+			wantBodyBytes: 5,
+			wantTruncated: true,
+			wantErr:       ErrBodyTooLarge,
+		},
+		{
+			name:          "command too long",
+			body:          "meow meow",
+			opts:          []CurlOption{WithMaxCommandLength(20)},
+			wantBodyBytes: 9,
+			wantTruncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString(tt.body))
+			req.Header.Set("Authorization", "Bearer secret")
+			req.Header.Set("Cookie", "session=secret")
+
+			var metrics recordingMetrics
+			_, err := GetCurlCommand(req, append(tt.opts, WithMetrics(&metrics))...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetCurlCommand() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(metrics) != 1 {
+				t.Fatalf("got %d measurements, want 1", len(metrics))
+			}
+			m := metrics[0]
+			if m.BodyBytes != tt.wantBodyBytes || m.Truncated != tt.wantTruncated ||
+				m.Redactions != tt.wantRedactions || !errors.Is(m.Err, tt.wantErr) {
+				t.Errorf("Measurement = %+v, want BodyBytes %d, Truncated %v, Redactions %d, Err %v",
+					m, tt.wantBodyBytes, tt.wantTruncated, tt.wantRedactions, tt.wantErr)
+			}
+			if m.Duration <= 0 {
+				t.Errorf("Duration = %v, want > 0", m.Duration)
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		opts          []CurlOption
+		wantBytesRead int64
+		wantBodySize  int
+	}{
+		{
+			name:          "body",
+			body:          "meow",
+			wantBytesRead: 4,
+			wantBodySize:  4,
+		},
+		{
+			name:          "peeked body",
+			body:          "meow meow",
+			opts:          []CurlOption{WithBodyPeek(4)},
+			wantBytesRead: 5,
+			wantBodySize:  4,
+		},
+		{
+			name:          "body in a file",
+			body:          "meow",
+			opts:          []CurlOption{WithBodyFromFile("body.bin")},
+			wantBytesRead: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "http://example.com/cats", bytes.NewBufferString(tt.body))
+			command, err := GetCurlCommand(req, tt.opts...)
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			stats := command.Stats()
+			if stats.BytesRead != tt.wantBytesRead || stats.BodySize != tt.wantBodySize {
+				t.Errorf("Stats() = %+v, want BytesRead %d and BodySize %d", stats, tt.wantBytesRead, tt.wantBodySize)
+			}
+			if stats.RenderedLength != len(command.String()) {
+				t.Errorf("Stats().RenderedLength = %d, want %d", stats.RenderedLength, len(command.String()))
+			}
+			if stats.Duration <= 0 {
+				t.Errorf("Stats().Duration = %v, want the generation time", stats.Duration)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package http2curl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// SensitiveHeaders lists headers carrying credentials, which Middleware
+// redacts by default
+var SensitiveHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "X-Api-Key"}
+
+// ServerErrorLogger receives the curl command of a request answered with a
+// 5xx status
+type ServerErrorLogger func(command *CurlCommand, status int)
+
+type commandKey struct{}
+
+// ContextWithCommand returns a copy of ctx carrying command
+func ContextWithCommand(ctx context.Context, command *CurlCommand) context.Context {
+	return context.WithValue(ctx, commandKey{}, command)
+}
+
+// CommandFromContext returns the command attached to ctx by Middleware or
+// ContextWithCommand, or nil if there is none
+func CommandFromContext(ctx context.Context) *CurlCommand {
+	command, _ := ctx.Value(commandKey{}).(*CurlCommand)
+	return command
+}
+
+// MiddlewareMaxBodySize is the default WithMaxBodySize of
+// NewMiddlewareConverter
+const MiddlewareMaxBodySize = 1 << 20
+
+// NewMiddlewareConverter returns the Converter used by Middleware, redacting
+// SensitiveHeaders and limiting bodies to MiddlewareMaxBodySize before
+// applying opts, for framework integrations. Bodies over WithMaxBodySize are
+// truncated like with WithBodyPeek rather than rejected: only the first
+// bytes are read, the rest streaming through to the handler unchanged.
+func NewMiddlewareConverter(opts ...CurlOption) *Converter {
+	opts = append([]CurlOption{WithRedactedHeaders(SensitiveHeaders...), WithMaxBodySize(MiddlewareMaxBodySize)}, opts...)
+	return NewConverter(append(opts, peekMaxBody)...)
+}
+
+// peekMaxBody reads at most MaxBodySize body bytes, unless WithBodyPeek set
+// a limit
+func peekMaxBody(c *CurlCommand) {
+	if c.BodyPeek == 0 && c.MaxBodySize > 0 && c.MaxBodySize <= 1<<31-1 {
+		c.BodyPeek = int(c.MaxBodySize)
+	}
+}
+
+// Middleware returns net/http middleware attaching the curl command of each
+// incoming request to its context, see CommandFromContext. If log is not nil
+// it is called for requests answered with a 5xx status.
+func Middleware(log ServerErrorLogger, opts ...CurlOption) func(http.Handler) http.Handler {
+	converter := NewMiddlewareConverter(opts...)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			command, err := converter.Convert(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			r = r.WithContext(ContextWithCommand(r.Context(), command))
+			if log == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+			if rec.status >= http.StatusInternalServerError {
+				log(command, rec.status)
+			}
+		})
+	}
+}
+
+// statusRecorder records the status code written to a ResponseWriter
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush sends buffered data to the client if the ResponseWriter supports it
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		r.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Hijack takes over the connection if the ResponseWriter supports it, e.g.
+// for WebSocket upgrades
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %T cannot be hijacked", http.ErrNotSupported, r.ResponseWriter)
+	}
+	return h.Hijack()
+}
+
+// Unwrap allows http.ResponseController to reach the original ResponseWriter
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,112 @@
+package http2curl
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		wantLogged  bool
+		wantCommand string
+	}{
+		{
+			name:        "success is not logged",
+			status:      http.StatusOK,
+			wantCommand: `curl -X 'GET' -H 'Authorization: REDACTED' -H 'X-Trace: abc' 'http://example.com/cats'`,
+		},
+		{
+			name:        "server error is logged",
+			status:      http.StatusBadGateway,
+			wantLogged:  true,
+			wantCommand: `curl -X 'GET' -H 'Authorization: REDACTED' -H 'X-Trace: abc' 'http://example.com/cats'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fromContext, logged *CurlCommand
+			var loggedStatus int
+			handler := Middleware(func(command *CurlCommand, status int) {
+				logged, loggedStatus = command, status
+			})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fromContext = CommandFromContext(r.Context())
+				w.WriteHeader(tt.status)
+			}))
+
+			req := httptest.NewRequest("GET", "http://example.com/cats", nil)
+			req.Header.Set("Authorization", "Bearer secret")
+			req.Header.Set("X-Trace", "abc")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if fromContext == nil || fromContext.String() != tt.wantCommand {
+				t.Errorf("Got:\n%v\nWant:\n%s", fromContext, tt.wantCommand)
+			}
+			if (logged != nil) != tt.wantLogged {
+				t.Errorf("logged = %v, want logged %v", logged, tt.wantLogged)
+			}
+			if tt.wantLogged && loggedStatus != tt.status {
+				t.Errorf("logged status = %d, want %d", loggedStatus, tt.status)
+			}
+		})
+	}
+}
+
+func TestMiddlewareBodyLimit(t *testing.T) {
+	var command *CurlCommand
+	var received []byte
+	handler := Middleware(func(*CurlCommand, int) {}, WithMaxBodySize(4))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		command = CommandFromContext(r.Context())
+		received, _ = io.ReadAll(r.Body)
+	}))
+
+	req := httptest.NewRequest("POST", "http://example.com/cats", strings.NewReader("0123456789"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := "# body truncated to 4 bytes, Content-Length: 10\n" +
+		`curl -X 'POST' -d '0123' 'http://example.com/cats'`
+	if command == nil || command.String() != want {
+		t.Errorf("Got:\n%v\nWant:\n%s", command, want)
+	}
+	if string(received) != "0123456789" {
+		t.Errorf("handler received %q, want %q", received, "0123456789")
+	}
+}
+
+// hijackRecorder is a ResponseRecorder whose connection can be hijacked
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.hijacked = true
+	return nil, nil, nil
+}
+
+func TestMiddlewareFlushHijack(t *testing.T) {
+	var hijackErr error
+	handler := Middleware(func(*CurlCommand, int) {})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		_, _, hijackErr = w.(http.Hijacker).Hijack()
+	}))
+
+	rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "http://example.com/ws", nil))
+	if !rec.Flushed || !rec.hijacked || hijackErr != nil {
+		t.Errorf("Flushed = %v, hijacked = %v, Hijack() error = %v, want the calls forwarded", rec.Flushed, rec.hijacked, hijackErr)
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://example.com/ws", nil))
+	if !errors.Is(hijackErr, http.ErrNotSupported) {
+		t.Errorf("Hijack() error = %v, want %v", hijackErr, http.ErrNotSupported)
+	}
+}
@@ -0,0 +1,235 @@
+package http2curl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FormPart is a multipart/form-data part passed to curl with -F, or with
+// --form-string for fields without a file
+type FormPart struct {
+	Name        string
+	Value       string // Field value, for parts without a file
+	File        string // Path of the file curl uploads
+	Filename    string // File name sent for File
+	ContentType string // Content type sent for File, curl guesses one when empty
+}
+
+// MultipartFileResolver returns the path of a file on disk holding the
+// contents of the file part partName, uploaded as filename
+type MultipartFileResolver func(partName, filename string) (path string, ok bool)
+
+// WithMultipartForm renders multipart/form-data bodies with one -F per part,
+// letting curl build the body. File parts are extracted to temporary files
+// referenced by the command, listed by TempFiles; the caller is responsible
+// for removing them with RemoveTempFiles. They are removed when generation
+// fails.
+// Bodies with parts -F cannot express are rendered as usual.
+func WithMultipartForm() CurlOption {
+	return func(c *CurlCommand) {
+		c.MultipartForm = true
+	}
+}
+
+// WithMultipartFileResolver renders multipart bodies like WithMultipartForm,
+// referencing the files returned by resolve for file parts so the command
+// can be rerun with the real uploads. Parts it does not resolve are
+// extracted to temporary files.
+func WithMultipartFileResolver(resolve MultipartFileResolver) CurlOption {
+	return func(c *CurlCommand) {
+		c.MultipartForm = true
+		c.fileResolver = resolve
+	}
+}
+
+// WithMultipartTempDir extracts the file parts of WithMultipartForm to dir
+// instead of the default directory for temporary files
+func WithMultipartTempDir(dir string) CurlOption {
+	return func(c *CurlCommand) {
+		c.tempDir = dir
+	}
+}
+
+// TempFiles returns the files extracted from multipart bodies while
+// generating the command
+func (c *CurlCommand) TempFiles() []string {
+	return append([]string(nil), c.tempFiles...)
+}
+
+// RemoveTempFiles removes the files listed by TempFiles
+func (c *CurlCommand) RemoveTempFiles() error {
+	var errs []error
+	for _, f := range c.tempFiles {
+		if err := os.Remove(f); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	c.tempFiles = nil
+	return errors.Join(errs...)
+}
+
+// WithPreserveMultipartBoundary sends multipart bodies exactly as received
+// with --data-binary and the original Content-Type, boundary included, for
+// servers sensitive to the boundary format. It takes precedence over
+// WithMultipartForm.
+func WithPreserveMultipartBoundary() CurlOption {
+	return func(c *CurlCommand) {
+		c.PreserveMultipartBoundary = true
+	}
+}
+
+// preserveMultipart renders a multipart body byte for byte, keeping the
+// CRLF line endings -d would mangle
+func (c *CurlCommand) preserveMultipart(contentType string) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && c.hasBody() && strings.HasPrefix(mediaType, "multipart/") {
+		c.Body.HexEscaped = true
+	}
+}
+
+// errNotForm means a body cannot be rendered with -F
+var errNotForm = errors.New("not a multipart form")
+
+// buildFormParts replaces a multipart/form-data body with its parts
+func (c *CurlCommand) buildFormParts(contentType string) error {
+	if !c.hasBody() || c.CurlJSON {
+		return nil
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return nil
+	}
+	parts, files, err := c.formParts(params["boundary"])
+	if err != nil {
+		for _, f := range files {
+			os.Remove(f)
+		}
+		if errors.Is(err, errNotForm) {
+			return nil
+		}
+		return err
+	}
+	c.FormParts = parts
+	c.tempFiles = append(c.tempFiles, files...)
+	c.Body = nil
+	return nil
+}
+
+// formParts parses the body into parts, returning the temporary files
+// created along the way
+func (c *CurlCommand) formParts(boundary string) ([]FormPart, []string, error) {
+	var parts []FormPart
+	var files []string
+	reader := multipart.NewReader(strings.NewReader(c.Body.Data), boundary)
+	for {
+		p, err := reader.NextRawPart()
+		if err == io.EOF {
+			return parts, files, nil
+		}
+		if err != nil || p.FormName() == "" || !formHeadersOnly(p.Header) {
+			return nil, files, errNotForm
+		}
+		content, err := io.ReadAll(p)
+		if err != nil {
+			return nil, files, errNotForm
+		}
+		part := FormPart{Name: p.FormName()}
+		if p.FileName() == "" {
+			if p.Header.Get("Content-Type") != "" {
+				return nil, files, errNotForm // --form-string cannot set a type
+			}
+			part.Value = string(content)
+			parts = append(parts, part)
+			continue
+		}
+
+		part.Filename, part.ContentType = p.FileName(), p.Header.Get("Content-Type")
+		if path, ok := c.resolveFile(part.Name, part.Filename); ok {
+			part.File = path
+		} else {
+			path, err := extractFormFile(c.tempDir, part.Filename, content)
+			if err != nil {
+				return nil, files, err
+			}
+			part.File = path
+			files = append(files, path)
+		}
+		parts = append(parts, part)
+	}
+}
+
+func (c *CurlCommand) resolveFile(name, filename string) (string, bool) {
+	if c.fileResolver == nil {
+		return "", false
+	}
+	return c.fileResolver(name, filename)
+}
+
+// formHeadersOnly reports whether a part has no headers besides those -F
+// sets itself
+func formHeadersOnly(header textproto.MIMEHeader) bool {
+	for k := range header {
+		if k != "Content-Disposition" && k != "Content-Type" {
+			return false
+		}
+	}
+	return true
+}
+
+// extractFormFile writes the content of a file part to a temporary file in
+// dir, or the default directory if dir is empty
+func extractFormFile(dir, filename string, content []byte) (string, error) {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune("*;,\"\\", r) {
+			return -1 // Keep the path free of -F separators
+		}
+		return r
+	}, filepath.Base(filename))
+	if name == "." || name == string(filepath.Separator) {
+		name = ""
+	}
+	f, err := os.CreateTemp(dir, "http2curl-*-"+name)
+	if err != nil {
+		return "", fmt.Errorf("multipart temp file error: %w", err)
+	}
+	_, err = f.Write(content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("multipart temp file error: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// formArg returns the -F or --form-string flag and parameter for part
+func formArg(part FormPart) (string, string) {
+	if part.File == "" {
+		return "--form-string", part.Name + "=" + part.Value
+	}
+	value := part.Name + "=@" + formQuote(part.File)
+	if part.Filename != filepath.Base(part.File) {
+		value += ";filename=" + formQuote(part.Filename)
+	}
+	if part.ContentType != "" {
+		value += ";type=" + part.ContentType
+	}
+	return "-F", value
+}
+
+// formQuote quotes a -F file name or path containing separators
+func formQuote(s string) string {
+	if !strings.ContainsAny(s, ";,\"\\") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,137 @@
+package http2curl
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newUploadRequest(t *testing.T, extraHeader bool) *http.Request {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.SetBoundary("cats-boundary")
+	w.WriteField("name", "Tom; @the cat")
+	avatar, _ := w.CreateFormFile("avatar", "tom.png")
+	avatar.Write([]byte("\x89PNG"))
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="notes"; filename="notes;v2.txt"`)
+	header.Set("Content-Type", "text/plain")
+	if extraHeader {
+		header.Set("Content-Transfer-Encoding", "8bit")
+	}
+	notes, _ := w.CreatePart(header)
+	notes.Write([]byte("meow"))
+	w.Close()
+
+	req, err := http.NewRequest("POST", "http://example.com/upload", &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestMultipartForm(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	resolve := func(partName, filename string) (string, bool) {
+		if partName == "avatar" && filename == "tom.png" {
+			return "/srv/uploads/tom.png", true
+		}
+		return "", false
+	}
+	command, err := GetCurlCommand(newUploadRequest(t, false), WithMultipartFileResolver(resolve))
+	if err != nil {
+		t.Fatalf("GetCurlCommand() error = %v", err)
+	}
+
+	if len(command.FormParts) != 3 {
+		t.Fatalf("FormParts = %+v, want 3 parts", command.FormParts)
+	}
+	extracted := command.FormParts[2].File
+	if filepath.Dir(extracted) != tmp {
+		t.Errorf("extracted file %q, want it in %q", extracted, tmp)
+	}
+	if data, _ := os.ReadFile(extracted); string(data) != "meow" {
+		t.Errorf("extracted file contents = %q, want %q", data, "meow")
+	}
+
+	if !strings.HasSuffix(extracted, "-notesv2.txt") {
+		t.Errorf("extracted file %q, want the name to end in -notesv2.txt", extracted)
+	}
+	want := `curl -X 'POST' --form-string 'name=Tom; @the cat' -F 'avatar=@/srv/uploads/tom.png;type=application/octet-stream' ` +
+		`-F 'notes=@` + extracted + `;filename="notes;v2.txt";type=text/plain' 'http://example.com/upload'`
+	if got := command.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
+
+func TestMultipartFormFallback(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	command, err := GetCurlCommand(newUploadRequest(t, true), WithMultipartForm())
+	if err != nil {
+		t.Fatalf("GetCurlCommand() error = %v", err)
+	}
+	if len(command.FormParts) != 0 || strings.Contains(command.String(), " -F ") {
+		t.Errorf("command with unsupported part headers:\n%s", command)
+	}
+	if entries, _ := os.ReadDir(tmp); len(entries) != 0 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestPreserveMultipartBoundary(t *testing.T) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.SetBoundary("cats-boundary")
+	w.WriteField("name", "Tom")
+	w.Close()
+	req, _ := http.NewRequest("POST", "http://example.com/upload", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	command, err := GetCurlCommand(req, WithMultipartForm(), WithPreserveMultipartBoundary())
+	if err != nil {
+		t.Fatalf("GetCurlCommand() error = %v", err)
+	}
+	want := `curl -X 'POST' --data-binary $'--cats-boundary\r\nContent-Disposition: form-data; name="name"\r\n\r\nTom\r\n--cats-boundary--\r\n' ` +
+		`-H 'Content-Type: multipart/form-data; boundary=cats-boundary' 'http://example.com/upload'`
+	if got := command.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
+
+func TestMultipartTempFiles(t *testing.T) {
+	tmp := t.TempDir()
+
+	command, err := GetCurlCommand(newUploadRequest(t, false), WithMultipartForm(), WithMultipartTempDir(tmp))
+	if err != nil {
+		t.Fatalf("GetCurlCommand() error = %v", err)
+	}
+	files := command.TempFiles()
+	if len(files) != 2 || filepath.Dir(files[0]) != tmp || files[1] != command.FormParts[2].File {
+		t.Fatalf("TempFiles() = %v, want the 2 extracted files in %q", files, tmp)
+	}
+	if err := command.RemoveTempFiles(); err != nil {
+		t.Fatalf("RemoveTempFiles() error = %v", err)
+	}
+	if entries, _ := os.ReadDir(tmp); len(entries) != 0 || len(command.TempFiles()) != 0 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+
+	_, err = GetCurlCommand(newUploadRequest(t, false), WithMultipartForm(), WithMultipartTempDir(tmp), WithStrict())
+	if !errors.Is(err, ErrLossyConversion) {
+		t.Fatalf("GetCurlCommand() error = %v, want %v", err, ErrLossyConversion)
+	}
+	if entries, _ := os.ReadDir(tmp); len(entries) != 0 {
+		t.Errorf("temporary files left behind after an error: %v", entries)
+	}
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package http2curl
+
+const raceEnabled = false
@@ -0,0 +1,41 @@
+package http2curl
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// WithNormalizedJSON compacts JSON bodies and sorts their object keys, so
+// the same request renders the same command whatever order it was
+// marshaled in, e.g. for snapshot tests and log deduplication. Numbers are
+// kept exactly as sent.
+func WithNormalizedJSON() CurlOption {
+	return func(c *CurlCommand) {
+		c.NormalizeJSON = true
+	}
+}
+
+// normalizeJSON rewrites a JSON object or array body in normalized form,
+// leaving other bodies untouched
+func (c *CurlCommand) normalizeJSON() {
+	if !c.hasBody() {
+		return
+	}
+	if trimmed := strings.TrimSpace(c.Body.Data); trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return
+	}
+	var v interface{}
+	decoder := json.NewDecoder(strings.NewReader(c.Body.Data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil || decoder.More() {
+		return
+	}
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return
+	}
+	c.Body.Data = strings.TrimSuffix(b.String(), "\n")
+}
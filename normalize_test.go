@@ -0,0 +1,51 @@
+package http2curl
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithNormalizedJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantCommand string
+	}{
+		{
+			name: "object",
+			body: "{\n  \"name\": \"Tom\",\n  \"age\": 3,\n  \"owner\": {\"z\": 1.50, \"a\": \"<b>&</b>\"}\n}",
+			wantCommand: `curl -X 'POST' -d '{"age":3,"name":"Tom","owner":{"a":"<b>&</b>","z":1.50}}' ` +
+				`-H 'Content-Type: application/json' 'http://example.com/cats'`,
+		},
+		{
+			name:        "array",
+			body:        `[ {"b": 12345678901234567890, "a": null} ]`,
+			wantCommand: `curl -X 'POST' -d '[{"a":null,"b":12345678901234567890}]' -H 'Content-Type: application/json' 'http://example.com/cats'`,
+		},
+		{
+			name:        "not JSON",
+			body:        `{"name": "Tom"} trailing`,
+			wantCommand: `curl -X 'POST' -d '{"name": "Tom"} trailing' -H 'Content-Type: application/json' 'http://example.com/cats'`,
+		},
+		{
+			name:        "scalar",
+			body:        ` "Tom" `,
+			wantCommand: `curl -X 'POST' -d ' "Tom" ' -H 'Content-Type: application/json' 'http://example.com/cats'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "http://example.com/cats", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			command, err := GetCurlCommand(req, WithNormalizedJSON())
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.wantCommand)
+			}
+		})
+	}
+}
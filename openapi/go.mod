@@ -0,0 +1,20 @@
+module github.com/chodges15/http2curl/v3/openapi
+
+go 1.20
+
+require github.com/chodges15/http2curl/v3 v3.0.0-00010101000000-000000000000
+
+require (
+	github.com/getkin/kin-openapi v0.118.0
+	github.com/go-openapi/jsonpointer v0.19.5 // indirect
+	github.com/go-openapi/swag v0.19.5 // indirect
+	github.com/invopop/yaml v0.1.0 // indirect
+	github.com/josharian/intern v1.0.0 // indirect
+	github.com/mailru/easyjson v0.7.7 // indirect
+	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826 // indirect
+	github.com/perimeterx/marshmallow v1.1.4 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/chodges15/http2curl/v3 => ../
@@ -0,0 +1,312 @@
+// Package openapi generates curl examples for OpenAPI 3 operations, with the
+// same escaping and options as commands generated at runtime, e.g. for the
+// "Try it" snippets of API documentation.
+//
+//	doc, _ := openapi3.NewLoader().LoadFromFile("openapi.yaml")
+//	command, err := openapi.Command(doc, "POST", "/cats", openapi.Example{
+//		PathParams: map[string]string{"owner": "tom"},
+//	})
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/chodges15/http2curl/v3"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Placeholders rendered for credentials missing from Example.Credentials
+const (
+	TokenPlaceholder       = "<token>"
+	CredentialsPlaceholder = "<credentials>"
+	APIKeyPlaceholder      = "<api-key>"
+)
+
+// Example holds the values used in place of, or in addition to, the
+// examples of the specification
+type Example struct {
+	Server      string            // Base URL, the document's first server when empty
+	PathParams  map[string]string // Path parameter values by name
+	Query       map[string]string // Query parameter values by name
+	Headers     map[string]string // Header values by name
+	Body        interface{}       // Request body, the media type's example when nil
+	Credentials map[string]string // Credentials by security scheme name
+}
+
+// Command returns the curl command of the operation at path and method in
+// doc, filling in parameters and the request body from example and then
+// from the examples, schema examples and defaults of the specification
+func Command(doc *openapi3.T, method, path string, example Example, opts ...http2curl.CurlOption) (*http2curl.CurlCommand, error) {
+	item := doc.Paths.Find(path)
+	if item == nil {
+		return nil, fmt.Errorf("openapi: no path %s", path)
+	}
+	op := item.GetOperation(strings.ToUpper(method))
+	if op == nil {
+		return nil, fmt.Errorf("openapi: no operation %s %s", strings.ToUpper(method), path)
+	}
+
+	server := example.Server
+	if server == "" {
+		if len(doc.Servers) == 0 {
+			return nil, fmt.Errorf("openapi: no server for %s %s", strings.ToUpper(method), path)
+		}
+		server = serverURL(doc.Servers[0])
+	}
+
+	builder := http2curl.NewCommand().Method(strings.ToUpper(method)).Options(opts...)
+	params := append(append(openapi3.Parameters(nil), item.Parameters...), op.Parameters...)
+	for _, ref := range params {
+		param := ref.Value
+		if param == nil {
+			continue
+		}
+		var value interface{}
+		var ok bool
+		switch param.In {
+		case openapi3.ParameterInPath:
+			value, ok = lookup(example.PathParams, param.Name)
+		case openapi3.ParameterInQuery:
+			value, ok = lookup(example.Query, param.Name)
+		case openapi3.ParameterInHeader:
+			value, ok = lookup(example.Headers, param.Name)
+		default:
+			continue
+		}
+		if !ok {
+			value, ok = parameterExample(param)
+		}
+		if !ok {
+			if param.Required {
+				return nil, fmt.Errorf("openapi: no example value for %s parameter %q", param.In, param.Name)
+			}
+			continue
+		}
+		switch param.In {
+		case openapi3.ParameterInPath:
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(fmt.Sprint(value)))
+		case openapi3.ParameterInHeader:
+			builder.Header(param.Name, fmt.Sprint(value))
+		}
+	}
+	builder.URL(strings.TrimSuffix(server, "/") + path)
+
+	// Query parameters are added once the URL is set
+	for _, ref := range params {
+		if param := ref.Value; param != nil && param.In == openapi3.ParameterInQuery {
+			value, ok := lookup(example.Query, param.Name)
+			if !ok {
+				value, ok = parameterExample(param)
+			}
+			if !ok {
+				continue
+			}
+			if values, isList := value.([]interface{}); isList {
+				for _, v := range values {
+					builder.Query(param.Name, fmt.Sprint(v))
+				}
+				continue
+			}
+			builder.Query(param.Name, fmt.Sprint(value))
+		}
+	}
+
+	addSecurity(builder, doc, op, example.Credentials)
+	if err := addBody(builder, op, example.Body); err != nil {
+		return nil, err
+	}
+	return builder.Build()
+}
+
+func lookup(values map[string]string, name string) (interface{}, bool) {
+	value, ok := values[name]
+	return value, ok
+}
+
+// serverURL returns the server URL with its variables set to their defaults
+func serverURL(server *openapi3.Server) string {
+	u := server.URL
+	for name, variable := range server.Variables {
+		u = strings.ReplaceAll(u, "{"+name+"}", variable.Default)
+	}
+	return u
+}
+
+// parameterExample returns the example value of param from the
+// specification
+func parameterExample(param *openapi3.Parameter) (interface{}, bool) {
+	if param.Example != nil {
+		return param.Example, true
+	}
+	if value, ok := firstExample(param.Examples); ok {
+		return value, true
+	}
+	return schemaExample(param.Schema)
+}
+
+func firstExample(examples openapi3.Examples) (interface{}, bool) {
+	names := make([]string, 0, len(examples))
+	for name := range examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if ref := examples[name]; ref != nil && ref.Value != nil && ref.Value.Value != nil {
+			return ref.Value.Value, true
+		}
+	}
+	return nil, false
+}
+
+func schemaExample(schema *openapi3.SchemaRef) (interface{}, bool) {
+	switch {
+	case schema == nil || schema.Value == nil:
+		return nil, false
+	case schema.Value.Example != nil:
+		return schema.Value.Example, true
+	case schema.Value.Default != nil:
+		return schema.Value.Default, true
+	default:
+		return nil, false
+	}
+}
+
+// addSecurity adds the credentials of the first security requirement of the
+// operation, or of the document when the operation sets none
+func addSecurity(builder *http2curl.Builder, doc *openapi3.T, op *openapi3.Operation, credentials map[string]string) {
+	requirements := doc.Security
+	if op.Security != nil {
+		requirements = *op.Security
+	}
+	if len(requirements) == 0 || doc.Components == nil {
+		return
+	}
+	names := make([]string, 0, len(requirements[0]))
+	for name := range requirements[0] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ref := doc.Components.SecuritySchemes[name]
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		scheme := ref.Value
+		credential, ok := credentials[name]
+		switch {
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"):
+			if !ok {
+				credential = TokenPlaceholder
+			}
+			builder.Header("Authorization", "Bearer "+credential)
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+			if !ok {
+				credential = CredentialsPlaceholder
+			}
+			builder.Header("Authorization", "Basic "+credential)
+		case scheme.Type == "apiKey" && scheme.In == "header":
+			if !ok {
+				credential = APIKeyPlaceholder
+			}
+			builder.Header(scheme.Name, credential)
+		case scheme.Type == "apiKey" && scheme.In == "query":
+			if !ok {
+				credential = APIKeyPlaceholder
+			}
+			builder.Query(scheme.Name, credential)
+		}
+	}
+}
+
+// addBody sets the request body from body, or from the example of the
+// preferred media type of the operation
+func addBody(builder *http2curl.Builder, op *openapi3.Operation, body interface{}) error {
+	if op.RequestBody == nil || op.RequestBody.Value == nil {
+		return nil
+	}
+	contentType, media := preferredMediaType(op.RequestBody.Value.Content)
+	if media == nil {
+		return nil
+	}
+	if body == nil {
+		var ok bool
+		if body = media.Example; body == nil {
+			if body, ok = firstExample(media.Examples); !ok {
+				body, ok = schemaExample(media.Schema)
+			}
+		}
+		if body == nil {
+			if op.RequestBody.Value.Required {
+				return fmt.Errorf("openapi: no example request body for %s", contentType)
+			}
+			return nil
+		}
+	}
+
+	builder.Header("Content-Type", contentType)
+	switch {
+	case isJSON(contentType):
+		builder.JSONBody(body)
+	case contentType == "application/x-www-form-urlencoded":
+		form := url.Values{}
+		fields, ok := body.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("openapi: form body example is %T, not an object", body)
+		}
+		for k, v := range fields {
+			form.Set(k, fmt.Sprint(v))
+		}
+		builder.FormBody(form)
+	default:
+		if s, ok := body.(string); ok {
+			builder.Body(s)
+			break
+		}
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("openapi: request body: %w", err)
+		}
+		builder.Body(string(data))
+	}
+	return nil
+}
+
+// preferredMediaType returns JSON media types first, then forms, then the
+// first media type in alphabetical order
+func preferredMediaType(content openapi3.Content) (string, *openapi3.MediaType) {
+	types := make([]string, 0, len(content))
+	for contentType := range content {
+		types = append(types, contentType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if rank(types[i]) != rank(types[j]) {
+			return rank(types[i]) < rank(types[j])
+		}
+		return types[i] < types[j]
+	})
+	if len(types) == 0 {
+		return "", nil
+	}
+	return types[0], content[types[0]]
+}
+
+func rank(contentType string) int {
+	switch {
+	case contentType == "application/json":
+		return 0
+	case isJSON(contentType):
+		return 1
+	case contentType == "application/x-www-form-urlencoded":
+		return 2
+	default:
+		return 3
+	}
+}
+
+func isJSON(contentType string) bool {
+	return contentType == "application/json" || strings.HasSuffix(contentType, "+json")
+}
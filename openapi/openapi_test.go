@@ -0,0 +1,136 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/chodges15/http2curl/v3"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const spec = `
+openapi: 3.0.0
+info: {title: Cats, version: "1.0"}
+servers:
+  - url: https://{env}.example.com/v1
+    variables:
+      env: {default: api}
+components:
+  securitySchemes:
+    bearer: {type: http, scheme: bearer}
+    key: {type: apiKey, in: header, name: X-Api-Key}
+security:
+  - bearer: []
+paths:
+  /owners/{owner}/cats:
+    parameters:
+      - {name: owner, in: path, required: true, schema: {type: string, example: tom}}
+    get:
+      parameters:
+        - {name: color, in: query, example: grey}
+        - {name: limit, in: query, schema: {type: integer}}
+        - {name: X-Request-Id, in: header, schema: {type: string, default: req-1}}
+      responses:
+        "200": {description: OK}
+    post:
+      security:
+        - key: []
+      requestBody:
+        required: true
+        content:
+          text/plain:
+            schema: {type: string}
+          application/json:
+            schema: {type: object}
+            examples:
+              tom: {value: {name: tom, age: 3}}
+      responses:
+        "201": {description: Created}
+  /owners/{owner}:
+    delete:
+      parameters:
+        - {name: owner, in: path, required: true, schema: {type: string}}
+      security: []
+      responses:
+        "204": {description: Deleted}
+`
+
+func TestCommand(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	if err != nil {
+		t.Fatalf("LoadFromData() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		example     Example
+		opts        []http2curl.CurlOption
+		wantCommand string
+		wantErr     bool
+	}{
+		{
+			name:        "examples from the specification",
+			method:      "get",
+			path:        "/owners/{owner}/cats",
+			wantCommand: `curl -X 'GET' -H 'Authorization: Bearer <token>' -H 'X-Request-Id: req-1' 'https://api.example.com/v1/owners/tom/cats?color=grey'`,
+		},
+		{
+			name:   "example values and credentials",
+			method: "GET",
+			path:   "/owners/{owner}/cats",
+			example: Example{
+				Server:      "http://localhost:8080",
+				PathParams:  map[string]string{"owner": "o'neill"},
+				Query:       map[string]string{"limit": "10"},
+				Credentials: map[string]string{"bearer": "abc"},
+			},
+			opts:        []http2curl.CurlOption{http2curl.WithRedactedHeaders("Authorization")},
+			wantCommand: `curl -X 'GET' -H 'Authorization: REDACTED' -H 'X-Request-Id: req-1' 'http://localhost:8080/owners/o%27neill/cats?color=grey&limit=10'`,
+		},
+		{
+			name:        "JSON request body example",
+			method:      "POST",
+			path:        "/owners/{owner}/cats",
+			wantCommand: `curl -X 'POST' -d '{"age":3,"name":"tom"}' -H 'Content-Type: application/json' -H 'X-Api-Key: <api-key>' 'https://api.example.com/v1/owners/tom/cats'`,
+		},
+		{
+			name:        "request body override",
+			method:      "POST",
+			path:        "/owners/{owner}/cats",
+			example:     Example{Body: map[string]string{"name": "jerry"}},
+			wantCommand: `curl -X 'POST' -d '{"name":"jerry"}' -H 'Content-Type: application/json' -H 'X-Api-Key: <api-key>' 'https://api.example.com/v1/owners/tom/cats'`,
+		},
+		{
+			name:        "operation without security",
+			method:      "DELETE",
+			path:        "/owners/{owner}",
+			example:     Example{PathParams: map[string]string{"owner": "tom"}},
+			wantCommand: `curl -X 'DELETE' 'https://api.example.com/v1/owners/tom'`,
+		},
+		{
+			name:    "missing required path parameter",
+			method:  "DELETE",
+			path:    "/owners/{owner}",
+			wantErr: true,
+		},
+		{
+			name:    "unknown operation",
+			method:  "PUT",
+			path:    "/owners/{owner}",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := Command(doc, tt.method, tt.path, tt.example, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Command() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && command.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+		})
+	}
+}
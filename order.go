@@ -0,0 +1,67 @@
+package http2curl
+
+// FlagClass is a segment of the curl command line whose position can be
+// chosen with WithFlagOrder
+type FlagClass int
+
+const (
+	FlagClassTLS       FlagClass = iota // -k
+	FlagClassTransport                  // -g, --path-as-is, -p and -x
+	FlagClassMethod                     // -X and --request-target
+	FlagClassData                       // -d, --data-binary or --json
+	FlagClassHeaders                    // -H, -b and -c
+	FlagClassURL                        // The URL
+	FlagClassExtra                      // --compressed, -fsS, -o, -w, --trace and Flags
+	flagClassCount
+)
+
+// DefaultFlagOrder is the order segments are rendered in by default
+var DefaultFlagOrder = []FlagClass{
+	FlagClassTLS, FlagClassTransport, FlagClassMethod, FlagClassData,
+	FlagClassHeaders, FlagClassURL, FlagClassExtra,
+}
+
+// WithFlagOrder renders the command line segments in the given order, e.g.
+// WithFlagOrder(FlagClassURL) to put the URL first like browser devtools.
+// Segments left out follow in their default order.
+func WithFlagOrder(order ...FlagClass) CurlOption {
+	return func(c *CurlCommand) {
+		c.FlagOrder = order
+	}
+}
+
+// flagOrder returns the complete segment order, with the segments missing
+// from FlagOrder appended in their default order
+func (c *CurlCommand) flagOrder() [flagClassCount]FlagClass {
+	var order [flagClassCount]FlagClass
+	var seen [flagClassCount]bool
+	n := 0
+	for _, classes := range [][]FlagClass{c.FlagOrder, DefaultFlagOrder} {
+		for _, class := range classes {
+			if class < 0 || class >= flagClassCount || seen[class] {
+				continue
+			}
+			seen[class] = true
+			order[n] = class
+			n++
+		}
+	}
+	return order
+}
+
+// orderArgs reorders args, whose segments end at the given indexes in the
+// default order, following FlagOrder
+func (c *CurlCommand) orderArgs(args []arg, ends [flagClassCount]int) []arg {
+	if len(c.FlagOrder) == 0 {
+		return args
+	}
+	ordered := make([]arg, 0, len(args))
+	for _, class := range c.flagOrder() {
+		start := 0
+		if class > 0 {
+			start = ends[class-1]
+		}
+		ordered = append(ordered, args[start:ends[class]]...)
+	}
+	return ordered
+}
@@ -0,0 +1,61 @@
+package http2curl
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithFlagOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		order       []FlagClass
+		wantCommand string
+	}{
+		{
+			name:        "default order",
+			wantCommand: `curl -k -X 'POST' -d 'meow' -H 'X-Auth-Token: private-token' 'https://example.com/cats' --compressed`,
+		},
+		{
+			name:        "URL first",
+			order:       []FlagClass{FlagClassURL},
+			wantCommand: `curl 'https://example.com/cats' -k -X 'POST' -d 'meow' -H 'X-Auth-Token: private-token' --compressed`,
+		},
+		{
+			name:        "headers before data",
+			order:       []FlagClass{FlagClassTLS, FlagClassMethod, FlagClassHeaders, FlagClassData},
+			wantCommand: `curl -k -X 'POST' -H 'X-Auth-Token: private-token' -d 'meow' 'https://example.com/cats' --compressed`,
+		},
+		{
+			name:        "duplicate and unknown classes are ignored",
+			order:       []FlagClass{FlagClassExtra, FlagClass(42), FlagClassExtra},
+			wantCommand: `curl --compressed -k -X 'POST' -d 'meow' -H 'X-Auth-Token: private-token' 'https://example.com/cats'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "https://example.com/cats", strings.NewReader("meow"))
+			req.Header.Set("X-Auth-Token", "private-token")
+			command, err := GetCurlCommand(req, WithInsecureSkipVerify(), WithCompression(), WithFlagOrder(tt.order...))
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestFlagOrderInvalidatesCache(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	command, _ := GetCurlCommand(req)
+	_ = command.String()
+	command.FlagOrder = []FlagClass{FlagClassURL}
+
+	want := `curl 'http://example.com' -X 'GET'`
+	if got := command.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
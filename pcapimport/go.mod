@@ -0,0 +1,7 @@
+module github.com/chodges15/http2curl/v3/pcapimport
+
+go 1.20
+
+require github.com/chodges15/http2curl/v3 v3.0.0-00010101000000-000000000000
+
+replace github.com/chodges15/http2curl/v3 => ../
@@ -0,0 +1,131 @@
+// Package pcapimport generates the curl commands of the HTTP/1.1 requests
+// found in packet captures, from the reassembled client side of TCP
+// streams. It has no dependency on a capture library: any io.Reader of the
+// bytes a client sent works, such as a gopacket tcpreader.ReaderStream, a
+// raw stream saved from Wireshark, or the output of tshark's follow
+// statistics read with ReadTsharkFollow.
+//
+//	func (f *factory) New(netFlow, tcpFlow gopacket.Flow) tcpassembly.Stream {
+//		r := tcpreader.NewReaderStream()
+//		server := net.JoinHostPort(netFlow.Dst().String(), tcpFlow.Dst().String())
+//		go pcapimport.ReadEach(&r, server, false, f.handle)
+//		return &r
+//	}
+package pcapimport
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/chodges15/http2curl/v3"
+)
+
+// ErrTsharkFormat is returned by ReadTsharkFollow when its input is not the
+// raw output of tshark's follow statistics
+var ErrTsharkFormat = errors.New("unrecognized tshark follow output")
+
+// HandleFunc receives the curl command of each request of a stream
+type HandleFunc func(command *http2curl.CurlCommand)
+
+// ReadEach reads the HTTP/1.1 requests sent on a stream to server, the
+// host:port used for requests without a Host header, and passes their curl
+// command to handle. URLs use https if isTLS is set, the stream having been
+// decrypted. It returns nil at the end of the stream, or the error of the
+// first request that cannot be read, typically one cut short by the end of
+// the capture. The rest of the stream is drained so a reassembler writing
+// to r is never blocked.
+func ReadEach(r io.Reader, server string, isTLS bool, handle HandleFunc, opts ...http2curl.CurlOption) error {
+	converter := http2curl.NewConverter(opts...)
+	br := bufio.NewReader(r)
+	defer io.Copy(io.Discard, br)
+	for {
+		if _, err := br.Peek(1); err == io.EOF {
+			return nil
+		}
+		req, err := readRequest(br, server, isTLS)
+		if err != nil {
+			return err
+		}
+		command, err := converter.Convert(req)
+		if err != nil {
+			return err
+		}
+		handle(command)
+	}
+}
+
+// ReadRequests returns the curl commands of the requests read by ReadEach,
+// along with its error, if any
+func ReadRequests(r io.Reader, server string, isTLS bool, opts ...http2curl.CurlOption) ([]*http2curl.CurlCommand, error) {
+	var commands []*http2curl.CurlCommand
+	err := ReadEach(r, server, isTLS, func(command *http2curl.CurlCommand) {
+		commands = append(commands, command)
+	}, opts...)
+	return commands, err
+}
+
+// readRequest reads a request and its body, so the next request of the
+// stream can be read
+func readRequest(br *bufio.Reader, server string, isTLS bool) (*http.Request, error) {
+	req, err := http.ReadRequest(br)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", req.Method, req.RequestURI, err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if req.Host == "" {
+		req.Host = server
+	}
+	if isTLS {
+		req.TLS = &tls.ConnectionState{}
+	}
+	return req, nil
+}
+
+// ReadTsharkFollow returns the curl commands of the requests of a TCP or
+// TLS stream in the output of tshark -qz follow,tcp,raw,<stream> or
+// follow,tls,raw,<stream>. Node 0 is taken as the client and node 1 as the
+// server; requests of a TLS stream use https.
+func ReadTsharkFollow(r io.Reader, opts ...http2curl.CurlOption) ([]*http2curl.CurlCommand, error) {
+	var (
+		client        bytes.Buffer
+		server, proto string
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "" || strings.HasPrefix(line, "====") || strings.HasPrefix(line, "Filter:") ||
+			strings.HasPrefix(line, "Node 0:"):
+		case strings.HasPrefix(line, "Follow:"):
+			proto = strings.TrimSpace(strings.TrimPrefix(line, "Follow:"))
+		case strings.HasPrefix(line, "Node 1:"):
+			server = strings.TrimSpace(strings.TrimPrefix(line, "Node 1:"))
+		case strings.HasPrefix(line, "\t"): // Sent by node 1
+		default:
+			data, err := hex.DecodeString(line)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrTsharkFormat, err)
+			}
+			client.Write(data)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if proto != "tcp,raw" && proto != "tls,raw" {
+		return nil, fmt.Errorf("%w: follow mode %q, want tcp,raw or tls,raw", ErrTsharkFormat, proto)
+	}
+	return ReadRequests(&client, server, proto == "tls,raw", opts...)
+}
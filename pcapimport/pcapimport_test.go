@@ -0,0 +1,79 @@
+package pcapimport
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/chodges15/http2curl/v3"
+)
+
+const stream = "GET /cats?color=grey HTTP/1.1\r\nHost: example.com\r\nAccept: application/json\r\n\r\n" +
+	"POST /cats HTTP/1.1\r\nHost: example.com\r\nContent-Type: text/plain\r\nContent-Length: 4\r\n\r\nmeow" +
+	"GET /health HTTP/1.0\r\n\r\n"
+
+func TestReadRequests(t *testing.T) {
+	commands, err := ReadRequests(strings.NewReader(stream), "203.0.113.7:8080", false)
+	if err != nil {
+		t.Fatalf("ReadRequests() error = %v", err)
+	}
+	want := []string{
+		`curl -X 'GET' -H 'Accept: application/json' 'http://example.com/cats?color=grey'`,
+		`curl -X 'POST' -d 'meow' -H 'Content-Type: text/plain' 'http://example.com/cats'`,
+		`curl -X 'GET' 'http://203.0.113.7:8080/health'`,
+	}
+	if len(commands) != len(want) {
+		t.Fatalf("ReadRequests() returned %d commands, want %d", len(commands), len(want))
+	}
+	for i, command := range commands {
+		if got := command.String(); got != want[i] {
+			t.Errorf("Got:\n%s\nWant:\n%s", got, want[i])
+		}
+	}
+}
+
+func TestReadEachTruncated(t *testing.T) {
+	r := strings.NewReader(stream[:len(stream)-30])
+	var handled int
+	err := ReadEach(r, "203.0.113.7:8080", true, func(command *http2curl.CurlCommand) {
+		if !strings.Contains(command.String(), "'https://example.com/cats") {
+			t.Errorf("command = %s, want an https URL", command)
+		}
+		handled++
+	})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ReadEach() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if handled != 1 {
+		t.Errorf("ReadEach() handled %d requests, want 1", handled)
+	}
+	if r.Len() != 0 {
+		t.Errorf("ReadEach() left %d bytes unread", r.Len())
+	}
+}
+
+func TestReadTsharkFollow(t *testing.T) {
+	request := "GET /cats HTTP/1.1\r\nHost: example.com\r\n\r\n"
+	output := "\n===================================================================\n" +
+		"Follow: tls,raw\nFilter: tcp.stream eq 0\nNode 0: 10.0.0.5:53422\nNode 1: 93.184.216.34:443\n" +
+		hex.EncodeToString([]byte(request[:20])) + "\n" +
+		hex.EncodeToString([]byte(request[20:])) + "\n" +
+		"\t" + hex.EncodeToString([]byte("HTTP/1.1 204 No Content\r\n\r\n")) + "\n" +
+		"===================================================================\n"
+	commands, err := ReadTsharkFollow(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("ReadTsharkFollow() error = %v", err)
+	}
+	want := `curl -X 'GET' 'https://example.com/cats'`
+	if len(commands) != 1 || commands[0].String() != want {
+		t.Errorf("Got:\n%v\nWant:\n%s", commands, want)
+	}
+
+	for _, bad := range []string{"Follow: tcp,ascii\n", "Follow: tcp,raw\nnot hex\n"} {
+		if _, err := ReadTsharkFollow(strings.NewReader(bad)); !errors.Is(err, ErrTsharkFormat) {
+			t.Errorf("ReadTsharkFollow(%q) error = %v, want %v", bad, err, ErrTsharkFormat)
+		}
+	}
+}
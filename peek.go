@@ -0,0 +1,53 @@
+package http2curl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// WithBodyPeek reads at most n bytes of the request body instead of draining
+// it, rendering a truncated -d and a comment with the total Content-Length.
+// The rest of the body is left unread, making the converter safe to use on
+// streaming uploads. It takes precedence over WithMaxBodySize.
+func WithBodyPeek(n int) CurlOption {
+	return func(c *CurlCommand) {
+		c.BodyPeek = n
+	}
+}
+
+// peekBody returns the first BodyPeek bytes of the request body and whether
+// the body is longer. The bytes read are put back in front of the rest of
+// the body restored as req.Body, which is left unread.
+func (c *CurlCommand) peekBody(req *http.Request) ([]byte, bool, error) {
+	if req.Body == http.NoBody {
+		return nil, false, nil
+	}
+	// Size the buffer for the body rather than for BodyPeek, which may be
+	// far larger
+	var buff bytes.Buffer
+	if req.ContentLength > 0 && req.ContentLength < int64(c.BodyPeek) {
+		buff.Grow(int(req.ContentLength) + 1)
+	}
+	_, err := buff.ReadFrom(io.LimitReader(req.Body, int64(c.BodyPeek)+1))
+	peeked := buff.Bytes()
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peeked), req.Body), req.Body}
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %w", ErrBodyRead, err)
+	}
+	c.bodyBytes = int64(len(peeked))
+	if len(peeked) <= c.BodyPeek {
+		return peeked, false, nil
+	}
+	c.bodyTruncated = true
+	total := "unknown"
+	if req.ContentLength > 0 {
+		total = fmt.Sprint(req.ContentLength)
+	}
+	c.Comments = append(c.Comments, fmt.Sprintf("body truncated to %d bytes, Content-Length: %s", c.BodyPeek, total))
+	return peeked[:c.BodyPeek], true, nil
+}
@@ -0,0 +1,92 @@
+package http2curl
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithBodyPeek(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		contentLength int64
+		opts          []CurlOption
+		wantCommand   string
+	}{
+		{
+			name:        "body within peek",
+			body:        "meow",
+			opts:        []CurlOption{WithBodyPeek(4)},
+			wantCommand: `curl -X 'POST' -d 'meow' 'http://example.com/cats'`,
+		},
+		{
+			name:          "truncated body with Content-Length",
+			body:          "meow meow",
+			contentLength: 9,
+			opts:          []CurlOption{WithBodyPeek(4)},
+			wantCommand: "# body truncated to 4 bytes, Content-Length: 9\n" +
+				`curl -X 'POST' -d 'meow' 'http://example.com/cats'`,
+		},
+		{
+			name: "truncated body of unknown length",
+			body: "meow meow",
+			opts: []CurlOption{WithBodyPeek(4)},
+			wantCommand: "# body truncated to 4 bytes, Content-Length: unknown\n" +
+				`curl -X 'POST' -d 'meow' 'http://example.com/cats'`,
+		},
+		{
+			name: "takes precedence over max body size",
+			body: "meow meow",
+			opts: []CurlOption{WithMaxBodySize(2), WithBodyPeek(4)},
+			wantCommand: "# body truncated to 4 bytes, Content-Length: unknown\n" +
+				`curl -X 'POST' -d 'meow' 'http://example.com/cats'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "http://example.com/cats", io.NopCloser(strings.NewReader(tt.body)))
+			req.ContentLength = tt.contentLength
+			command, err := GetCurlCommand(req, tt.opts...)
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.wantCommand)
+			}
+			if body, _ := io.ReadAll(req.Body); string(body) != tt.body {
+				t.Errorf("request body after GetCurlCommand() = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestWithBodyPeekStreaming(t *testing.T) {
+	pr, pw := io.Pipe()
+	req, _ := http.NewRequest("PUT", "http://example.com/upload", pr)
+	proceed := make(chan struct{})
+	go func() {
+		pw.Write([]byte("chunk one,"))
+		<-proceed
+		pw.Write([]byte("chunk two"))
+		pw.Close()
+	}()
+
+	// The rest of the upload is only written once the command is generated
+	command, err := GetCurlCommand(req, WithBodyPeek(5))
+	if err != nil {
+		t.Fatalf("GetCurlCommand() error = %v", err)
+	}
+	close(proceed)
+
+	want := "# body truncated to 5 bytes, Content-Length: unknown\n" +
+		`curl -X 'PUT' -d 'chunk' 'http://example.com/upload'`
+	if got := command.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+	if body, _ := io.ReadAll(req.Body); string(body) != "chunk one,chunk two" {
+		t.Errorf("request body after GetCurlCommand() = %q, want %q", body, "chunk one,chunk two")
+	}
+}
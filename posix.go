@@ -0,0 +1,43 @@
+package http2curl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithPOSIXPortability avoids bash-isms so commands run unmodified under
+// dash, busybox sh and zsh: bodies that would use echo -e or $'...'
+// quoting are piped from printf with octal escapes instead
+func WithPOSIXPortability() CurlOption {
+	return func(c *CurlCommand) {
+		c.POSIXPortable = true
+	}
+}
+
+// printfEscape returns s as a printf format printing s exactly, escaping
+// percent signs, backslashes, non-printable bytes and a leading dash,
+// which printf would take for an option
+func printfEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case i == 0 && c == '-':
+			b.WriteString(`\055`)
+		case c == '%':
+			b.WriteString("%%")
+		case c == '\\':
+			b.WriteString(`\\`)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c < 0x20 || c >= 0x7F:
+			fmt.Fprintf(&b, `\%03o`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package http2curl
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithPOSIXPortability(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		opts        []CurlOption
+		want        string
+	}{
+		{
+			name: "escaped newlines",
+			body: "50% off\n\\tabs\\",
+			opts: []CurlOption{WithEscapedNewlines()},
+			want: `printf '50%% off\n\\tabs\\' | curl -X 'POST' --data-binary @- 'http://example.com/cats'`,
+		},
+		{
+			name:        "binary body",
+			body:        "\x82\xa0'\x01",
+			contentType: "text/plain; charset=shift_jis",
+			opts:        []CurlOption{WithTranscodeBody()},
+			want:        `printf '\202\240'\''\001' | curl -X 'POST' --data-binary @- -H 'Content-Type: text/plain; charset=shift_jis' 'http://example.com/cats'`,
+		},
+		{
+			name: "inline body unchanged",
+			body: "meow",
+			want: `curl -X 'POST' -d 'meow' 'http://example.com/cats'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "http://example.com/cats", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			command, err := GetCurlCommand(req, append(tt.opts, WithPOSIXPortability())...)
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.want {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintfEscape(t *testing.T) {
+	want := `a%%b\\c\n\r\t\000\377`
+	if got := printfEscape("a%b\\c\n\r\t\x00\xff"); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
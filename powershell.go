@@ -0,0 +1,81 @@
+package http2curl
+
+import "strings"
+
+// powerShellLineBreak separates the flags of MultiLineString for PowerShell
+const powerShellLineBreak = " `\n  "
+
+// WithPowerShell renders commands for curl.exe run from PowerShell 7.3 or
+// later, which passes arguments to native programs as written: the program
+// is named curl.exe to bypass the curl alias of Invoke-WebRequest, words
+// are single-quoted, variables are environment variables referenced as
+// ${env:NAME} and, as with WithWindowsCurl, bodies that would be piped
+// through standard input, or contain newlines or binary bytes, are read
+// from DefaultBodyFile.
+func WithPowerShell() CurlOption {
+	return func(c *CurlCommand) {
+		c.PowerShell = true
+		c.PlaceholderSyntax = PlaceholderEnv
+	}
+}
+
+// windows reports whether the command is run by curl.exe on Windows
+func (c *CurlCommand) windows() bool {
+	return c.WindowsCurl || c.PowerShell
+}
+
+// program returns the name the curl program is run by
+func (c *CurlCommand) program() string {
+	if c.PowerShell {
+		return "curl.exe"
+	}
+	return "curl"
+}
+
+// writePowerShellQuoted writes str to b as a PowerShell word: single-quoted
+// unless it references variables, which only double-quoted strings expand
+func (c *CurlCommand) writePowerShellQuoted(b *strings.Builder, str string) {
+	if i, _ := c.nextPlaceholder(str); i < 0 || c.PlaceholderSyntax != PlaceholderEnv {
+		writePowerShellSingleQuoted(b, c.literal(str))
+		return
+	}
+	b.WriteByte('"')
+	for {
+		i, v := c.nextPlaceholder(str)
+		if i < 0 {
+			break
+		}
+		writePowerShellDoubleQuotedContent(b, c.literal(str[:i]))
+		b.WriteString(c.placeholder(v))
+		str = str[i+len(c.placeholder(v)):]
+	}
+	writePowerShellDoubleQuotedContent(b, c.literal(str))
+	b.WriteByte('"')
+}
+
+// writePowerShellSingleQuoted writes str to b as a single-quoted PowerShell
+// string, doubling the quotes, which include the typographic single quotes
+func writePowerShellSingleQuoted(b *strings.Builder, str string) {
+	b.WriteByte('\'')
+	for _, r := range str {
+		switch r {
+		case '\'', '‘', '’', '‚', '‛':
+			b.WriteRune(r)
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('\'')
+}
+
+// writePowerShellDoubleQuotedContent writes the content of a double-quoted
+// PowerShell string to b, escaping quotes, including the typographic double
+// quotes, dollar signs and backticks with a backtick
+func writePowerShellDoubleQuotedContent(b *strings.Builder, str string) {
+	for _, r := range str {
+		switch r {
+		case '"', '“', '”', '„', '$', '`':
+			b.WriteByte('`')
+		}
+		b.WriteRune(r)
+	}
+}
@@ -0,0 +1,81 @@
+package http2curl
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithPowerShell(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		header   string
+		opts     []CurlOption
+		want     string
+		fallback LengthFallback
+	}{
+		{
+			name:   "inline body",
+			body:   `{"note":"it's $5 ‘off’"}`,
+			header: "`cats`",
+			want:   "curl.exe -X 'POST' --data-raw '{\"note\":\"it''s $5 ‘‘off’’\"}' -H 'X-Path: `cats`' 'http://example.com/cats?a=1&b=2'",
+		},
+		{
+			name:     "multi-line body",
+			body:     "meow\nmeow",
+			want:     "# write the body to body.bin before running the command\n" + `curl.exe -X 'POST' --data-binary '@body.bin' 'http://example.com/cats?a=1&b=2'`,
+			fallback: BodyFileFallback,
+		},
+		{
+			name: "variables and inline comments",
+			body: "meow",
+			opts: []CurlOption{WithVariable("HOST", "example.com"), WithComment("cats"), WithInlineComments()},
+			want: "$env:HOST = 'example.com'\n" + `curl.exe -X 'POST' --data-raw 'meow' "http://${env:HOST}/cats?a=1&b=2" # cats`,
+		},
+		{
+			name:   "variables in double-quoted words",
+			body:   `{"host":"example.com","price":"$5"}`,
+			header: "`cats`",
+			opts:   []CurlOption{WithVariable("HOST", "example.com")},
+			want:   "$env:HOST = 'example.com'\ncurl.exe -X 'POST' --data-raw \"{`\"host`\":`\"${env:HOST}`\",`\"price`\":`\"`$5`\"}\" -H 'X-Path: `cats`' \"http://${env:HOST}/cats?a=1&b=2\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "http://example.com/cats?a=1&b=2", strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Set("X-Path", tt.header)
+			}
+			command, err := GetCurlCommand(req, append(tt.opts, WithPowerShell())...)
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.want {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.want)
+			}
+			if command.Fallback != tt.fallback {
+				t.Errorf("Fallback = %v, want %v", command.Fallback, tt.fallback)
+			}
+		})
+	}
+}
+
+func TestPowerShellMultiLineString(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	command, _ := GetCurlCommand(req, WithPowerShell())
+	want := "curl.exe `\n  -X 'GET' `\n  'http://example.com'"
+	if got := command.MultiLineString(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
+
+func TestPowerShellWithWindowsCurl(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	_, err := GetCurlCommand(req, WithPowerShell(), WithWindowsCurl())
+	if !errors.Is(err, ErrConflictingOptions) {
+		t.Errorf("GetCurlCommand() error = %v, want %v", err, ErrConflictingOptions)
+	}
+}
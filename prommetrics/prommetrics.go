@@ -0,0 +1,72 @@
+// Package prommetrics exports http2curl generation measurements as
+// Prometheus metrics.
+//
+//	metrics, err := prommetrics.New(prometheus.DefaultRegisterer)
+//	if err != nil {
+//		return err
+//	}
+//	handler := http2curl.Middleware(logFailure, http2curl.WithMetrics(metrics))
+package prommetrics
+
+import (
+	"github.com/chodges15/http2curl/v3"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics implements http2curl.Metrics with Prometheus collectors
+type Metrics struct {
+	commands   *prometheus.CounterVec
+	bodyBytes  prometheus.Histogram
+	duration   prometheus.Histogram
+	truncated  prometheus.Counter
+	redactions prometheus.Counter
+}
+
+// New returns Metrics whose collectors are registered with reg
+func New(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		commands: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http2curl_commands_total",
+			Help: "Curl commands generated, by result.",
+		}, []string{"result"}),
+		bodyBytes: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "http2curl_body_bytes",
+			Help:    "Request body bytes buffered to generate curl commands.",
+			Buckets: prometheus.ExponentialBuckets(64, 4, 10),
+		}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "http2curl_generation_duration_seconds",
+			Help:    "Time spent generating curl commands.",
+			Buckets: prometheus.ExponentialBuckets(1e-6, 4, 10),
+		}),
+		truncated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "http2curl_truncations_total",
+			Help: "Curl commands whose body or length exceeded the configured limits.",
+		}),
+		redactions: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "http2curl_redactions_total",
+			Help: "Header values redacted from curl commands.",
+		}),
+	}
+	for _, c := range []prometheus.Collector{m.commands, m.bodyBytes, m.duration, m.truncated, m.redactions} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// ObserveGeneration implements http2curl.Metrics
+func (m *Metrics) ObserveGeneration(measurement http2curl.Measurement) {
+	result := "ok"
+	if measurement.Err != nil {
+		result = "error"
+	}
+	m.commands.WithLabelValues(result).Inc()
+	m.bodyBytes.Observe(float64(measurement.BodyBytes))
+	m.duration.Observe(measurement.Duration.Seconds())
+	if measurement.Truncated {
+		m.truncated.Inc()
+	}
+	m.redactions.Add(float64(measurement.Redactions))
+}
@@ -0,0 +1,53 @@
+package prommetrics
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/chodges15/http2curl/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics, err := New(reg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for _, body := range []string{"meow", "meow meow meow"} {
+		req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		http2curl.GetCurlCommand(req,
+			http2curl.WithMetrics(metrics),
+			http2curl.WithMaxBodySize(8),
+			http2curl.WithRedactedHeaders("Authorization"))
+	}
+
+	want := `
+# HELP http2curl_commands_total Curl commands generated, by result.
+# TYPE http2curl_commands_total counter
+http2curl_commands_total{result="error"} 1
+http2curl_commands_total{result="ok"} 1
+# HELP http2curl_redactions_total Header values redacted from curl commands.
+# TYPE http2curl_redactions_total counter
+http2curl_redactions_total 1
+# HELP http2curl_truncations_total Curl commands whose body or length exceeded the configured limits.
+# TYPE http2curl_truncations_total counter
+http2curl_truncations_total 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"http2curl_commands_total", "http2curl_redactions_total", "http2curl_truncations_total"); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(metrics.duration); n != 1 {
+		t.Errorf("duration histograms = %d, want 1", n)
+	}
+
+	if _, err := New(reg); err == nil {
+		t.Error("New() with already registered collectors error = nil, want error")
+	}
+}
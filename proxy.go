@@ -0,0 +1,52 @@
+package http2curl
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+)
+
+// NewDebugProxy returns a reverse proxy forwarding requests to target and
+// writing the curl command of each forwarded request to sink, so any client
+// can be debugged without code changes. Commands address target directly
+// and are rendered as forwarded: with the Host and X-Forwarded-For headers
+// the proxy sends and without hop-by-hop headers. They are written by the
+// Transport of the proxy, which sends requests with http.DefaultTransport.
+func NewDebugProxy(target *url.URL, sink io.Writer, opts ...CurlOption) *httputil.ReverseProxy {
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.Transport = &debugTransport{base: http.DefaultTransport, converter: NewConverter(opts...), sink: sink}
+	return proxy
+}
+
+// debugTransport writes the command of each request to sink before sending
+// it with base, once the reverse proxy has rewritten it
+type debugTransport struct {
+	base      http.RoundTripper
+	converter *Converter
+	sink      io.Writer
+	mu        sync.Mutex
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	render := req
+	if req.Host != "" && req.Host != req.URL.Host {
+		render = req.Clone(req.Context())
+		render.Header.Set("Host", req.Host)
+	}
+	var out string
+	command, err := t.converter.Convert(render)
+	req.Body = render.Body // Restored by Convert
+	if err != nil {
+		out = fmt.Sprintf("# http2curl: %s %s: %v\n", req.Method, req.URL, err)
+	} else {
+		out = command.String() + "\n"
+	}
+	t.mu.Lock()
+	io.WriteString(t.sink, out)
+	t.mu.Unlock()
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,46 @@
+package http2curl
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestNewDebugProxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer backend.Close()
+	target, _ := url.Parse(backend.URL)
+
+	var log bytes.Buffer
+	proxy := httptest.NewServer(NewDebugProxy(target, &log, WithRedactedHeaders("X-Auth-Token")))
+	defer proxy.Close()
+
+	req, _ := http.NewRequest("POST", proxy.URL+"/cats?name=tom", bytes.NewBufferString("meow"))
+	req.Header.Set("X-Auth-Token", "private-token")
+	req.Header.Set("User-Agent", "test")
+	req.Header.Set("Connection", "X-Hop")
+	req.Header.Set("X-Hop", "1")
+	req.Header.Set("Keep-Alive", "timeout=5")
+	req.Header.Set("Proxy-Authorization", "Basic dG9tOmplcnJ5")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != "meow" {
+		t.Errorf("proxied body = %q, want %q", body, "meow")
+	}
+	proxyURL, _ := url.Parse(proxy.URL)
+	want := `curl -X 'POST' -d 'meow' -H 'Accept-Encoding: gzip' -H 'Host: ` + proxyURL.Host + `' -H 'User-Agent: test' -H 'X-Auth-Token: REDACTED' -H 'X-Forwarded-For: 127.0.0.1' '` + backend.URL + `/cats?name=tom'` + "\n"
+	if log.String() != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", log.String(), want)
+	}
+}
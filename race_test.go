@@ -0,0 +1,7 @@
+//go:build race
+
+package http2curl
+
+// raceEnabled reports whether tests run with the race detector, which adds
+// allocations of its own
+const raceEnabled = true
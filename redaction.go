@@ -0,0 +1,106 @@
+package http2curl
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RedactionKind is the part of the command a value was redacted from
+type RedactionKind string
+
+// Parts of the command values are redacted from
+const (
+	RedactedHeader  RedactionKind = "header"
+	RedactedTrailer RedactionKind = "trailer"
+	RedactedURL     RedactionKind = "url"
+	RedactedBody    RedactionKind = "body"
+	RedactedComment RedactionKind = "comment"
+)
+
+// Redaction records the values replaced with REDACTED in one place
+type Redaction struct {
+	Kind  RedactionKind `json:"kind"`
+	Name  string        `json:"name,omitempty"` // Header name, or JSON path in JSON bodies, e.g. $.card.number
+	Count int           `json:"count"`
+}
+
+// Redactions returns what was redacted while generating the command, so
+// audit tooling can verify scrubbing happened before a command is shared
+func (c *CurlCommand) Redactions() []Redaction {
+	return append([]Redaction(nil), c.redactions...)
+}
+
+// redactionCount returns the number of values redacted
+func (c *CurlCommand) redactionCount() int {
+	n := 0
+	for _, r := range c.redactions {
+		n += r.Count
+	}
+	return n
+}
+
+// recordRedaction adds count redacted values to the report
+func (c *CurlCommand) recordRedaction(kind RedactionKind, name string, count int) {
+	for i := range c.redactions {
+		if c.redactions[i].Kind == kind && c.redactions[i].Name == name {
+			c.redactions[i].Count += count
+			return
+		}
+	}
+	c.redactions = append(c.redactions, Redaction{Kind: kind, Name: name, Count: count})
+}
+
+var jsonIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// jsonPaths returns the paths of the values in the JSON document data
+// containing secret, or nil if data is not JSON
+func jsonPaths(data, secret string) []string {
+	if !json.Valid([]byte(data)) {
+		return nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil
+	}
+	var paths []string
+	var walk func(path string, v interface{})
+	walk = func(path string, v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				if strings.Contains(k, secret) {
+					paths = append(paths, path)
+				}
+				if jsonIdentifier.MatchString(k) {
+					walk(path+"."+k, v[k])
+				} else {
+					walk(path+"["+strconv.Quote(k)+"]", v[k])
+				}
+			}
+		case []interface{}:
+			for i, item := range v {
+				walk(path+"["+strconv.Itoa(i)+"]", item)
+			}
+		case string:
+			if strings.Contains(v, secret) {
+				paths = append(paths, path)
+			}
+		case json.Number:
+			if strings.Contains(v.String(), secret) {
+				paths = append(paths, path)
+			}
+		}
+	}
+	walk("$", doc)
+	return paths
+}
@@ -0,0 +1,544 @@
+package http2curl
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bodyMode is how the body is passed to curl
+type bodyMode int
+
+const (
+	bodyNone    bodyMode = iota
+	bodyInline           // -d 'body', newlines rendered as \n, or --json 'body' verbatim
+	bodyFields           // -d 'k=v' -d 'k2=v2', joined with & by curl
+	bodyEcho             // echo -e 'body' | curl -d @-, as rendered by v1
+	bodyStream           // printf '%s' 'body' | curl --data-binary @-
+	bodyBase64           // echo 'Ym9keQ==' | base64 --decode | curl --data-binary @-
+	bodyHeredoc          // curl --data-binary @- <<'EOF', see heredocNeedsTrim
+	bodyANSIC            // curl --data-binary $'body'
+	bodyPrintf           // printf 'body\n\001' | curl --data-binary @-
+	bodyFile             // curl --data-binary '@file'
+)
+
+// argStyle is how an argument is written on the command line
+type argStyle int
+
+const (
+	argRaw    argStyle = iota // As is, for flags
+	argQuoted                 // Single-quoted
+	argANSIC                  // $'...' quoted with hex escapes
+)
+
+// arg is a single curl argument
+type arg struct {
+	value string
+	style argStyle
+}
+
+func flagArg(flag string) arg {
+	return arg{value: flag, style: argRaw}
+}
+
+func quotedArg(value string) arg {
+	return arg{value: value, style: argQuoted}
+}
+
+// writeArg writes the argument as a shell word to b
+func (c *CurlCommand) writeArg(b *strings.Builder, a arg) {
+	switch a.style {
+	case argQuoted:
+		c.writeWord(b, a.value)
+	case argANSIC:
+		b.WriteString(ansiCEscape(a.value))
+	default:
+		b.WriteString(a.value)
+	}
+}
+
+// writeBashEscaped writes str single-quoted to b, like bashEscape
+func writeBashEscaped(b *strings.Builder, str string) {
+	b.WriteByte('\'')
+	for {
+		i := strings.IndexByte(str, '\'')
+		if i < 0 {
+			break
+		}
+		b.WriteString(str[:i])
+		b.WriteString(`'\''`)
+		str = str[i+1:]
+	}
+	b.WriteString(str)
+	b.WriteByte('\'')
+}
+
+// writeDoubleQuoted writes str double-quoted to b, escaping the characters
+// the shell still interprets inside double quotes
+func writeDoubleQuoted(b *strings.Builder, str string) {
+	b.WriteByte('"')
+	writeDoubleQuotedContent(b, str)
+	b.WriteByte('"')
+}
+
+func writeDoubleQuotedContent(b *strings.Builder, str string) {
+	for i := 0; i < len(str); i++ {
+		switch str[i] {
+		case '"', '$', '`', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(str[i])
+	}
+}
+
+// writeWord writes str as a single shell word to b, expanding the
+// placeholders of the command's variables
+func (c *CurlCommand) writeWord(b *strings.Builder, str string) {
+	switch {
+	case c.WindowsCurl:
+		c.writeCmdQuoted(b, str)
+	case c.PowerShell:
+		c.writePowerShellQuoted(b, str)
+	case len(c.Variables) > 0 && c.shellVariables():
+		c.writeWordWithVariables(b, str)
+	case c.DoubleQuotes:
+		writeDoubleQuoted(b, c.literal(str))
+	default:
+		writeBashEscaped(b, c.literal(str))
+	}
+}
+
+// String returns a ready to copy/paste command. Commands returned by
+// GetCurlCommand and Clone cache the result until they are modified.
+func (c *CurlCommand) String() string {
+	if c.cache == nil {
+		return c.render()
+	}
+	return c.cache.get(c)
+}
+
+// render renders the command on a single line
+func (c *CurlCommand) render() string {
+	return c.renderSeparated(" ")
+}
+
+// renderSeparated renders the command with sep between the flags
+func (c *CurlCommand) renderSeparated(sep string) string {
+	return c.renderPainted(sep, nil)
+}
+
+// renderPainted renders the command with sep between the flags, marking
+// its parts with p unless it is nil
+func (c *CurlCommand) renderPainted(sep string, p painter) string {
+	var b strings.Builder
+	b.Grow(c.estimatedLength())
+	if !c.InlineComments {
+		for _, comment := range c.Comments {
+			paintOpen(p, &b, partComment)
+			b.WriteString(c.commentPrefix())
+			b.WriteString(sanitizeComment(comment))
+			paintClose(p, &b, partComment)
+			b.WriteByte('\n')
+		}
+	}
+	c.writeVariables(&b)
+	c.writeTokens(&b, sep, p)
+	var delimiter string
+	stdin := c.stdinCommand()
+	if c.ConfigFile == "" && stdin.bodyMode() == bodyHeredoc {
+		delimiter = heredocDelimiter(stdin.Body.Data)
+		if !heredocNeedsTrim(stdin.Body.Data) {
+			b.WriteString(" <<'" + delimiter + "'")
+		}
+	}
+	if c.InlineComments && len(c.Comments) > 0 {
+		comments := make([]string, len(c.Comments))
+		for i, comment := range c.Comments {
+			comments[i] = sanitizeComment(comment)
+		}
+		separator := " "
+		if c.WindowsCurl {
+			separator = " & " // End the command before REM
+		}
+		b.WriteString(separator)
+		paintOpen(p, &b, partComment)
+		b.WriteString(c.commentPrefix() + strings.Join(comments, "; "))
+		paintClose(p, &b, partComment)
+	}
+	if delimiter != "" {
+		b.WriteByte('\n')
+		paintOpen(p, &b, partBody)
+		b.WriteString(stdin.Body.Data)
+		paintClose(p, &b, partBody)
+		if heredocNeedsTrim(stdin.Body.Data) {
+			b.WriteByte('\n')
+		}
+		b.WriteString(delimiter)
+	}
+	for _, comment := range c.FooterComments {
+		b.WriteByte('\n')
+		paintOpen(p, &b, partComment)
+		b.WriteString(c.commentPrefix())
+		b.WriteString(sanitizeComment(comment))
+		paintClose(p, &b, partComment)
+	}
+	return b.String()
+}
+
+// estimatedLength returns the approximate length of the rendered command,
+// used to size the output buffer in a single allocation
+func (c *CurlCommand) estimatedLength() int {
+	n := 32 + len(c.Method) + len(c.URL) + len(c.Proxy) + len(c.RequestTarget) + len(c.Cookies)
+	for _, h := range c.Headers {
+		n += len(h.Key) + len(h.Value) + 8
+	}
+	for _, flag := range c.Flags {
+		n += len(flag) + 1
+	}
+	for _, comment := range c.Comments {
+		n += len(comment) + 3
+	}
+	for _, comment := range c.FooterComments {
+		n += len(comment) + 3
+	}
+	for _, part := range c.FormParts {
+		n += len(part.Name) + len(part.Value) + len(part.File) + len(part.Filename) + len(part.ContentType) + 32
+	}
+	if c.Body != nil {
+		n += len(c.Body.Data) + 16
+	}
+	for _, next := range c.Next {
+		n += next.estimatedLength() + 8
+	}
+	return n
+}
+
+// sanitizeComment keeps a comment on a single line
+func sanitizeComment(comment string) string {
+	return stripControlChars(strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(comment))
+}
+
+// writeTokens writes the command as shell words to b, with sep before each
+// flag and a space between a flag and its parameter, marking its parts with
+// p unless it is nil
+func (c *CurlCommand) writeTokens(b *strings.Builder, sep string, p painter) {
+	if c.ConfigFile != "" {
+		b.WriteString("curl -K ")
+		c.writeWord(b, c.ConfigFile)
+		return
+	}
+
+	stdin := c.stdinCommand()
+	switch stdin.bodyMode() {
+	case bodyEcho:
+		b.WriteString("echo -e ")
+		paintOpen(p, b, partBody)
+		stdin.writeWord(b, strings.ReplaceAll(stdin.Body.Data, "\n", "\\n"))
+		paintClose(p, b, partBody)
+		b.WriteString(" | ")
+	case bodyStream:
+		b.WriteString("printf ")
+		stdin.writeWord(b, "%s")
+		b.WriteByte(' ')
+		paintOpen(p, b, partBody)
+		stdin.writeWord(b, stdin.Body.Data)
+		paintClose(p, b, partBody)
+		b.WriteString(" | ")
+	case bodyBase64:
+		paintOpen(p, b, partBody)
+		stdin.writeBase64Pipeline(b)
+		paintClose(p, b, partBody)
+	case bodyPrintf:
+		b.WriteString("printf ")
+		paintOpen(p, b, partBody)
+		stdin.writeWord(b, stdin.mapLiterals(stdin.Body.Data, stdin.Variables, func(text string) string {
+			return stdin.escapePlaceholders(printfEscape(stdin.literal(text)))
+		}))
+		paintClose(p, b, partBody)
+		b.WriteString(" | ")
+	case bodyHeredoc:
+		if heredocNeedsTrim(stdin.Body.Data) {
+			b.WriteString(`{ printf '%s' "$(cat)"; } <<'` + heredocDelimiter(stdin.Body.Data) + "' | ")
+		}
+	}
+	b.WriteString(c.program())
+	c.writeArgs(b, sep, p)
+	for _, next := range c.Next {
+		b.WriteString(sep)
+		paintOpen(p, b, partFlag)
+		b.WriteString("--next")
+		paintClose(p, b, partFlag)
+		next.writeArgs(b, sep, p)
+	}
+}
+
+// impliedPost reports whether -X is left out of a POST request, curl
+// sending the body with POST
+func (c *CurlCommand) impliedPost() bool {
+	return c.ImplicitPost && c.Method == http.MethodPost && (c.hasBody() || len(c.FormParts) > 0)
+}
+
+// writeArgs writes the arguments passed to curl to b, each preceded by sep
+// or, after a flag, by its parameter's space, marking them with p unless it
+// is nil
+func (c *CurlCommand) writeArgs(b *strings.Builder, sep string, p painter) {
+	args := c.args()
+	for i, a := range args {
+		if i > 0 && takesParameter(args[i-1]) {
+			b.WriteByte(' ')
+		} else {
+			b.WriteString(sep)
+		}
+		if p == nil {
+			c.writeArg(b, a)
+			continue
+		}
+		c.writePaintedArg(b, args, i, p)
+	}
+}
+
+// args returns the arguments passed to curl
+func (c *CurlCommand) args() []arg {
+	args := make([]arg, 0, 12+2*len(c.Headers)+2*len(c.SuppressHeaders)+len(c.Flags))
+	var ends [flagClassCount]int
+	if c.InsecureSkipVerify && strings.HasPrefix(c.URL, "https://") {
+		args = append(args, flagArg("-k"))
+	}
+	args = c.tlsArgs(args)
+	ends[FlagClassTLS] = len(args)
+	if !c.compatV1() && hasIPv6Host(c.URL) {
+		args = append(args, flagArg("-g")) // Keep curl from globbing the brackets
+	}
+	if c.ExactURL && hasDotSegments(c.URL) {
+		args = append(args, flagArg("--path-as-is"))
+	}
+	if c.ProxyTunnel {
+		args = append(args, flagArg("-p"))
+	}
+	if c.Proxy != "" {
+		args = append(args, flagArg("-x"), quotedArg(c.Proxy))
+	}
+	if c.SocksProxy != "" {
+		args = append(args, flagArg(socksFlags[c.SocksVersion]), quotedArg(c.SocksProxy))
+	}
+	if c.HTTPVersion != "" {
+		args = append(args, flagArg(c.HTTPVersion))
+	}
+	for _, entry := range c.Resolve {
+		args = append(args, flagArg("--resolve"), quotedArg(entry))
+	}
+	if c.ConnectTimeout > 0 {
+		args = append(args, flagArg("--connect-timeout"), flagArg(formatSeconds(c.ConnectTimeout)))
+	}
+	if c.MaxTime > 0 {
+		args = append(args, flagArg("--max-time"), flagArg(formatSeconds(c.MaxTime)))
+	}
+	ends[FlagClassTransport] = len(args)
+	if (!c.ProxyTunnel || c.Method != http.MethodConnect) && !c.impliedPost() {
+		requestFlag := "-X"
+		if c.LongRequestFlag {
+			requestFlag = "--request"
+		}
+		args = append(args, flagArg(requestFlag), quotedArg(c.Method))
+	}
+	if c.RequestTarget != "" {
+		args = append(args, flagArg("--request-target"), quotedArg(c.RequestTarget))
+	}
+	ends[FlagClassMethod] = len(args)
+
+	dataFlag := "-d"
+	if c.CurlJSON {
+		dataFlag = "--json"
+	} else if c.windows() {
+		dataFlag = "--data-raw" // Keep curl.exe from reading a leading @ as a file
+	}
+	binaryFlag := dataFlag
+	if !c.CurlJSON {
+		binaryFlag = "--data-binary" // Keep the newlines that -d would strip
+	}
+	switch c.bodyMode() {
+	case bodyInline:
+		data := c.Body.Data
+		if !c.CurlJSON {
+			data = strings.ReplaceAll(data, "\n", "\\n")
+		}
+		args = append(args, flagArg(dataFlag), quotedArg(data))
+	case bodyFields:
+		for _, field := range strings.Split(c.Body.Data, "&") {
+			args = append(args, flagArg(dataFlag), quotedArg(field))
+		}
+	case bodyEcho:
+		args = append(args, flagArg(dataFlag), flagArg("@-")) // Read from standard input
+	case bodyHeredoc, bodyStream, bodyBase64, bodyPrintf:
+		args = append(args, flagArg(binaryFlag), flagArg("@-"))
+	case bodyANSIC:
+		args = append(args, flagArg(binaryFlag), arg{value: c.Body.Data, style: argANSIC})
+	case bodyFile:
+		args = append(args, flagArg(binaryFlag), quotedArg("@"+c.BodyFile))
+	}
+	for _, part := range c.FormParts {
+		flag, value := formArg(part)
+		args = append(args, flagArg(flag), quotedArg(value))
+	}
+	ends[FlagClassData] = len(args)
+
+	for _, h := range c.Headers {
+		if c.CurlJSON && c.hasBody() && isJSONDefaultHeader(h) {
+			continue // Implied by --json
+		}
+		args = append(args, flagArg("-H"), quotedArg(stripControlChars(c.headerLine(h))))
+	}
+	for _, key := range c.SuppressHeaders {
+		if c.hasHeader(key) {
+			continue // Already replaced by the request's own value
+		}
+		args = append(args, flagArg("-H"), quotedArg(stripControlChars(key+":")))
+	}
+	if c.ContentLength && c.hasBody() {
+		args = append(args, flagArg("-H"), quotedArg(fmt.Sprintf("Content-Length: %d", c.sentBodyLength())))
+	}
+	if c.Cookies != "" {
+		args = append(args, flagArg("-b"), quotedArg(stripControlChars(c.Cookies)))
+	}
+	if c.CookieFile != "" {
+		args = append(args, flagArg("-b"), quotedArg(c.CookieFile))
+	}
+	if c.CookieJarFile != "" {
+		args = append(args, flagArg("-c"), quotedArg(c.CookieJarFile))
+	}
+	ends[FlagClassHeaders] = len(args)
+
+	args = append(args, quotedArg(c.URL))
+	ends[FlagClassURL] = len(args)
+
+	if c.EnableCompression {
+		args = append(args, flagArg("--compressed"))
+	}
+	if c.FailQuietly {
+		args = append(args, flagArg("-fsS"))
+	}
+	if c.OutputFile != "" {
+		args = append(args, flagArg("-o"), quotedArg(c.OutputFile))
+	}
+	if c.WriteOut != "" {
+		args = append(args, flagArg("-w"), quotedArg(c.WriteOut))
+	}
+	if c.TraceFile != "" {
+		traceFlag := "--trace"
+		if c.TraceASCII {
+			traceFlag = "--trace-ascii"
+		}
+		args = append(args, flagArg(traceFlag), quotedArg(c.TraceFile), flagArg("--trace-time"))
+	}
+	for _, flag := range c.Flags {
+		args = append(args, flagArg(flag))
+	}
+	ends[FlagClassExtra] = len(args)
+	return c.orderArgs(args, ends)
+}
+
+// headerLine returns the -H parameter for h. curl drops headers with an
+// empty value, so those are sent with its "Key;" syntax instead.
+func (c *CurlCommand) headerLine(h Header) string {
+	if strings.TrimSpace(h.Value) == "" && !c.compatV1() {
+		return h.Key + ";"
+	}
+	return h.Key + ": " + h.Value
+}
+
+// sentBodyLength returns the number of body bytes curl sends for the
+// rendered command
+func (c *CurlCommand) sentBodyLength() int {
+	switch c.bodyMode() {
+	case bodyANSIC, bodyFile, bodyStream, bodyBase64, bodyFields, bodyPrintf, bodyHeredoc:
+		return len(c.Body.Data)
+	case bodyEcho:
+		// echo -e restores the newlines, which curl strips when reading @-
+		return len(strings.NewReplacer("\r", "", "\n", "").Replace(c.Body.Data))
+	default:
+		return len(strings.ReplaceAll(c.Body.Data, "\n", "\\n"))
+	}
+}
+
+// formatSeconds formats d as decimal seconds, e.g. 2.5 or 0.25
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+// hasIPv6Host reports whether rawURL has a bracketed IPv6 literal host
+func hasIPv6Host(rawURL string) bool {
+	_, rest, found := strings.Cut(rawURL, "://")
+	return found && strings.HasPrefix(rest, "[")
+}
+
+// hasDotSegments reports whether the path of rawURL contains "." or ".."
+// segments, which curl would otherwise squash
+func hasDotSegments(rawURL string) bool {
+	_, rest, _ := strings.Cut(rawURL, "://")
+	path := rest[strings.IndexAny(rest+"/", "/"):]
+	path, _, _ = strings.Cut(path, "?")
+	path, _, _ = strings.Cut(path, "#")
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "." || segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CurlCommand) bodyMode() bodyMode {
+	switch {
+	case c.BodyFile != "":
+		return bodyFile
+	case !c.hasBody():
+		return bodyNone
+	case c.Body.Base64:
+		return bodyBase64
+	case c.Body.HexEscaped && c.POSIXPortable:
+		return bodyPrintf
+	case c.Body.HexEscaped:
+		return bodyANSIC
+	case c.Body.Chunked:
+		return bodyStream
+	case c.HeredocBody:
+		return bodyHeredoc
+	case c.EscapedNewlines && (c.POSIXPortable || !c.compatV1()):
+		return bodyPrintf
+	case c.EscapedNewlines:
+		return bodyEcho
+	case c.Body.Fields:
+		return bodyFields
+	default:
+		return bodyInline
+	}
+}
+
+func (c *CurlCommand) hasBody() bool {
+	return c.Body != nil && c.Body.Data != ""
+}
+
+// isJSONDefaultHeader reports whether the header is one curl sets itself
+// when passing the body with --json
+func isJSONDefaultHeader(h Header) bool {
+	return (strings.EqualFold(h.Key, "Content-Type") || strings.EqualFold(h.Key, "Accept")) &&
+		strings.EqualFold(h.Value, "application/json")
+}
+
+// heredocNeedsTrim reports whether the here-document of body ends with a
+// newline body does not have, in which case it is piped through
+// printf '%s' "$(cat)", the command substitution removing that newline
+func heredocNeedsTrim(body string) bool {
+	return !strings.HasSuffix(body, "\n")
+}
+
+// heredocDelimiter returns a here-document delimiter which does not appear
+// as a line of the body
+func heredocDelimiter(body string) string {
+	delimiter := "EOF"
+	for i := 0; strings.Contains("\n"+body+"\n", "\n"+delimiter+"\n"); i++ {
+		delimiter = fmt.Sprintf("EOF%d", i)
+	}
+	return delimiter
+}
@@ -0,0 +1,83 @@
+// Package reqhook attaches the curl command of every request sent by an
+// imroc/req client to the request, for use in logging and error reports.
+//
+//	client := req.C()
+//	reqhook.Register(client, http2curl.WithRedactedHeaders("Authorization"))
+//	client.OnAfterResponse(func(c *req.Client, resp *req.Response) error {
+//		if resp.IsErrorState() {
+//			log.Printf("%s, replay with: %s", resp.Status, reqhook.Command(resp.Request))
+//		}
+//		return nil
+//	})
+package reqhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/chodges15/http2curl/v3"
+	"github.com/imroc/req/v3"
+)
+
+type contextKey struct{}
+
+// Register wraps the round trip of client to generate the curl command of
+// each request right before it is sent
+func Register(client *req.Client, opts ...http2curl.CurlOption) {
+	client.WrapRoundTripFunc(RoundTripWrapper(opts...))
+}
+
+// RoundTripWrapper returns the middleware installed by Register
+func RoundTripWrapper(opts ...http2curl.CurlOption) req.RoundTripWrapperFunc {
+	converter := http2curl.NewConverter(opts...)
+	return func(rt req.RoundTripper) req.RoundTripFunc {
+		return func(r *req.Request) (*req.Response, error) {
+			// Logging must never fail the request
+			if command, err := convert(converter, r); err == nil {
+				r.SetContext(context.WithValue(r.Context(), contextKey{}, command))
+			}
+			return rt.RoundTrip(r)
+		}
+	}
+}
+
+// convert generates the command from a copy of r, leaving its body untouched
+// for the actual round trip
+func convert(converter *http2curl.Converter, r *req.Request) (*http2curl.CurlCommand, error) {
+	var body io.Reader
+	switch {
+	case r.GetBody != nil:
+		rc, err := r.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		body = rc
+	case len(r.Body) > 0:
+		body = bytes.NewReader(r.Body)
+	}
+	raw, err := http.NewRequestWithContext(r.Context(), r.Method, r.URL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	raw.Header = r.Headers.Clone()
+	if raw.Header == nil {
+		raw.Header = http.Header{}
+	}
+	for _, cookie := range r.Cookies {
+		raw.AddCookie(cookie)
+	}
+	return converter.Convert(raw)
+}
+
+// Command returns the curl command generated for r, or nil if none was
+// generated
+func Command(r *req.Request) *http2curl.CurlCommand {
+	if r == nil {
+		return nil
+	}
+	command, _ := r.Context().Value(contextKey{}).(*http2curl.CurlCommand)
+	return command
+}
@@ -0,0 +1,39 @@
+package reqhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chodges15/http2curl/v3"
+	"github.com/imroc/req/v3"
+)
+
+func TestRegister(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := req.C().SetUserAgent("test")
+	Register(client, http2curl.WithRedactedHeaders("Authorization"))
+
+	resp, err := client.R().
+		SetHeader("Authorization", "Bearer secret").
+		SetHeader("Content-Type", "text/plain").
+		SetBodyString("meow").
+		Post(server.URL + "/cats")
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if resp.String() != "meow" {
+		t.Errorf("sent body = %q, want %q", resp.String(), "meow")
+	}
+
+	want := `curl -X 'POST' -d 'meow' -H 'Authorization: REDACTED' -H 'Content-Type: text/plain' -H 'User-Agent: test' '` + server.URL + `/cats'`
+	if got := Command(resp.Request); got == nil || got.String() != want {
+		t.Errorf("Got:\n%v\nWant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,85 @@
+package http2curl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// HostResolver looks up the addresses of a host, as *net.Resolver does
+type HostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// StaticResolver is a HostResolver answering with fixed addresses per host,
+// e.g. the remote addresses recorded with httptrace.GotConnInfo. Hosts it
+// has no addresses for are not pinned.
+type StaticResolver map[string][]string
+
+// LookupHost implements HostResolver
+func (r StaticResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	for h, addrs := range r {
+		if strings.EqualFold(h, host) {
+			return addrs, nil
+		}
+	}
+	return nil, nil
+}
+
+// WithDNSCacheBypass pins the host of the request to the addresses r returns
+// for it with --resolve, so replays reach the backend instances the request
+// did rather than whatever DNS answers later. Pass the net.Resolver the
+// client dials with, or a StaticResolver of the addresses it connected to.
+// GetFollowRedirectsCommand pins every host of the redirect chain. IP
+// literal hosts are left alone.
+func WithDNSCacheBypass(r HostResolver) CurlOption {
+	return func(c *CurlCommand) {
+		c.resolver = r
+	}
+}
+
+// addResolve adds the --resolve entry of the host of rawURL, unless the
+// command already has one for its host and port
+func (c *CurlCommand) addResolve(ctx context.Context, rawURL string) error {
+	if c.resolver == nil {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("url parse error: %w", err)
+	}
+	host, port := u.Hostname(), u.Port()
+	if host == "" || net.ParseIP(host) != nil {
+		return nil
+	}
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	prefix := host + ":" + port + ":"
+	for _, entry := range c.Resolve {
+		if strings.HasPrefix(strings.ToLower(entry), strings.ToLower(prefix)) {
+			return nil
+		}
+	}
+	addrs, err := c.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return fmt.Errorf("resolve error: %w", err)
+	}
+	if len(addrs) == 0 {
+		return nil
+	}
+	pinned := make([]string, len(addrs))
+	for i, addr := range addrs {
+		if strings.Contains(addr, ":") {
+			addr = "[" + addr + "]"
+		}
+		pinned[i] = addr
+	}
+	c.Resolve = append(c.Resolve, prefix+strings.Join(pinned, ","))
+	return nil
+}
@@ -0,0 +1,103 @@
+package http2curl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+type resolverFunc func(ctx context.Context, host string) ([]string, error)
+
+func (f resolverFunc) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return f(ctx, host)
+}
+
+func TestWithDNSCacheBypass(t *testing.T) {
+	resolver := StaticResolver{
+		"example.com":     {"203.0.113.7"},
+		"api.example.com": {"2001:db8::1", "203.0.113.8"},
+	}
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "https",
+			url:  "https://Example.com/cats",
+			want: `curl --resolve 'Example.com:443:203.0.113.7' -X 'GET' 'https://Example.com/cats'`,
+		},
+		{
+			name: "port and several addresses",
+			url:  "http://api.example.com:8080/cats",
+			want: `curl --resolve 'api.example.com:8080:[2001:db8::1],203.0.113.8' -X 'GET' 'http://api.example.com:8080/cats'`,
+		},
+		{
+			name: "unknown host",
+			url:  "http://other.example.com/cats",
+			want: `curl -X 'GET' 'http://other.example.com/cats'`,
+		},
+		{
+			name: "ip literal",
+			url:  "http://203.0.113.9/cats",
+			want: `curl -X 'GET' 'http://203.0.113.9/cats'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			command, err := GetCurlCommand(req, WithDNSCacheBypass(resolver))
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.want {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithDNSCacheBypassError(t *testing.T) {
+	errLookup := errors.New("no such host")
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/cats", nil)
+	_, err := GetCurlCommand(req, WithDNSCacheBypass(resolverFunc(func(context.Context, string) ([]string, error) {
+		return nil, errLookup
+	})))
+	if !errors.Is(err, errLookup) {
+		t.Errorf("GetCurlCommand() error = %v, want %v", err, errLookup)
+	}
+}
+
+func TestWithDNSCacheBypassRedirectChain(t *testing.T) {
+	first, _ := http.NewRequest(http.MethodGet, "https://example.com/old", nil)
+	redirect := &http.Response{
+		Status:  "302 Found",
+		Header:  http.Header{"Location": {"https://api.example.com/new"}},
+		Request: first,
+	}
+	second, _ := http.NewRequest(http.MethodGet, "https://api.example.com/new", nil)
+	second.Response = redirect
+	third, _ := http.NewRequest(http.MethodGet, "https://example.com/newer", nil)
+	third.Response = &http.Response{Status: "302 Found", Header: http.Header{"Location": {third.URL.String()}}, Request: second}
+	resp := &http.Response{Status: "200 OK", Request: third}
+
+	command, err := GetFollowRedirectsCommand(resp, WithDNSCacheBypass(StaticResolver{
+		"example.com":     {"203.0.113.7"},
+		"api.example.com": {"203.0.113.8"},
+	}))
+	if err != nil {
+		t.Fatalf("GetFollowRedirectsCommand() error = %v", err)
+	}
+	want := []string{"example.com:443:203.0.113.7", "api.example.com:443:203.0.113.8"}
+	if len(command.Resolve) != len(want) || command.Resolve[0] != want[0] || command.Resolve[1] != want[1] {
+		t.Errorf("Resolve = %q, want %q", command.Resolve, want)
+	}
+	if clone := command.Clone(); !clone.Equal(command) || clone.Hash() != command.Hash() {
+		t.Errorf("Clone() differs from the command")
+	}
+	if other := command.Derive(func(c *CurlCommand) { c.Resolve = c.Resolve[:1] }); other.Equal(command) {
+		t.Errorf("Equal() ignores Resolve")
+	}
+}
@@ -0,0 +1,118 @@
+package http2curl
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// GetCurlCommandFromResponse generates the curl command for the request that
+// produced resp, which is the last request of any redirect chain
+func GetCurlCommandFromResponse(resp *http.Response, opts ...CurlOption) (*CurlCommand, error) {
+	if resp == nil || resp.Request == nil {
+		return nil, ErrNoRequest
+	}
+	return GetCurlCommand(replayableRequest(resp.Request), opts...)
+}
+
+// GetRedirectChainCommands generates one curl command per request of the
+// redirect chain that led to resp, starting with the original request. Each
+// command notes the response its request received in a comment
+func GetRedirectChainCommands(resp *http.Response, opts ...CurlOption) ([]*CurlCommand, error) {
+	chain := redirectChain(resp)
+	if len(chain) == 0 {
+		return nil, ErrNoRequest
+	}
+	commands := make([]*CurlCommand, 0, len(chain))
+	for i, req := range chain {
+		// Comment through an annotator so the secret scanners see the hop
+		hop := fmt.Sprintf("hop %d/%d: %s", i+1, len(chain), hopResult(chain, i, resp))
+		command, err := GetCurlCommand(replayableRequest(req), append(opts[:len(opts):len(opts)], withComment(hop))...)
+		if err != nil {
+			for _, c := range commands {
+				c.RemoveTempFiles()
+			}
+			return nil, fmt.Errorf("redirect %d: %w", i, err)
+		}
+		commands = append(commands, command)
+	}
+	return commands, nil
+}
+
+// GetFollowRedirectsCommand generates a single curl command for the original
+// request of the redirect chain that led to resp, following redirects with -L
+// and listing every hop observed by the Go client in comments. POST requests
+// with a body leave out -X POST: curl then switches to GET on 301, 302 and
+// 303 redirects and keeps POST on 307 and 308 like the Go client, where
+// -X POST would keep POST throughout. Other methods are kept by curl across
+// every redirect.
+func GetFollowRedirectsCommand(resp *http.Response, opts ...CurlOption) (*CurlCommand, error) {
+	chain := redirectChain(resp)
+	if len(chain) == 0 {
+		return nil, ErrNoRequest
+	}
+	// Comment through annotators so the secret scanners see the hops
+	opts = opts[:len(opts):len(opts)]
+	for i, req := range chain {
+		opts = append(opts, withComment(
+			fmt.Sprintf("hop %d/%d: %s %s: %s", i+1, len(chain), req.Method, req.URL, hopResult(chain, i, resp))))
+	}
+	command, err := GetCurlCommand(replayableRequest(chain[0]), opts...)
+	if err != nil {
+		return nil, err
+	}
+	command.ImplicitPost = true
+	command.AddFlag("-L")
+	for _, req := range chain[1:] {
+		if err := command.addResolve(req.Context(), req.URL.String()); err != nil {
+			return nil, err
+		}
+	}
+	return command, nil
+}
+
+// withComment adds comment while the command is generated, before secrets
+// are scanned
+func withComment(comment string) CurlOption {
+	return withAnnotator(func(*http.Request) string { return comment })
+}
+
+// redirectChain returns the requests that led to resp, in the order they
+// were sent
+func redirectChain(resp *http.Response) []*http.Request {
+	var chain []*http.Request
+	for resp != nil && resp.Request != nil {
+		chain = append([]*http.Request{resp.Request}, chain...)
+		resp = resp.Request.Response
+	}
+	return chain
+}
+
+// hopResult describes the response received by the i-th request of chain,
+// including the redirect target for redirects
+func hopResult(chain []*http.Request, i int, resp *http.Response) string {
+	if i+1 < len(chain) {
+		resp = chain[i+1].Response
+	}
+	if resp == nil {
+		return "no response"
+	}
+	if location := resp.Header.Get("Location"); location != "" {
+		return resp.Status + " -> " + location
+	}
+	return resp.Status
+}
+
+// replayableRequest returns req with a fresh body when the transport has
+// already consumed the original one
+func replayableRequest(req *http.Request) *http.Request {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return req
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return req
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone
+}
@@ -0,0 +1,157 @@
+package http2curl
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newRedirectServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/moved", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetCurlCommandFromResponse(t *testing.T) {
+	server := newRedirectServer(t)
+
+	req, _ := http.NewRequest("POST", server.URL+"/old", bytes.NewBufferString("data"))
+	req.Header.Set("X-Auth-Token", "private-token")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	command, err := GetCurlCommandFromResponse(resp)
+	if err != nil {
+		t.Fatalf("GetCurlCommandFromResponse() error = %v", err)
+	}
+	want := `curl -X 'GET' -H 'Referer: ` + server.URL + `/moved' -H 'X-Auth-Token: private-token' '` + server.URL + `/new'`
+	if command.String() != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", command.String(), want)
+	}
+
+	commands, err := GetRedirectChainCommands(resp)
+	if err != nil {
+		t.Fatalf("GetRedirectChainCommands() error = %v", err)
+	}
+	wantChain := []string{
+		"# hop 1/3: 307 Temporary Redirect -> /moved\n" +
+			`curl -X 'POST' -d 'data' -H 'X-Auth-Token: private-token' '` + server.URL + `/old'`,
+		"# hop 2/3: 302 Found -> /new\n" +
+			`curl -X 'POST' -d 'data' -H 'Referer: ` + server.URL + `/old' -H 'X-Auth-Token: private-token' '` + server.URL + `/moved'`,
+		"# hop 3/3: 200 OK\n" + want,
+	}
+	if len(commands) != len(wantChain) {
+		t.Fatalf("GetRedirectChainCommands() returned %d commands, want %d", len(commands), len(wantChain))
+	}
+	for i, command := range commands {
+		if command.String() != wantChain[i] {
+			t.Errorf("command %d Got:\n%s\nWant:\n%s", i, command.String(), wantChain[i])
+		}
+	}
+}
+
+func TestGetFollowRedirectsCommand(t *testing.T) {
+	server := newRedirectServer(t)
+
+	resp, err := http.Get(server.URL + "/old")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	command, err := GetFollowRedirectsCommand(resp)
+	if err != nil {
+		t.Fatalf("GetFollowRedirectsCommand() error = %v", err)
+	}
+	want := "# hop 1/3: GET " + server.URL + "/old: 307 Temporary Redirect -> /moved\n" +
+		"# hop 2/3: GET " + server.URL + "/moved: 302 Found -> /new\n" +
+		"# hop 3/3: GET " + server.URL + "/new: 200 OK\n" +
+		`curl -X 'GET' '` + server.URL + `/old' -L`
+	if command.String() != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", command.String(), want)
+	}
+}
+
+func TestGetFollowRedirectsCommandPost(t *testing.T) {
+	server := newRedirectServer(t)
+
+	resp, err := http.Post(server.URL+"/old", "text/plain", bytes.NewBufferString("data"))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+
+	command, err := GetFollowRedirectsCommand(resp)
+	if err != nil {
+		t.Fatalf("GetFollowRedirectsCommand() error = %v", err)
+	}
+	want := "# hop 1/3: POST " + server.URL + "/old: 307 Temporary Redirect -> /moved\n" +
+		"# hop 2/3: POST " + server.URL + "/moved: 302 Found -> /new\n" +
+		"# hop 3/3: GET " + server.URL + "/new: 200 OK\n" +
+		`curl -d 'data' -H 'Content-Type: text/plain' '` + server.URL + `/old' -L`
+	if command.String() != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", command.String(), want)
+	}
+	wantConfig := "data = \"data\"\nheader = \"Content-Type: text/plain\"\nurl = \"" + server.URL + "/old\"\nlocation\n"
+	if got := command.ConfigFileContents(); got != wantConfig {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, wantConfig)
+	}
+}
+
+func TestRedirectCommentsScanned(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/callback?code="+testJWT, http.StatusFound)
+	})
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/login")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	follow, err := GetFollowRedirectsCommand(resp, WithSecretScanner(HeuristicScanner))
+	if err != nil {
+		t.Fatalf("GetFollowRedirectsCommand() error = %v", err)
+	}
+	chain, err := GetRedirectChainCommands(resp, ForCompliance())
+	if err != nil {
+		t.Fatalf("GetRedirectChainCommands() error = %v", err)
+	}
+	for _, command := range append(chain, follow) {
+		if got := command.String(); strings.Contains(got, testJWT) || !strings.Contains(got, "code=REDACTED") {
+			t.Errorf("token in redirect comments not redacted:\n%s", got)
+		}
+	}
+}
+
+func TestGetCurlCommandFromResponseWithoutRequest(t *testing.T) {
+	if _, err := GetCurlCommandFromResponse(&http.Response{}); !errors.Is(err, ErrNoRequest) {
+		t.Errorf("GetCurlCommandFromResponse() error = %v, want %v", err, ErrNoRequest)
+	}
+	if _, err := GetRedirectChainCommands(nil); !errors.Is(err, ErrNoRequest) {
+		t.Errorf("GetRedirectChainCommands() error = %v, want %v", err, ErrNoRequest)
+	}
+	if _, err := GetFollowRedirectsCommand(nil); !errors.Is(err, ErrNoRequest) {
+		t.Errorf("GetFollowRedirectsCommand() error = %v, want %v", err, ErrNoRequest)
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/chodges15/http2curl/v3/restyhook
+
+go 1.20
+
+require (
+	github.com/chodges15/http2curl/v3 v3.0.0-00010101000000-000000000000
+	github.com/go-resty/resty/v2 v2.7.0
+)
+
+replace github.com/chodges15/http2curl/v3 => ../
+
+require golang.org/x/net v0.17.0 // indirect
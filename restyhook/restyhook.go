@@ -0,0 +1,53 @@
+// Package restyhook attaches the curl command of every request sent by a
+// go-resty client to the request, for use in logging and error reports.
+//
+//	client := resty.New()
+//	restyhook.Register(client, http2curl.WithRedactedHeaders("Authorization"))
+//	client.OnError(func(req *resty.Request, err error) {
+//		log.Printf("%v, replay with: %s", err, restyhook.Command(req))
+//	})
+package restyhook
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/chodges15/http2curl/v3"
+	"github.com/go-resty/resty/v2"
+)
+
+type contextKey struct{}
+
+// Register sets a pre-request hook on client generating the curl command of
+// each request right before it is sent. Resty supports a single pre-request
+// hook, so any existing one is replaced.
+func Register(client *resty.Client, opts ...http2curl.CurlOption) {
+	client.SetPreRequestHook(PreRequestHook(opts...))
+}
+
+// PreRequestHook returns the hook installed by Register, for clients which
+// already use a pre-request hook and call it from their own
+func PreRequestHook(opts ...http2curl.CurlOption) resty.PreRequestHook {
+	converter := http2curl.NewConverter(opts...)
+	return func(_ *resty.Client, req *http.Request) error {
+		// The request body is restored by the converter; resty wraps it
+		// afterwards, so buffers it returns to its pool are not shared.
+		command, err := converter.Convert(req)
+		if err != nil {
+			// Logging must never fail the request
+			return nil
+		}
+		*req = *req.WithContext(context.WithValue(req.Context(), contextKey{}, command))
+		return nil
+	}
+}
+
+// Command returns the curl command generated for req, or nil if none was
+// generated
+func Command(req *resty.Request) *http2curl.CurlCommand {
+	if req == nil || req.RawRequest == nil {
+		return nil
+	}
+	command, _ := req.RawRequest.Context().Value(contextKey{}).(*http2curl.CurlCommand)
+	return command
+}
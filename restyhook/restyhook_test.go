@@ -0,0 +1,45 @@
+package restyhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chodges15/http2curl/v3"
+	"github.com/go-resty/resty/v2"
+)
+
+func TestRegister(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := resty.New()
+	Register(client, http2curl.WithRedactedHeaders("Authorization"))
+
+	var logged *http2curl.CurlCommand
+	client.OnAfterResponse(func(_ *resty.Client, resp *resty.Response) error {
+		logged = Command(resp.Request)
+		return nil
+	})
+
+	resp, err := client.R().
+		SetHeader("Authorization", "Bearer secret").
+		SetHeader("Content-Type", "text/plain").
+		SetBody("meow").
+		Post(server.URL + "/cats")
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if resp.String() != "meow" {
+		t.Errorf("sent body = %q, want %q", resp.String(), "meow")
+	}
+
+	want := `curl -X 'POST' -d 'meow' -H 'Authorization: REDACTED' -H 'Content-Type: text/plain' -H 'User-Agent: go-resty/2.7.0 (https://github.com/go-resty/resty)' '` + server.URL + `/cats'`
+	if logged == nil || logged.String() != want {
+		t.Errorf("Got:\n%v\nWant:\n%s", logged, want)
+	}
+}
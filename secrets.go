@@ -0,0 +1,125 @@
+package http2curl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SecretScanner finds secrets in the parts of a command about to be rendered
+type SecretScanner interface {
+	// FindSecrets returns the secrets found in s, each a substring of s
+	FindSecrets(s string) []string
+}
+
+// SecretScannerFunc adapts a function to the SecretScanner interface
+type SecretScannerFunc func(s string) []string
+
+// FindSecrets calls f(s)
+func (f SecretScannerFunc) FindSecrets(s string) []string {
+	return f(s)
+}
+
+// HeuristicScanner finds JSON Web Tokens, AWS access key IDs and long
+// base64 strings mixing upper and lower case letters and digits
+var HeuristicScanner SecretScanner = SecretScannerFunc(findHeuristicSecrets)
+
+var (
+	jwtPattern          = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	awsAccessKeyPattern = regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)
+	base64Pattern       = regexp.MustCompile(`[A-Za-z0-9+/_-]{40,}={0,2}`)
+)
+
+func findHeuristicSecrets(s string) []string {
+	secrets := jwtPattern.FindAllString(s, -1)
+	secrets = append(secrets, awsAccessKeyPattern.FindAllString(s, -1)...)
+	for _, candidate := range base64Pattern.FindAllString(s, -1) {
+		// Paths and identifiers rarely mix all three character classes
+		if strings.ContainsAny(candidate, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") &&
+			strings.ContainsAny(candidate, "abcdefghijklmnopqrstuvwxyz") &&
+			strings.ContainsAny(candidate, "0123456789") {
+			secrets = append(secrets, candidate)
+		}
+	}
+	return secrets
+}
+
+// WithSecretScanner replaces the secrets scanner finds in the URL, proxy,
+// request target, header values, cookies, body, form fields and comments
+// with REDACTED, e.g.
+// WithSecretScanner(HeuristicScanner)
+func WithSecretScanner(scanner SecretScanner) CurlOption {
+	return func(c *CurlCommand) {
+		c.secretScanners = append(c.secretScanners, scanner)
+	}
+}
+
+// WithRejectSecrets makes command generation fail with ErrSecretDetected
+// when a scanner set with WithSecretScanner finds a secret, instead of
+// redacting it, so tooling can refuse to persist unsafe commands
+func WithRejectSecrets() CurlOption {
+	return func(c *CurlCommand) {
+		c.RejectSecrets = true
+	}
+}
+
+// scanSecrets runs the secret scanners over the command
+func (c *CurlCommand) scanSecrets() error {
+	if len(c.secretScanners) == 0 {
+		return nil
+	}
+	var err error
+	scan := func(kind RedactionKind, name string, s *string) {
+		if err != nil {
+			return
+		}
+		for _, scanner := range c.secretScanners {
+			for _, secret := range scanner.FindSecrets(*s) {
+				count := 0
+				if secret != "" {
+					count = strings.Count(*s, secret)
+				}
+				if count == 0 {
+					continue
+				}
+				if c.RejectSecrets {
+					if name == "" {
+						err = fmt.Errorf("%w: in %s", ErrSecretDetected, kind)
+					} else {
+						err = fmt.Errorf("%w: in %s %s", ErrSecretDetected, kind, name)
+					}
+					return
+				}
+				if paths := jsonPaths(*s, secret); kind == RedactedBody && len(paths) > 0 {
+					for _, path := range paths {
+						c.recordRedaction(kind, path, 1)
+					}
+				} else {
+					c.recordRedaction(kind, name, count)
+				}
+				*s = strings.ReplaceAll(*s, secret, redactedValue)
+			}
+		}
+	}
+
+	scan(RedactedURL, "", &c.URL)
+	scan(RedactedURL, "proxy", &c.Proxy)
+	scan(RedactedURL, "request target", &c.RequestTarget)
+	for i := range c.Headers {
+		scan(RedactedHeader, c.Headers[i].Key, &c.Headers[i].Value)
+	}
+	scan(RedactedHeader, "Cookie", &c.Cookies)
+	if c.Body != nil {
+		scan(RedactedBody, "", &c.Body.Data)
+	}
+	for i := range c.FormParts {
+		scan(RedactedBody, c.FormParts[i].Name, &c.FormParts[i].Value)
+	}
+	for i := range c.Comments {
+		scan(RedactedComment, "", &c.Comments[i])
+	}
+	for i := range c.FooterComments {
+		scan(RedactedComment, "", &c.FooterComments[i])
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package http2curl
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultCookieFile is the file the commands of a Session share cookies
+// through
+const DefaultCookieFile = "cookies.txt"
+
+// Session accumulates the commands of sequential requests, e.g. a login
+// followed by the requests made with its session cookie, and renders them as
+// a script sharing cookies through a cookie file. It is safe for concurrent
+// use; commands keep the order their requests were added in.
+type Session struct {
+	mu         sync.Mutex
+	converter  *Converter
+	cookieFile string
+	commands   []*CurlCommand
+}
+
+// NewSession returns a Session generating commands with opts and sharing
+// cookies through DefaultCookieFile
+func NewSession(opts ...CurlOption) *Session {
+	return &Session{converter: NewConverter(opts...), cookieFile: DefaultCookieFile}
+}
+
+// SetCookieFile sets the cookie file shared by the commands. Commands
+// already added are replaced with updated copies, leaving those returned
+// earlier unchanged.
+func (s *Session) SetCookieFile(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cookieFile = path
+	for i, command := range s.commands {
+		s.commands[i] = command.Derive(func(c *CurlCommand) { s.useCookieFile(c, i) })
+	}
+}
+
+// Add generates the command for req and appends it to the session. The
+// first command saves the cookies it receives with -c; later commands read
+// them with -b and save updated ones, in place of their Cookie header.
+func (s *Session) Add(req *http.Request) (*CurlCommand, error) {
+	command, err := s.converter.Convert(req)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.commands) > 0 {
+		command.RemoveHeader("Cookie")
+		command.Cookies = ""
+	}
+	s.useCookieFile(command, len(s.commands))
+	s.commands = append(s.commands, command)
+	return command, nil
+}
+
+// useCookieFile points the i-th command of the session at the cookie file
+func (s *Session) useCookieFile(command *CurlCommand, i int) {
+	command.CookieJarFile = s.cookieFile
+	if i > 0 {
+		command.CookieFile = s.cookieFile
+	}
+}
+
+// Commands returns the commands added so far
+func (s *Session) Commands() []*CurlCommand {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*CurlCommand(nil), s.commands...)
+}
+
+// Script returns a bash script running the commands in order, stopping at
+// the first that fails
+func (s *Session) Script() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\nset -e\n")
+	for _, command := range s.commands {
+		b.WriteByte('\n')
+		b.WriteString(command.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
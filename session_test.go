@@ -0,0 +1,33 @@
+package http2curl
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSession(t *testing.T) {
+	session := NewSession()
+	login, _ := http.NewRequest("POST", "http://example.com/login", strings.NewReader("user=cat"))
+	if _, err := session.Add(login); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	feed, _ := http.NewRequest("POST", "http://example.com/feed", strings.NewReader("food=fish"))
+	feed.Header.Set("Cookie", "session=abc123")
+	if _, err := session.Add(feed); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	want := "#!/usr/bin/env bash\nset -e\n\n" +
+		`curl -X 'POST' -d 'user=cat' -c 'cookies.txt' 'http://example.com/login'` + "\n\n" +
+		`curl -X 'POST' -d 'food=fish' -b 'cookies.txt' -c 'cookies.txt' 'http://example.com/feed'` + "\n"
+	if got := session.Script(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+
+	session.SetCookieFile("/tmp/cat cookies")
+	want = `curl -X 'POST' -d 'food=fish' -b '/tmp/cat cookies' -c '/tmp/cat cookies' 'http://example.com/feed'`
+	if got := session.Commands()[1].String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
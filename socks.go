@@ -0,0 +1,72 @@
+package http2curl
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// SocksVersion selects the SOCKS protocol curl speaks to a SOCKS proxy
+type SocksVersion int
+
+// Supported SOCKS versions
+const (
+	Socks4         SocksVersion = iota + 1 // --socks4, host names resolved by curl
+	Socks4A                                // --socks4a, host names resolved by the proxy
+	Socks5                                 // --socks5, host names resolved by curl
+	Socks5Hostname                         // --socks5-hostname, host names resolved by the proxy
+)
+
+// socksFlags maps SOCKS versions to their curl flags
+var socksFlags = map[SocksVersion]string{
+	Socks4:         "--socks4",
+	Socks4A:        "--socks4a",
+	Socks5:         "--socks5",
+	Socks5Hostname: "--socks5-hostname",
+}
+
+// WithSocksProxy sends the request through the SOCKS proxy at addr
+// (host:port), e.g. WithSocksProxy("bastion:1080", Socks5Hostname) renders
+// --socks5-hostname 'bastion:1080'
+func WithSocksProxy(addr string, version SocksVersion) CurlOption {
+	return func(c *CurlCommand) {
+		c.SocksProxy, c.SocksVersion = addr, version
+	}
+}
+
+// WithTransportProxy renders the proxy t selects for the request with its
+// Proxy function, e.g. http.ProxyFromEnvironment: socks5 and socks5h proxies,
+// which Go's transport resolves host names through, become
+// --socks5-hostname and HTTP proxies -x. Proxy credentials are left out of
+// the command. Dialers from golang.org/x/net/proxy set as DialContext
+// cannot be inspected; use WithSocksProxy for those.
+func WithTransportProxy(t *http.Transport) CurlOption {
+	return func(c *CurlCommand) {
+		c.proxyFunc = t.Proxy
+	}
+}
+
+// applyTransportProxy sets the proxy selected for req by the transport
+// given with WithTransportProxy
+func (c *CurlCommand) applyTransportProxy(req *http.Request) error {
+	if c.proxyFunc == nil || c.Proxy != "" || c.SocksProxy != "" {
+		return nil
+	}
+	u, err := c.proxyFunc(req)
+	if err != nil {
+		return fmt.Errorf("proxy error: %w", err)
+	}
+	if u == nil {
+		return nil
+	}
+	if u.User != nil {
+		c.recordLoss(LossAuthStripped, "", "proxy credentials left out")
+	}
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		c.SocksProxy, c.SocksVersion = u.Host, Socks5Hostname
+	default:
+		c.Proxy = (&url.URL{Scheme: u.Scheme, Host: u.Host}).String()
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package http2curl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithStrict makes command generation fail with ErrLossyConversion, listing
+// everything that would be lost, when the command would not reproduce the
+// request exactly: truncated, rewritten or decompressed bodies, streamed
+// bodies sent with a Content-Length, newlines or NUL bytes the rendering of
+// the body cannot carry, multipart bodies rebuilt with another boundary,
+// trailers, HTTP/2 requests rendered without --http2, Content-Encodings
+// curl does not know, stripped control characters and proxy credentials.
+// Headers dropped by options are not losses; see Warnings for those.
+func WithStrict() CurlOption {
+	return func(c *CurlCommand) {
+		c.Strict = true
+	}
+}
+
+// checkStrict fails with ErrLossyConversion if the command approximates
+// its request
+func (c *CurlCommand) checkStrict() error {
+	var losses []string
+	undecoded := false
+	for _, w := range c.warnings {
+		if w.Kind != LossHeaderDropped {
+			losses = append(losses, w.Detail)
+		}
+		undecoded = undecoded || (w.Kind == LossContentEncoding && c.AutoDecompressGZIP)
+	}
+	switch {
+	case undecoded:
+		return fmt.Errorf("%w: %w: %s", ErrLossyConversion, ErrUnsupportedEncoding, strings.Join(losses, "; "))
+	case len(losses) > 0:
+		return fmt.Errorf("%w: %s", ErrLossyConversion, strings.Join(losses, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package http2curl
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithStrict(t *testing.T) {
+	tests := []struct {
+		name string
+		req  func() *http.Request
+		opts []CurlOption
+		want string // Loss reported, empty when the conversion is exact
+	}{
+		{
+			name: "exact",
+			req: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, "https://example.com/cats", strings.NewReader(`{"name":"tom"}`))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			},
+		},
+		{
+			name: "truncated body",
+			req: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, "https://example.com/cats", strings.NewReader("meow meow"))
+				return req
+			},
+			opts: []CurlOption{WithBodyPeek(4)},
+			want: "body truncated",
+		},
+		{
+			name: "rewritten body",
+			req: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, "https://example.com/cats", strings.NewReader(`{"b":1, "a":2}`))
+				return req
+			},
+			opts: []CurlOption{WithNormalizedJSON()},
+			want: "body rewritten by a formatting option",
+		},
+		{
+			name: "streamed body",
+			req: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, "https://example.com/cats", io.NopCloser(strings.NewReader("meow")))
+				return req
+			},
+			want: "body of unknown length sent with a Content-Length, see WithChunkedBody",
+		},
+		{
+			name: "streamed body sent chunked",
+			req: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, "https://example.com/cats", io.NopCloser(strings.NewReader("meow")))
+				return req
+			},
+			opts: []CurlOption{WithChunkedBody()},
+		},
+		{
+			name: "newlines",
+			req: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, "https://example.com/cats", strings.NewReader("a\nb"))
+				return req
+			},
+			want: `body newlines sent as \n, see WithEscapedNewlines`,
+		},
+		{
+			name: "newlines escaped",
+			req: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, "https://example.com/cats", strings.NewReader("a\nb\x00"))
+				return req
+			},
+			opts: []CurlOption{WithEscapedNewlines()},
+		},
+		{
+			name: "trailers",
+			req: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, "https://example.com/cats", strings.NewReader("meow"))
+				req.Trailer = http.Header{"Checksum": nil}
+				return req
+			},
+			want: "trailers not sent by curl: Checksum",
+		},
+		{
+			name: "unsupported encoding",
+			req: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, "https://example.com/cats", strings.NewReader("meow"))
+				req.Header.Set("Content-Encoding", "gzip, snappy")
+				return req
+			},
+			want: `unsupported Content-Encoding "snappy"`,
+		},
+		{
+			name: "http2",
+			req: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodConnect, "https://example.com/chat", nil)
+				req.ProtoMajor, req.ProtoMinor = 2, 0
+				req.Header.Set(":protocol", "websocket")
+				return req
+			},
+			want: "HTTP/2 request rendered without --http2, see WithHTTP2Flags; HTTP/2 pseudo-header :protocol dropped",
+		},
+		{
+			name: "http2 flags",
+			req: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "https://example.com/cats", nil)
+				req.ProtoMajor, req.ProtoMinor = 2, 0
+				return req
+			},
+			opts: []CurlOption{WithHTTP2Flags()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := GetCurlCommand(tt.req(), tt.opts...); err != nil {
+				t.Fatalf("GetCurlCommand() without WithStrict error = %v", err)
+			}
+			_, err := GetCurlCommand(tt.req(), append(tt.opts, WithStrict())...)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("GetCurlCommand() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrLossyConversion) {
+				t.Fatalf("GetCurlCommand() error = %v, want %v", err, ErrLossyConversion)
+			}
+			if want := ErrLossyConversion.Error() + ": " + tt.want; err.Error() != want {
+				t.Errorf("Got:\n%s\nWant:\n%s", err, want)
+			}
+		})
+	}
+}
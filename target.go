@@ -0,0 +1,39 @@
+package http2curl
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// applyRequestTarget renders requests whose target is not a path: CONNECT
+// requests become a proxy tunnel to the requested authority, and OPTIONS *
+// requests use --request-target
+func (c *CurlCommand) applyRequestTarget(req *http.Request) {
+	switch {
+	case req.Method == http.MethodConnect:
+		authority := req.Host
+		if req.URL.Opaque != "" {
+			authority = req.URL.Opaque
+		}
+		if authority == "" {
+			authority = req.URL.Host
+		}
+
+		if req.URL.Scheme != "" && req.URL.Host != "" && req.URL.Host != authority {
+			c.Proxy = req.URL.Scheme + "://" + req.URL.Host
+		} else if addr, ok := req.Context().Value(http.LocalAddrContextKey).(net.Addr); ok && req.URL.Scheme == "" {
+			c.Proxy = "http://" + addr.String() // Server side: this server is the proxy
+		}
+		c.ProxyTunnel = true
+
+		scheme := "http"
+		if _, port, _ := net.SplitHostPort(authority); port == "443" {
+			scheme = "https"
+		}
+		c.URL = scheme + "://" + c.formatHost(authority)
+	case req.Method == http.MethodOptions && (req.RequestURI == "*" || req.URL.Path == "*" || req.URL.Opaque == "*"):
+		c.RequestTarget = "*"
+		c.URL = strings.TrimSuffix(strings.TrimSuffix(c.URL, "*"), "/")
+	}
+}
@@ -0,0 +1,84 @@
+package http2curl
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestRequestTarget(t *testing.T) {
+	tests := []struct {
+		name        string
+		setupReq    func() *http.Request
+		wantCommand string
+	}{
+		{
+			name: "client CONNECT through proxy",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("CONNECT", "http://proxy.internal:3128", nil)
+				req.Host = "example.com:443"
+				return req
+			},
+			wantCommand: `curl -p -x 'http://proxy.internal:3128' 'https://example.com:443'`,
+		},
+		{
+			name: "client CONNECT with opaque target",
+			setupReq: func() *http.Request {
+				return &http.Request{
+					Method: "CONNECT",
+					URL:    &url.URL{Opaque: "example.com:8080"},
+					Header: http.Header{"Proxy-Authorization": {"Basic dXNlcjpwYXNz"}},
+				}
+			},
+			wantCommand: `curl -p -H 'Proxy-Authorization: Basic dXNlcjpwYXNz' 'http://example.com:8080'`,
+		},
+		{
+			name: "server side CONNECT",
+			setupReq: func() *http.Request {
+				req := httptest.NewRequest("CONNECT", "example.com:443", nil)
+				addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.5"), Port: 3128}
+				return req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, addr))
+			},
+			wantCommand: `curl -p -x 'http://10.0.0.5:3128' 'https://example.com:443'`,
+		},
+		{
+			name: "client OPTIONS *",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("OPTIONS", "http://example.com", nil)
+				req.URL.Path = "*"
+				return req
+			},
+			wantCommand: `curl -X 'OPTIONS' --request-target '*' 'http://example.com'`,
+		},
+		{
+			name: "server side OPTIONS *",
+			setupReq: func() *http.Request {
+				return httptest.NewRequest("OPTIONS", "*", nil)
+			},
+			wantCommand: `curl -X 'OPTIONS' --request-target '*' 'http://example.com'`,
+		},
+		{
+			name: "OPTIONS with path",
+			setupReq: func() *http.Request {
+				req, _ := http.NewRequest("OPTIONS", "http://example.com/api", nil)
+				return req
+			},
+			wantCommand: `curl -X 'OPTIONS' 'http://example.com/api'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := GetCurlCommand(tt.setupReq())
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if command.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package http2curl
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+type timingsKey struct{}
+
+// timings records the phases of a request traced with TraceTimings
+type timings struct {
+	mu sync.Mutex
+
+	start, dnsStart, connectStart, tlsStart, wrote time.Time
+
+	dns, connect, tls, server, total time.Duration
+	remoteAddr                       string
+	reused, done                     bool
+}
+
+// TraceTimings returns a copy of ctx recording the DNS lookup, connect, TLS
+// handshake and time to first byte of requests sent with it. Commands
+// generated with WithTimingComments from such a request list the timings
+// below them, so the repro ships with the evidence of what was slow.
+func TraceTimings(ctx context.Context) context.Context {
+	t := &timings{}
+	ctx = context.WithValue(ctx, timingsKey{}, t)
+	return httptrace.WithClientTrace(ctx, t.clientTrace())
+}
+
+// WithTimingComments adds the timings recorded by TraceTimings as comments
+// below the command. Requests without recorded timings get none.
+func WithTimingComments() CurlOption {
+	return func(c *CurlCommand) {
+		c.TimingComments = true
+	}
+}
+
+// addTimings adds the comments of the timings recorded for req
+func (c *CurlCommand) addTimings(req *http.Request) {
+	if !c.TimingComments {
+		return
+	}
+	if t, ok := req.Context().Value(timingsKey{}).(*timings); ok {
+		c.FooterComments = append(c.FooterComments, t.comments()...)
+	}
+}
+
+func (t *timings) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.reset()
+			t.start = now()
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dns = now().Sub(t.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.connectStart.IsZero() {
+				t.connectStart = now()
+			}
+		},
+		ConnectDone: func(_, addr string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err == nil && t.remoteAddr == "" {
+				t.connect = now().Sub(t.connectStart)
+				t.remoteAddr = addr
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tls = now().Sub(t.tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.reused = info.Reused
+			if t.remoteAddr == "" && info.Conn != nil {
+				t.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.wrote = now()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			at := now()
+			t.server = at.Sub(t.wrote)
+			t.total = at.Sub(t.start)
+			t.done = true
+		},
+	}
+}
+
+// reset clears the timings of an earlier attempt, e.g. before a redirect
+func (t *timings) reset() {
+	t.dnsStart, t.connectStart, t.tlsStart, t.wrote = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+	t.dns, t.connect, t.tls, t.server, t.total = 0, 0, 0, 0, 0
+	t.remoteAddr, t.reused, t.done = "", false, false
+}
+
+// comments returns the recorded timings as comment lines, skipping the
+// phases that did not happen
+func (t *timings) comments() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.start.IsZero() {
+		return nil
+	}
+	var comments []string
+	if t.reused {
+		comments = append(comments, "connection: reused "+t.remoteAddr)
+	}
+	if !t.dnsStart.IsZero() {
+		comments = append(comments, "dns: "+formatDuration(t.dns))
+	}
+	if !t.connectStart.IsZero() {
+		comments = append(comments, fmt.Sprintf("connect: %s %s", formatDuration(t.connect), t.remoteAddr))
+	}
+	if !t.tlsStart.IsZero() {
+		comments = append(comments, "tls: "+formatDuration(t.tls))
+	}
+	if t.done {
+		comments = append(comments, "server: "+formatDuration(t.server), "total: "+formatDuration(t.total))
+	}
+	return comments
+}
+
+// formatDuration rounds d to a readable precision
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Microsecond).String()
+}
@@ -0,0 +1,101 @@
+package http2curl
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimingComments(t *testing.T) {
+	clock := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return clock }
+	step := func(d time.Duration) { clock = clock.Add(d) }
+
+	req, _ := http.NewRequest("GET", "https://example.com/cats", nil)
+	req = req.WithContext(TraceTimings(req.Context()))
+	trace := httptrace.ContextClientTrace(req.Context())
+	trace.GetConn("example.com:443")
+	trace.DNSStart(httptrace.DNSStartInfo{Host: "example.com"})
+	step(12 * time.Millisecond)
+	trace.DNSDone(httptrace.DNSDoneInfo{})
+	trace.ConnectStart("tcp", "93.184.216.34:443")
+	step(30 * time.Millisecond)
+	trace.ConnectDone("tcp", "93.184.216.34:443", nil)
+	trace.TLSHandshakeStart()
+	step(45 * time.Millisecond)
+	trace.TLSHandshakeDone(tls.ConnectionState{}, nil)
+	trace.GotConn(httptrace.GotConnInfo{})
+	trace.WroteRequest(httptrace.WroteRequestInfo{})
+	step(1500 * time.Millisecond)
+	trace.GotFirstResponseByte()
+
+	tests := []struct {
+		name string
+		opts []CurlOption
+		want string
+	}{
+		{
+			name: "without option",
+			want: `curl -X 'GET' 'https://example.com/cats'`,
+		},
+		{
+			name: "with option",
+			opts: []CurlOption{WithTimingComments(), WithComment("source: billing")},
+			want: "# source: billing\n" +
+				"curl -X 'GET' 'https://example.com/cats'\n" +
+				"# dns: 12ms\n" +
+				"# connect: 30ms 93.184.216.34:443\n" +
+				"# tls: 45ms\n" +
+				"# server: 1.5s\n" +
+				"# total: 1.587s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := GetCurlCommand(req, tt.opts...)
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.want {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimingCommentsUntraced(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	command, _ := GetCurlCommand(req, WithTimingComments())
+	want := `curl -X 'GET' 'http://example.com'`
+	if got := command.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
+
+func TestTimingCommentsTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	var got string
+	client := &http.Client{Transport: NewTransport(nil, func(command *CurlCommand, _ *http.Response, _ error) {
+		got = command.String()
+	}, WithCommandOptions(WithTimingComments()))}
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	resp, err := client.Do(req.WithContext(TraceTimings(req.Context())))
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	lines := strings.Split(got, "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[1], "# connect: ") || !strings.HasSuffix(lines[1], server.Listener.Addr().String()) ||
+		!strings.HasPrefix(lines[2], "# server: ") || !strings.HasPrefix(lines[3], "# total: ") {
+		t.Errorf("command with timings:\n%s", got)
+	}
+}
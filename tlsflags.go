@@ -0,0 +1,137 @@
+package http2curl
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// tlsVersions maps Go TLS versions to curl's version numbers
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "1.0",
+	tls.VersionTLS11: "1.1",
+	tls.VersionTLS12: "1.2",
+	tls.VersionTLS13: "1.3",
+}
+
+// opensslCiphers maps Go cipher suites up to TLS 1.2 to the OpenSSL names
+// --ciphers takes. TLS 1.3 suites keep their IANA names with --tls13-ciphers.
+var opensslCiphers = map[uint16]string{
+	tls.TLS_RSA_WITH_RC4_128_SHA:                      "RC4-SHA",
+	tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA:                 "DES-CBC3-SHA",
+	tls.TLS_RSA_WITH_AES_128_CBC_SHA:                  "AES128-SHA",
+	tls.TLS_RSA_WITH_AES_256_CBC_SHA:                  "AES256-SHA",
+	tls.TLS_RSA_WITH_AES_128_CBC_SHA256:               "AES128-SHA256",
+	tls.TLS_RSA_WITH_AES_128_GCM_SHA256:               "AES128-GCM-SHA256",
+	tls.TLS_RSA_WITH_AES_256_GCM_SHA384:               "AES256-GCM-SHA384",
+	tls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA:              "ECDHE-ECDSA-RC4-SHA",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA:          "ECDHE-ECDSA-AES128-SHA",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA:          "ECDHE-ECDSA-AES256-SHA",
+	tls.TLS_ECDHE_RSA_WITH_RC4_128_SHA:                "ECDHE-RSA-RC4-SHA",
+	tls.TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA:           "ECDHE-RSA-DES-CBC3-SHA",
+	tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA:            "ECDHE-RSA-AES128-SHA",
+	tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA:            "ECDHE-RSA-AES256-SHA",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256:       "ECDHE-ECDSA-AES128-SHA256",
+	tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256:         "ECDHE-RSA-AES128-SHA256",
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256:         "ECDHE-RSA-AES128-GCM-SHA256",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256:       "ECDHE-ECDSA-AES128-GCM-SHA256",
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384:         "ECDHE-RSA-AES256-GCM-SHA384",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384:       "ECDHE-ECDSA-AES256-GCM-SHA384",
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256:   "ECDHE-RSA-CHACHA20-POLY1305",
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256: "ECDHE-ECDSA-CHACHA20-POLY1305",
+}
+
+// WithTLSVersion restricts the TLS versions curl negotiates to the range
+// min to max, e.g. WithTLSVersion(tls.VersionTLS12, tls.VersionTLS12)
+// renders --tlsv1.2 --tls-max 1.2. Either bound may be 0 for none.
+func WithTLSVersion(min, max uint16) CurlOption {
+	return func(c *CurlCommand) {
+		c.TLSMinVersion, c.TLSMaxVersion = min, max
+	}
+}
+
+// WithCiphers restricts the cipher suites curl offers to the given Go
+// cipher suites, e.g. the CipherSuites of a client's tls.Config, rendered
+// with their OpenSSL names in --ciphers and TLS 1.3 suites in
+// --tls13-ciphers
+func WithCiphers(suites ...uint16) CurlOption {
+	return func(c *CurlCommand) {
+		c.Ciphers = append(c.Ciphers, suites...)
+	}
+}
+
+// WithPinnedPubKey makes curl verify the server's public key with
+// --pinnedpubkey, failing like a pinning client when the certificate
+// changes. hashOrPath is a PEM or DER public key file, or base64 SHA-256
+// hashes as returned by PubKeyPin, separated by ";".
+func WithPinnedPubKey(hashOrPath string) CurlOption {
+	return func(c *CurlCommand) {
+		c.PinnedPubKey = hashOrPath
+	}
+}
+
+// PubKeyPin returns the --pinnedpubkey hash of the certificate's public key,
+// e.g. sha256//YhKJKSzoTt2b5FP18fvpHo7fJYqQCjAa3HWY3tvRMwE=, the SPKI
+// fingerprint most pinning clients compare
+func PubKeyPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256//" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// validateTLS checks the versions set with WithTLSVersion
+func (c *CurlCommand) validateTLS() error {
+	for _, v := range []uint16{c.TLSMinVersion, c.TLSMaxVersion} {
+		if _, ok := tlsVersions[v]; v != 0 && !ok {
+			return fmt.Errorf("unsupported TLS version %#04x", v)
+		}
+	}
+	if c.TLSMinVersion != 0 && c.TLSMaxVersion != 0 && c.TLSMinVersion > c.TLSMaxVersion {
+		return fmt.Errorf("%w: minimum TLS version above the maximum", ErrConflictingOptions)
+	}
+	return nil
+}
+
+// cipherLists returns the --ciphers and --tls13-ciphers parameters
+func (c *CurlCommand) cipherLists() (string, string) {
+	if len(c.Ciphers) == 0 {
+		return "", ""
+	}
+	var ciphers, tls13 []string
+	for _, id := range c.Ciphers {
+		if name, ok := opensslCiphers[id]; ok {
+			ciphers = append(ciphers, name)
+			continue
+		}
+		switch id {
+		case tls.TLS_AES_128_GCM_SHA256, tls.TLS_AES_256_GCM_SHA384, tls.TLS_CHACHA20_POLY1305_SHA256:
+			tls13 = append(tls13, tls.CipherSuiteName(id))
+		default:
+			ciphers = append(ciphers, tls.CipherSuiteName(id)) // Unknown to curl, left for it to report
+		}
+	}
+	return strings.Join(ciphers, ":"), strings.Join(tls13, ":")
+}
+
+// tlsArgs returns the TLS version, cipher and pinning arguments
+func (c *CurlCommand) tlsArgs(args []arg) []arg {
+	if c.TLSMinVersion != 0 {
+		args = append(args, flagArg("--tlsv"+tlsVersions[c.TLSMinVersion]))
+	}
+	if c.TLSMaxVersion != 0 {
+		args = append(args, flagArg("--tls-max"), flagArg(tlsVersions[c.TLSMaxVersion]))
+	}
+	ciphers, tls13 := c.cipherLists()
+	if ciphers != "" {
+		args = append(args, flagArg("--ciphers"), quotedArg(ciphers))
+	}
+	if tls13 != "" {
+		args = append(args, flagArg("--tls13-ciphers"), quotedArg(tls13))
+	}
+	if c.PinnedPubKey != "" {
+		args = append(args, flagArg("--pinnedpubkey"), quotedArg(c.PinnedPubKey))
+	}
+	return args
+}
@@ -0,0 +1,83 @@
+package http2curl
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTLSFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []CurlOption
+		wantCommand string
+		wantErr     error
+	}{
+		{
+			name:        "pinned version",
+			opts:        []CurlOption{WithTLSVersion(tls.VersionTLS12, tls.VersionTLS12), WithInsecureSkipVerify()},
+			wantCommand: `curl -k --tlsv1.2 --tls-max 1.2 -X 'GET' 'https://example.com'`,
+		},
+		{
+			name:        "minimum only",
+			opts:        []CurlOption{WithTLSVersion(tls.VersionTLS13, 0)},
+			wantCommand: `curl --tlsv1.3 -X 'GET' 'https://example.com'`,
+		},
+		{
+			name: "ciphers",
+			opts: []CurlOption{WithCiphers(
+				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+				tls.TLS_AES_256_GCM_SHA384,
+				tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			)},
+			wantCommand: `curl --ciphers 'ECDHE-RSA-AES128-GCM-SHA256:ECDHE-ECDSA-CHACHA20-POLY1305' ` +
+				`--tls13-ciphers 'TLS_AES_256_GCM_SHA384' -X 'GET' 'https://example.com'`,
+		},
+		{
+			name:        "pinned public key",
+			opts:        []CurlOption{WithPinnedPubKey("sha256//YhKJKSzoTt2b5FP18fvpHo7fJYqQCjAa3HWY3tvRMwE=;sha256//t62CeU2tQiqkexU74Gxa2eg7fRbEgoChTociMee9wno=")},
+			wantCommand: `curl --pinnedpubkey 'sha256//YhKJKSzoTt2b5FP18fvpHo7fJYqQCjAa3HWY3tvRMwE=;sha256//t62CeU2tQiqkexU74Gxa2eg7fRbEgoChTociMee9wno=' -X 'GET' 'https://example.com'`,
+		},
+		{
+			name:    "inverted range",
+			opts:    []CurlOption{WithTLSVersion(tls.VersionTLS13, tls.VersionTLS12)},
+			wantErr: ErrConflictingOptions,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "https://example.com", nil)
+			command, err := GetCurlCommand(req, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetCurlCommand() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && command.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestTLSFlagsUnsupportedVersion(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.com", nil)
+	if _, err := GetCurlCommand(req, WithTLSVersion(0x0300, 0)); err == nil {
+		t.Error("GetCurlCommand() with SSL 3.0 error = nil, want an error")
+	}
+}
+
+func TestPubKeyPin(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	cert := server.Certificate()
+
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	want := "sha256//" + base64.StdEncoding.EncodeToString(sum[:])
+	if got := PubKeyPin(cert); got != want {
+		t.Errorf("PubKeyPin() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,156 @@
+package http2curl
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// TokenKind is the kind of a Token
+type TokenKind int
+
+// Token kinds emitted by RenderTokens
+const (
+	TokenComment TokenKind = iota // A comment line, without the leading #
+	TokenProgram                  // curl
+	TokenFlag                     // A flag, e.g. -X or --compressed
+	TokenValue                    // The parameter of the preceding flag, e.g. POST
+	TokenHeader                   // A -H header, split into Key and Value
+	TokenBody                     // A chunk of the body of the preceding data flag
+	TokenURL                      // The request URL
+)
+
+// bodyChunkSize is the maximum length of a TokenBody chunk
+const bodyChunkSize = 32 << 10
+
+// Token is a single element of a command, unquoted. The body is emitted as
+// it is sent, in chunks of up to 32 KiB split on UTF-8 boundaries, even when
+// the rendered command pipes it through standard input.
+type Token struct {
+	Kind  TokenKind
+	Value string // The text of the token, or the header value
+	Key   string // The header name of TokenHeader tokens
+}
+
+// RenderTokens generates the command for req and passes its tokens to emit
+// one at a time, for custom renderers such as syntax highlighters. It
+// stops at the first error returned by emit.
+func RenderTokens(req *http.Request, emit func(token Token) error, opts ...CurlOption) error {
+	command, err := GetCurlCommand(req, opts...)
+	if err != nil {
+		return err
+	}
+	return command.EmitTokens(emit)
+}
+
+// EmitTokens passes the tokens of the command to emit one at a time,
+// stopping at the first error it returns
+func (c *CurlCommand) EmitTokens(emit func(token Token) error) error {
+	for _, comment := range c.Comments {
+		if err := emit(Token{Kind: TokenComment, Value: sanitizeComment(comment)}); err != nil {
+			return err
+		}
+	}
+	if err := emit(Token{Kind: TokenProgram, Value: c.program()}); err != nil {
+		return err
+	}
+	if c.ConfigFile != "" {
+		if err := emit(Token{Kind: TokenFlag, Value: "-K"}); err != nil {
+			return err
+		}
+		return emit(Token{Kind: TokenValue, Value: c.ConfigFile})
+	}
+	if err := c.emitArgs(emit); err != nil {
+		return err
+	}
+	for _, next := range c.Next {
+		if err := emit(Token{Kind: TokenFlag, Value: "--next"}); err != nil {
+			return err
+		}
+		if err := next.emitArgs(emit); err != nil {
+			return err
+		}
+	}
+	for _, comment := range c.FooterComments {
+		if err := emit(Token{Kind: TokenComment, Value: sanitizeComment(comment)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// emitArgs passes the tokens of the arguments of the command to emit
+func (c *CurlCommand) emitArgs(emit func(token Token) error) error {
+	args := c.args()
+	for i, a := range args {
+		var err error
+		switch kind := c.argKind(args, i); kind {
+		case TokenHeader:
+			key, value := splitHeaderLine(c.literal(a.value))
+			err = emit(Token{Kind: TokenHeader, Key: key, Value: value})
+		case TokenBody:
+			data := a.value
+			if a.value == "@-" && a.style == argRaw {
+				data = c.Body.Data
+			}
+			err = emitBodyChunks(c.literal(data), emit)
+		default:
+			err = emit(Token{Kind: kind, Value: c.literal(a.value)})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// argKind returns the kind of token of the i-th of args
+func (c *CurlCommand) argKind(args []arg, i int) TokenKind {
+	switch {
+	case i == 0 || !takesParameter(args[i-1]):
+		if args[i].style == argRaw && strings.HasPrefix(args[i].value, "-") {
+			return TokenFlag
+		}
+		return TokenURL
+	case args[i-1].value == "-H":
+		return TokenHeader
+	case isDataFlag(args[i-1].value) && c.bodyMode() != bodyFile:
+		return TokenBody
+	default:
+		return TokenValue
+	}
+}
+
+// isDataFlag reports whether flag passes the request body
+func isDataFlag(flag string) bool {
+	switch flag {
+	case "-d", "--data-binary", "--data-raw", "--json":
+		return true
+	}
+	return false
+}
+
+// splitHeaderLine splits a -H parameter into the header name and value
+func splitHeaderLine(line string) (string, string) {
+	i := strings.IndexAny(line, ":;")
+	if i < 0 {
+		return line, ""
+	}
+	return line[:i], strings.TrimLeft(line[i+1:], " ")
+}
+
+// emitBodyChunks passes data to emit in chunks of up to bodyChunkSize bytes
+// which do not split UTF-8 sequences
+func emitBodyChunks(data string, emit func(token Token) error) error {
+	for len(data) > bodyChunkSize {
+		n := bodyChunkSize
+		for n > bodyChunkSize-utf8.UTFMax && !utf8.RuneStart(data[n]) {
+			n--
+		}
+		if err := emit(Token{Kind: TokenBody, Value: data[:n]}); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return emit(Token{Kind: TokenBody, Value: data})
+}
@@ -0,0 +1,73 @@
+package http2curl
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderTokens(t *testing.T) {
+	req, _ := http.NewRequest("PUT", "http://example.com/cats", strings.NewReader("line one\nline two"))
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("X-Empty", "")
+
+	var got []Token
+	err := RenderTokens(req, func(token Token) error {
+		got = append(got, token)
+		return nil
+	}, WithHeredocBody(), WithComment("cats"), WithCompression())
+	if err != nil {
+		t.Fatalf("RenderTokens() error = %v", err)
+	}
+	want := []Token{
+		{Kind: TokenComment, Value: "cats"},
+		{Kind: TokenProgram, Value: "curl"},
+		{Kind: TokenFlag, Value: "-X"},
+		{Kind: TokenValue, Value: "PUT"},
+		{Kind: TokenFlag, Value: "--data-binary"},
+		{Kind: TokenBody, Value: "line one\nline two"},
+		{Kind: TokenFlag, Value: "-H"},
+		{Kind: TokenHeader, Key: "Content-Type", Value: "text/plain"},
+		{Kind: TokenFlag, Value: "-H"},
+		{Kind: TokenHeader, Key: "X-Empty", Value: ""},
+		{Kind: TokenURL, Value: "http://example.com/cats"},
+		{Kind: TokenFlag, Value: "--compressed"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got:\n%v\nWant:\n%v", got, want)
+	}
+}
+
+func TestRenderTokensBodyChunks(t *testing.T) {
+	body := strings.Repeat("a", bodyChunkSize-1) + "é" + strings.Repeat("b", 10)
+	req, _ := http.NewRequest("POST", "http://example.com", strings.NewReader(body))
+
+	var chunks []string
+	err := RenderTokens(req, func(token Token) error {
+		if token.Kind == TokenBody {
+			chunks = append(chunks, token.Value)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RenderTokens() error = %v", err)
+	}
+	if len(chunks) != 2 || len(chunks[0]) != bodyChunkSize-1 || strings.Join(chunks, "") != body {
+		t.Errorf("body split into chunks of %d bytes, want %d and the rest", len(chunks[0]), bodyChunkSize-1)
+	}
+}
+
+func TestRenderTokensStopsOnError(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	errStop := errors.New("stop")
+	calls := 0
+	err := RenderTokens(req, func(Token) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || calls != 1 {
+		t.Errorf("RenderTokens() error = %v after %d calls, want %v after 1", err, calls, errStop)
+	}
+}
@@ -0,0 +1,169 @@
+package http2curl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// TransportLogger receives the curl command of a round trip along with its
+// outcome. The command is nil if it could not be generated.
+type TransportLogger func(command *CurlCommand, resp *http.Response, err error)
+
+// TransportOption configures a Transport
+type TransportOption func(t *Transport)
+
+// Transport is an http.RoundTripper passing the curl command of each request
+// it sends to a TransportLogger
+type Transport struct {
+	base      http.RoundTripper
+	log       TransportLogger
+	converter *Converter
+	captureOn func(*http.Request, *http.Response, error) bool
+	async     *AsyncRenderer
+}
+
+// NewTransport returns a Transport sending requests with base, or
+// http.DefaultTransport if base is nil, and logging their commands with log.
+// If log is nil requests are sent without generating commands.
+func NewTransport(base http.RoundTripper, log TransportLogger, opts ...TransportOption) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &Transport{base: base, log: log, converter: NewConverter()}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// WithCommandOptions generates the commands of a Transport with opts
+func WithCommandOptions(opts ...CurlOption) TransportOption {
+	return func(t *Transport) {
+		t.converter = NewConverter(opts...)
+	}
+}
+
+// WithCaptureOn only generates and logs commands of round trips for which
+// capture returns true, e.g. FailedRoundTrip. Request bodies are then read
+// back with GetBody, or copied as they are sent when it is not set: up to
+// MiddlewareMaxBodySize bytes are then held in memory for every round trip,
+// including those capture discards, and longer bodies are truncated.
+func WithCaptureOn(capture func(req *http.Request, resp *http.Response, err error) bool) TransportOption {
+	return func(t *Transport) {
+		t.captureOn = capture
+	}
+}
+
+// FailedRoundTrip reports whether a round trip failed or returned a status of
+// 400 or more, for use with WithCaptureOn
+func FailedRoundTrip(_ *http.Request, resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode >= http.StatusBadRequest
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.log == nil {
+		return t.base.RoundTrip(req)
+	}
+	send := req
+	var sent *lockedBuffer
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		sent = &lockedBuffer{limit: t.teeLimit() + 1}
+		send = req.Clone(req.Context())
+		send.Body = &teeReadCloser{Reader: io.TeeReader(req.Body, sent), Closer: req.Body}
+	}
+
+	resp, err := t.base.RoundTrip(send)
+	if t.captureOn != nil && !t.captureOn(req, resp, err) {
+		return resp, err
+	}
+
+	render := req.Clone(req.Context())
+	if resp != nil && resp.ProtoMajor == 2 {
+		render.Proto, render.ProtoMajor, render.ProtoMinor = resp.Proto, resp.ProtoMajor, resp.ProtoMinor
+	}
+	if sent != nil && t.async != nil {
+		t.async.submit(render, sent.Bytes(), t.teeLimit(), nil, func(command *CurlCommand, cerr error) {
+			t.logCommand(command, cerr, resp, err)
+		})
+		return resp, err
+	}
+	var truncated bool
+	switch {
+	case sent != nil:
+		data := sent.Bytes()
+		if int64(len(data)) > t.teeLimit() {
+			data, truncated = data[:t.teeLimit()], true
+		}
+		render.Body = io.NopCloser(bytes.NewReader(data))
+	case req.GetBody != nil:
+		body, gerr := req.GetBody()
+		if gerr != nil {
+			t.log(nil, resp, err)
+			return resp, err
+		}
+		render.Body = body
+	}
+	if t.async != nil {
+		t.async.Submit(render, func(command *CurlCommand, cerr error) {
+			t.logCommand(command, cerr, resp, err)
+		})
+		return resp, err
+	}
+	command, cerr := t.converter.Convert(render)
+	if cerr == nil && truncated {
+		command.Comments = append(command.Comments, fmt.Sprintf("body truncated to %d bytes", t.teeLimit()))
+	}
+	t.logCommand(command, cerr, resp, err)
+	return resp, err
+}
+
+// teeLimit returns the number of bytes of the bodies copied as they are
+// sent rendered, those past it being truncated
+func (t *Transport) teeLimit() int64 {
+	if t.async != nil && t.async.maxBody > 0 && t.async.maxBody < MiddlewareMaxBodySize {
+		return t.async.maxBody
+	}
+	return MiddlewareMaxBodySize
+}
+
+// logCommand passes command to the logger, or nil when cerr is set
+func (t *Transport) logCommand(command *CurlCommand, cerr error, resp *http.Response, err error) {
+	if cerr != nil {
+		command = nil
+	}
+	t.log(command, resp, err)
+}
+
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// lockedBuffer is a buffer safe to read while the transport may still be
+// sending the request body, keeping its first limit bytes unless limit is 0
+type lockedBuffer struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	limit int64
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if room := b.limit - int64(b.buf.Len()); b.limit > 0 && int64(len(p)) > room {
+		b.buf.Write(p[:room])
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// Bytes returns a copy of the bytes written so far
+func (b *lockedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
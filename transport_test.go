@@ -0,0 +1,132 @@
+package http2curl
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "meow" {
+			t.Errorf("server received body %q, want %q", body, "meow")
+		}
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		opts       []TransportOption
+		path       string
+		body       func() io.Reader
+		wantLogged bool
+	}{
+		{
+			name:       "every request",
+			path:       "/cats",
+			body:       func() io.Reader { return strings.NewReader("meow") },
+			wantLogged: true,
+		},
+		{
+			name: "successful request with capture on failure",
+			opts: []TransportOption{WithCaptureOn(FailedRoundTrip)},
+			path: "/cats",
+			body: func() io.Reader { return strings.NewReader("meow") },
+		},
+		{
+			name:       "failed request with capture on failure",
+			opts:       []TransportOption{WithCaptureOn(FailedRoundTrip)},
+			path:       "/missing",
+			body:       func() io.Reader { return strings.NewReader("meow") },
+			wantLogged: true,
+		},
+		{
+			name:       "failed request without GetBody",
+			opts:       []TransportOption{WithCaptureOn(FailedRoundTrip)},
+			path:       "/missing",
+			body:       func() io.Reader { return io.MultiReader(bytes.NewBufferString("me"), bytes.NewBufferString("ow")) },
+			wantLogged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logged *CurlCommand
+			opts := append([]TransportOption{WithCommandOptions(WithRedactedHeaders("Authorization"))}, tt.opts...)
+			client := &http.Client{Transport: NewTransport(nil, func(command *CurlCommand, resp *http.Response, err error) {
+				if err != nil {
+					t.Errorf("round trip error = %v", err)
+				}
+				logged = command
+			}, opts...)}
+
+			req, _ := http.NewRequest("POST", server.URL+tt.path, tt.body())
+			req.Header.Set("Authorization", "Bearer secret")
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			resp.Body.Close()
+
+			if !tt.wantLogged {
+				if logged != nil {
+					t.Errorf("logged %v, want nothing", logged)
+				}
+				return
+			}
+			want := `curl -X 'POST' -d 'meow' -H 'Authorization: REDACTED' '` + server.URL + tt.path + `'`
+			if logged == nil || logged.String() != want {
+				t.Errorf("Got:\n%v\nWant:\n%s", logged, want)
+			}
+		})
+	}
+}
+
+func TestTransportNilLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(nil, nil)}
+	resp, err := client.Post(server.URL, "text/plain", io.MultiReader(strings.NewReader("meow")))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestTransportTeeLimit(t *testing.T) {
+	body := strings.Repeat("a", MiddlewareMaxBodySize+10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, _ := io.ReadAll(r.Body); len(got) != len(body) {
+			t.Errorf("server received %d bytes, want %d", len(got), len(body))
+		}
+	}))
+	defer server.Close()
+
+	var logged *CurlCommand
+	client := &http.Client{Transport: NewTransport(nil, func(command *CurlCommand, _ *http.Response, _ error) {
+		logged = command
+	})}
+	resp, err := client.Post(server.URL, "text/plain", io.MultiReader(strings.NewReader(body)))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if logged == nil {
+		t.Fatal("no command logged")
+	}
+	if got := len(logged.Body.Data); got != MiddlewareMaxBodySize {
+		t.Errorf("rendered body of %d bytes, want %d", got, MiddlewareMaxBodySize)
+	}
+	if want := "# body truncated to 1048576 bytes\n"; !strings.HasPrefix(logged.String(), want) {
+		t.Errorf("command does not start with %q", want)
+	}
+}
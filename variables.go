@@ -0,0 +1,399 @@
+package http2curl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// VariableTarget is a part of the command WithVariable replaces values in
+type VariableTarget int
+
+// Parts of the command variables are substituted in
+const (
+	VariableURL VariableTarget = iota
+	VariableHeaders
+	VariableBody
+)
+
+// Variable is referenced in the command in place of its value, e.g. HOST
+// for commands run against several environments
+type Variable struct {
+	Name    string
+	Value   string
+	Targets []VariableTarget // Parts the value is replaced in, all when empty
+}
+
+// WithVariable replaces occurrences of value with ${NAME} in the URL,
+// header values and body, or only in the given targets, and assigns the
+// variable in a preamble above the command, e.g.
+// WithVariable("HOST", "api.example.com", VariableURL). Bodies passed with
+// WithHeredocBody or hex escaping are left unchanged, and text of the
+// request reading like a placeholder is kept literal.
+func WithVariable(name, value string, targets ...VariableTarget) CurlOption {
+	return func(c *CurlCommand) {
+		c.Variables = append(c.Variables, Variable{Name: name, Value: value, Targets: targets})
+	}
+}
+
+// PlaceholderSyntax is how variables are referenced in the command
+type PlaceholderSyntax int
+
+// Placeholder syntaxes, selected with WithPlaceholderSyntax
+const (
+	PlaceholderBraces   PlaceholderSyntax = iota // ${NAME}, expanded by the shell
+	PlaceholderDollar                            // $NAME, expanded by the shell
+	PlaceholderMustache                          // {{NAME}}, e.g. for Postman environments
+	PlaceholderPercent                           // %NAME%, for Windows batch files
+	PlaceholderEnv                               // ${env:NAME}, for PowerShell
+)
+
+// WithPlaceholderSyntax selects how variables set with WithVariable are
+// referenced. Shell syntaxes assign the variables in a preamble; with
+// PlaceholderPercent the preamble uses set, with PlaceholderEnv $env:
+// assignments, and with PlaceholderMustache
+// there is none, the values being defined in the Postman environment.
+// PlaceholderPercent implies WithWindowsCurl, batch files being run by
+// cmd.exe, and PlaceholderEnv WithPowerShell.
+func WithPlaceholderSyntax(syntax PlaceholderSyntax) CurlOption {
+	return func(c *CurlCommand) {
+		c.PlaceholderSyntax = syntax
+		switch syntax {
+		case PlaceholderPercent:
+			c.WindowsCurl = true
+		case PlaceholderEnv:
+			c.PowerShell = true
+		}
+	}
+}
+
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateVariables checks that variables can be assigned in the shell
+func (c *CurlCommand) validateVariables() error {
+	for _, v := range c.Variables {
+		if !variableName.MatchString(v.Name) {
+			return fmt.Errorf("%w: %q", ErrInvalidVariable, v.Name)
+		}
+	}
+	if c.PlaceholderSyntax == PlaceholderPercent && !c.WindowsCurl {
+		return fmt.Errorf("%w: PlaceholderPercent requires WithWindowsCurl", ErrConflictingOptions)
+	}
+	if c.PlaceholderSyntax == PlaceholderEnv && !c.PowerShell {
+		return fmt.Errorf("%w: PlaceholderEnv requires WithPowerShell", ErrConflictingOptions)
+	}
+	if c.WindowsCurl && c.PowerShell {
+		return fmt.Errorf("%w: WithWindowsCurl and WithPowerShell render for different shells", ErrConflictingOptions)
+	}
+	return nil
+}
+
+// placeholder returns the reference to the variable in the command
+func (c *CurlCommand) placeholder(v Variable) string {
+	switch c.PlaceholderSyntax {
+	case PlaceholderDollar:
+		return "$" + v.Name
+	case PlaceholderMustache:
+		return "{{" + v.Name + "}}"
+	case PlaceholderPercent:
+		return "%" + v.Name + "%"
+	case PlaceholderEnv:
+		return "${env:" + v.Name + "}"
+	default:
+		return "${" + v.Name + "}"
+	}
+}
+
+// shellVariables reports whether the shell expands the placeholders
+func (c *CurlCommand) shellVariables() bool {
+	return c.PlaceholderSyntax == PlaceholderBraces || c.PlaceholderSyntax == PlaceholderDollar
+}
+
+func (v Variable) targets(target VariableTarget) bool {
+	if len(v.Targets) == 0 {
+		return true
+	}
+	for _, t := range v.Targets {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
+
+// placeholderEscape precedes text of the URL, headers and body of commands
+// with variables that reads like a placeholder but was in the request, so
+// that only the placeholders substituteVariables wrote are expanded. It
+// escapes itself too, being a byte valid UTF-8 never contains.
+const placeholderEscape = "\xff"
+
+// substituteVariables replaces variable values with their placeholders
+func (c *CurlCommand) substituteVariables() {
+	if len(c.Variables) == 0 {
+		return
+	}
+	c.URL = c.substitute(c.URL, VariableURL)
+	for i := range c.Headers {
+		c.Headers[i].Value = c.substitute(c.Headers[i].Value, VariableHeaders)
+	}
+	if c.substitutesBody() {
+		c.Body.Data = c.substitute(c.Body.Data, VariableBody)
+	}
+}
+
+// substitutesBody reports whether variables are substituted in the body
+func (c *CurlCommand) substitutesBody() bool {
+	return c.Body != nil && !c.Body.HexEscaped && !c.Body.Base64 && !c.HeredocBody
+}
+
+// substitute replaces the values of the variables of target in str, text
+// of the request, with their placeholders, escaping the rest of str. The
+// value of a variable is only looked for in the text the values of those
+// before it left.
+func (c *CurlCommand) substitute(str string, target VariableTarget) string {
+	type segment struct {
+		text        string
+		placeholder bool
+	}
+	segments := []segment{{text: str}}
+	for _, v := range c.Variables {
+		if v.Value == "" || !v.targets(target) {
+			continue
+		}
+		var split []segment
+		for _, s := range segments {
+			if s.placeholder {
+				split = append(split, s)
+				continue
+			}
+			for i, text := range strings.Split(s.text, v.Value) {
+				if i > 0 {
+					split = append(split, segment{text: c.placeholder(v), placeholder: true})
+				}
+				if text != "" {
+					split = append(split, segment{text: text})
+				}
+			}
+		}
+		segments = split
+	}
+
+	var b strings.Builder
+	for _, s := range segments {
+		if s.placeholder {
+			b.WriteString(s.text)
+		} else {
+			b.WriteString(c.escapePlaceholders(s.text))
+		}
+	}
+	return b.String()
+}
+
+// escapePlaceholders escapes placeholderEscape and the text reading like
+// the placeholder of a variable in str, text of the request
+func (c *CurlCommand) escapePlaceholders(str string) string {
+	str = strings.ReplaceAll(str, placeholderEscape, placeholderEscape+placeholderEscape)
+	var b strings.Builder
+	for {
+		i, _ := c.nextPlaceholder(str)
+		if i < 0 {
+			break
+		}
+		b.WriteString(str[:i])
+		b.WriteString(placeholderEscape)
+		b.WriteByte(str[i]) // Search past the escaped placeholder
+		str = str[i+1:]
+	}
+	if b.Len() == 0 {
+		return str
+	}
+	b.WriteString(str)
+	return b.String()
+}
+
+// literal returns the text of str, removing the escapes substituteVariables
+// added to the URL, headers and body of commands with variables
+func (c *CurlCommand) literal(str string) string {
+	if len(c.Variables) == 0 || !strings.Contains(str, placeholderEscape) {
+		return str
+	}
+	var b strings.Builder
+	for i := 0; i < len(str); i++ {
+		if str[i] == placeholderEscape[0] {
+			if i++; i == len(str) {
+				break
+			}
+		}
+		b.WriteByte(str[i])
+	}
+	return b.String()
+}
+
+// mapLiterals returns str with f applied to the text between the
+// placeholders of vars
+func (c *CurlCommand) mapLiterals(str string, vars []Variable, f func(string) string) string {
+	var b strings.Builder
+	for {
+		i, v := findPlaceholder(str, vars, c.placeholder)
+		if i < 0 {
+			break
+		}
+		placeholder := c.placeholder(v)
+		b.WriteString(f(str[:i]))
+		b.WriteString(placeholder)
+		str = str[i+len(placeholder):]
+	}
+	b.WriteString(f(str))
+	return b.String()
+}
+
+// adoptVariables makes the command reference vars, escaping the text of
+// its URL, headers and body reading like the placeholders of those it did
+// not reference yet
+func (c *CurlCommand) adoptVariables(vars []Variable, syntax PlaceholderSyntax) {
+	own := c.Variables
+	c.Variables = append([]Variable(nil), vars...)
+	c.PlaceholderSyntax = syntax
+	if len(c.Variables) == 0 {
+		return
+	}
+	escape := func(str string) string {
+		if len(own) == 0 {
+			return c.escapePlaceholders(str)
+		}
+		return c.mapLiterals(str, own, func(text string) string {
+			return c.escapePlaceholders(c.literal(text))
+		})
+	}
+	c.URL = escape(c.URL)
+	for i := range c.Headers {
+		c.Headers[i].Value = escape(c.Headers[i].Value)
+	}
+	if c.substitutesBody() {
+		c.Body.Data = escape(c.Body.Data)
+	}
+}
+
+// writeVariables writes the variable assignments preceding the command
+func (c *CurlCommand) writeVariables(b *strings.Builder) {
+	for _, v := range c.Variables {
+		switch {
+		case c.PlaceholderSyntax == PlaceholderPercent:
+			b.WriteString(`set "` + v.Name + "=" + strings.ReplaceAll(v.Value, "%", "%%") + `"`)
+		case c.PlaceholderSyntax == PlaceholderEnv:
+			b.WriteString("$env:" + v.Name + " = ")
+			writePowerShellSingleQuoted(b, v.Value)
+		case c.PlaceholderSyntax == PlaceholderMustache:
+			continue
+		case c.DoubleQuotes:
+			b.WriteString(v.Name + "=")
+			writeDoubleQuoted(b, v.Value)
+		default:
+			b.WriteString(v.Name + "=")
+			writeBashEscaped(b, v.Value)
+		}
+		b.WriteByte('\n')
+	}
+}
+
+// expandPlaceholders replaces the placeholders in str with the values of
+// the variables, for output no shell expands
+func (c *CurlCommand) expandPlaceholders(str string) string {
+	if len(c.Variables) == 0 {
+		return str
+	}
+	var b strings.Builder
+	for {
+		i, v := c.nextPlaceholder(str)
+		if i < 0 {
+			break
+		}
+		b.WriteString(c.literal(str[:i]))
+		b.WriteString(v.Value)
+		str = str[i+len(c.placeholder(v)):]
+	}
+	b.WriteString(c.literal(str))
+	return b.String()
+}
+
+// writeWordWithVariables writes str as a single shell word to b, leaving
+// variable placeholders outside of single quotes so the shell expands them
+func (c *CurlCommand) writeWordWithVariables(b *strings.Builder, str string) {
+	if c.DoubleQuotes {
+		b.WriteByte('"')
+	}
+	written := false
+	for {
+		i, v := c.nextPlaceholder(str)
+		if i < 0 {
+			break
+		}
+		placeholder := c.placeholder(v)
+		rest := str[i+len(placeholder):]
+		if c.DoubleQuotes {
+			writeDoubleQuotedContent(b, c.literal(str[:i]))
+			b.WriteString(placeholder)
+			if c.PlaceholderSyntax == PlaceholderDollar && rest != "" && isIdentifierChar(rest[0]) {
+				b.WriteString(`""`) // End the name before the text following it
+			}
+		} else {
+			if i > 0 {
+				writeBashEscaped(b, c.literal(str[:i]))
+			}
+			b.WriteString(`"` + placeholder + `"`)
+		}
+		str = rest
+		written = true
+	}
+	switch {
+	case c.DoubleQuotes:
+		writeDoubleQuotedContent(b, c.literal(str))
+		b.WriteByte('"')
+	case str != "" || !written:
+		writeBashEscaped(b, c.literal(str))
+	}
+}
+
+func isIdentifierChar(b byte) bool {
+	return b == '_' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// nextPlaceholder returns the index of the first variable placeholder in
+// str and its variable, or -1
+func (c *CurlCommand) nextPlaceholder(str string) (int, Variable) {
+	return findPlaceholder(str, c.Variables, c.placeholder)
+}
+
+// findPlaceholder returns the index of the first placeholder of vars in
+// str not escaped with placeholderEscape and its variable, or -1
+func findPlaceholder(str string, vars []Variable, placeholder func(Variable) string) (int, Variable) {
+	first, found := -1, Variable{}
+	for _, v := range vars {
+		p := placeholder(v)
+		for from := 0; ; {
+			i := strings.Index(str[from:], p)
+			if i < 0 {
+				break
+			}
+			i += from
+			if !escaped(str, i) {
+				if first < 0 || i < first {
+					first, found = i, v
+				}
+				break
+			}
+			from = i + 1
+		}
+	}
+	return first, found
+}
+
+// escaped reports whether the text at i in str follows an odd number of
+// placeholderEscape bytes
+func escaped(str string, i int) bool {
+	n := 0
+	for ; i > 0 && str[i-1] == placeholderEscape[0]; i-- {
+		n++
+	}
+	return n%2 == 1
+}
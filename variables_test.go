@@ -0,0 +1,186 @@
+package http2curl
+
+import (
+	"errors"
+	"net/http"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestWithVariable(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []CurlOption
+		wantCommand string
+	}{
+		{
+			name: "host in URL",
+			opts: []CurlOption{WithVariable("HOST", "api.example.com", VariableURL)},
+			wantCommand: "HOST='api.example.com'\n" +
+				`curl -X 'POST' -d '{"tenant":"t-42"}' -H 'Authorization: Bearer abc' -H 'X-Tenant: t-42' 'https://'"${HOST}"'/tenants/t-42/cats'`,
+		},
+		{
+			name: "value in all targets",
+			opts: []CurlOption{WithVariable("TENANT", "t-42")},
+			wantCommand: "TENANT='t-42'\n" +
+				`curl -X 'POST' -d '{"tenant":"'"${TENANT}"'"}' -H 'Authorization: Bearer abc' -H 'X-Tenant: '"${TENANT}" 'https://api.example.com/tenants/'"${TENANT}"'/cats'`,
+		},
+		{
+			name: "several variables",
+			opts: []CurlOption{
+				WithVariable("HOST", "api.example.com"),
+				WithVariable("TOKEN", "abc", VariableHeaders),
+			},
+			wantCommand: "HOST='api.example.com'\nTOKEN='abc'\n" +
+				`curl -X 'POST' -d '{"tenant":"t-42"}' -H 'Authorization: Bearer '"${TOKEN}" -H 'X-Tenant: t-42' 'https://'"${HOST}"'/tenants/t-42/cats'`,
+		},
+		{
+			name: "dollar placeholders",
+			opts: []CurlOption{WithVariable("TENANT", "t-42", VariableURL), WithPlaceholderSyntax(PlaceholderDollar)},
+			wantCommand: "TENANT='t-42'\n" +
+				`curl -X 'POST' -d '{"tenant":"t-42"}' -H 'Authorization: Bearer abc' -H 'X-Tenant: t-42' 'https://api.example.com/tenants/'"$TENANT"'/cats'`,
+		},
+		{
+			name: "dollar placeholders in double quotes",
+			opts: []CurlOption{
+				WithVariable("PREFIX", "t-4", VariableURL), WithPlaceholderSyntax(PlaceholderDollar), WithDoubleQuoteEscaping(),
+			},
+			wantCommand: "PREFIX=\"t-4\"\n" +
+				`curl -X "POST" -d "{\"tenant\":\"t-42\"}" -H "Authorization: Bearer abc" -H "X-Tenant: t-42" "https://api.example.com/tenants/$PREFIX""2/cats"`,
+		},
+		{
+			name:        "Postman placeholders",
+			opts:        []CurlOption{WithVariable("host", "api.example.com"), WithPlaceholderSyntax(PlaceholderMustache)},
+			wantCommand: `curl -X 'POST' -d '{"tenant":"t-42"}' -H 'Authorization: Bearer abc' -H 'X-Tenant: t-42' 'https://{{host}}/tenants/t-42/cats'`,
+		},
+		{
+			name: "Windows batch placeholders",
+			opts: []CurlOption{WithVariable("TENANT", "t-42"), WithPlaceholderSyntax(PlaceholderPercent)},
+			wantCommand: "set \"TENANT=t-42\"\n" +
+				`curl -X "POST" --data-raw "{""tenant"":""%TENANT%""}" -H "Authorization: Bearer abc" -H "X-Tenant: %TENANT%" "https://api.example.com/tenants/%TENANT%/cats"`,
+		},
+		{
+			name: "double quotes",
+			opts: []CurlOption{WithVariable("HOST", "api.example.com"), WithDoubleQuoteEscaping()},
+			wantCommand: "HOST=\"api.example.com\"\n" +
+				`curl -X "POST" -d "{\"tenant\":\"t-42\"}" -H "Authorization: Bearer abc" -H "X-Tenant: t-42" "https://${HOST}/tenants/t-42/cats"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "https://api.example.com/tenants/t-42/cats", strings.NewReader(`{"tenant":"t-42"}`))
+			req.Header.Set("Authorization", "Bearer abc")
+			req.Header.Set("X-Tenant", "t-42")
+			command, err := GetCurlCommand(req, tt.opts...)
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestWithVariableInvalidName(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	if _, err := GetCurlCommand(req, WithVariable("HOST NAME", "example.com")); !errors.Is(err, ErrInvalidVariable) {
+		t.Errorf("GetCurlCommand() error = %v, want %v", err, ErrInvalidVariable)
+	}
+}
+
+func TestPlaceholderPercentWithoutWindowsCurl(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	_, err := GetCurlCommand(req, WithVariable("HOST", "example.com"), func(c *CurlCommand) {
+		c.PlaceholderSyntax = PlaceholderPercent
+	})
+	if !errors.Is(err, ErrConflictingOptions) {
+		t.Errorf("GetCurlCommand() error = %v, want %v", err, ErrConflictingOptions)
+	}
+}
+
+func TestVariablePlaceholderText(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+	text := "${HOST} $HOST \xff${HOST} \xff"
+	tests := []struct {
+		name string
+		opts []CurlOption
+	}{
+		{name: "braces"},
+		{name: "dollar", opts: []CurlOption{WithPlaceholderSyntax(PlaceholderDollar)}},
+		{name: "double quotes", opts: []CurlOption{WithDoubleQuoteEscaping()}},
+		{name: "printf body", opts: []CurlOption{WithEscapedNewlines()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := func(opts ...CurlOption) string {
+				req, _ := http.NewRequest("POST", "https://api.example.com/cats", strings.NewReader(text+"\n"))
+				req.Header.Set("X-Template", text)
+				command, err := GetCurlCommand(req, append(tt.opts, opts...)...)
+				if err != nil {
+					t.Fatalf("GetCurlCommand() error = %v", err)
+				}
+				out, err := exec.Command(bash, "-c", printArgs+command.String()).Output()
+				if err != nil {
+					t.Fatalf("bash -c %q error = %v", command, err)
+				}
+				return string(out)
+			}
+			if got, want := run(WithVariable("HOST", "api.example.com")), run(); got != want {
+				t.Errorf("bash runs curl with %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestVariablePlaceholderTextOutputs(t *testing.T) {
+	text := "${HOST} %HOST% \xff"
+	req, _ := http.NewRequest("POST", "https://api.example.com/cats", strings.NewReader(text))
+	req.Header.Set("X-Template", text)
+
+	config, _ := GetCurlCommand(req, WithVariable("HOST", "api.example.com"))
+	want := "request = \"POST\"\ndata = \"" + text + "\"\nheader = \"X-Template: " + text + "\"\nurl = \"https://api.example.com/cats\"\n"
+	if got := config.ConfigFileContents(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+
+	windows, _ := GetCurlCommand(req, WithVariable("HOST", "api.example.com"), WithWindowsCurl())
+	want = "set \"HOST=api.example.com\"\n" +
+		`curl -X "POST" --data-raw "${HOST} %%HOST%% ` + "\xff" + `" -H "X-Template: ${HOST} %%HOST%% ` + "\xff" + `" "https://%HOST%/cats"`
+	if got := windows.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+
+	var values []string
+	config.EmitTokens(func(token Token) error {
+		values = append(values, token.Value)
+		return nil
+	})
+	if got := strings.Join(values, "|"); got != "curl|-X|POST|-d|"+text+"|-H|"+text+"|https://${HOST}/cats" {
+		t.Errorf("token values = %q", got)
+	}
+}
+
+func TestChainCommandsPlaceholderText(t *testing.T) {
+	literal, _ := http.NewRequest("GET", "http://api.example.com/template", nil)
+	literal.Header.Set("X-Template", "${HOST}")
+	first, _ := GetCurlCommand(literal)
+	status, _ := http.NewRequest("GET", "http://api.example.com/status", nil)
+	second, _ := GetCurlCommand(status, WithVariable("HOST", "api.example.com"))
+
+	chained, err := ChainCommands(first, second)
+	if err != nil {
+		t.Fatalf("ChainCommands() error = %v", err)
+	}
+	want := "HOST='api.example.com'\n" +
+		`curl -X 'GET' -H 'X-Template: ${HOST}' 'http://api.example.com/template' --next -X 'GET' 'http://'"${HOST}"'/status'`
+	if got := chained.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+package http2curl
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// LossKind is the kind of approximation made while generating a command
+type LossKind string
+
+// Approximations reported by Warnings
+const (
+	LossBodyTruncated     LossKind = "body_truncated"
+	LossBodyRewritten     LossKind = "body_rewritten"
+	LossBodyDecompressed  LossKind = "body_decompressed"
+	LossContentEncoding   LossKind = "content_encoding"
+	LossStreamedBody      LossKind = "streamed_body"
+	LossMultipartBoundary LossKind = "multipart_boundary"
+	LossBodyNewlines      LossKind = "body_newlines"
+	LossBodyNUL           LossKind = "body_nul"
+	LossTrailers          LossKind = "trailers"
+	LossHTTP2             LossKind = "http2"
+	LossHeaderDropped     LossKind = "header_dropped"   // Dropped by WithIncludeHeaders, WithExcludeHeaders or a HeaderTransformer
+	LossHeaderSanitized   LossKind = "header_sanitized" // Control characters stripped
+	LossAuthStripped      LossKind = "auth_stripped"
+	LossRedacted          LossKind = "redacted"
+)
+
+// LossWarning describes one way the command differs from the request it
+// was generated from
+type LossWarning struct {
+	Kind   LossKind `json:"kind"`
+	Name   string   `json:"name,omitempty"` // Header, trailer or Content-Encoding concerned
+	Detail string   `json:"detail"`
+}
+
+// Warnings returns every approximation made while generating the command,
+// so tooling can show how faithfully it reproduces the request. Unlike
+// WithStrict, it also lists what options asked to leave out: dropped
+// headers and redacted values.
+func (c *CurlCommand) Warnings() []LossWarning {
+	if len(c.redactions) == 0 {
+		return append([]LossWarning(nil), c.warnings...)
+	}
+	warnings := make([]LossWarning, len(c.warnings), len(c.warnings)+len(c.redactions))
+	copy(warnings, c.warnings)
+	for _, r := range c.redactions {
+		warnings = append(warnings, LossWarning{
+			Kind:   LossRedacted,
+			Name:   r.Name,
+			Detail: fmt.Sprintf("%d %s value(s) redacted", r.Count, r.Kind),
+		})
+	}
+	return warnings
+}
+
+// recordLoss adds an approximation to the report
+func (c *CurlCommand) recordLoss(kind LossKind, name, detail string) {
+	c.warnings = append(c.warnings, LossWarning{Kind: kind, Name: name, Detail: detail})
+}
+
+// knownContentEncodings are the registered content codings, which curl
+// and servers can be expected to handle
+var knownContentEncodings = map[string]bool{
+	"identity": true, "gzip": true, "x-gzip": true, "deflate": true,
+	"br": true, "zstd": true, "compress": true, "x-compress": true,
+}
+
+// standardPseudoHeaders are the HTTP/2 pseudo-headers curl derives from the
+// method and URL
+var standardPseudoHeaders = map[string]bool{
+	":method": true, ":scheme": true, ":authority": true, ":path": true,
+}
+
+// recordLosses reports the ways the command built from req approximates
+// it. rewritten and decompressed report whether the body was changed by a
+// formatting option or by gzip decompression.
+func (c *CurlCommand) recordLosses(req *http.Request, rewritten, decompressed bool) {
+	if c.bodyTruncated {
+		c.recordLoss(LossBodyTruncated, "", "body truncated")
+	}
+	if rewritten {
+		c.recordLoss(LossBodyRewritten, "", "body rewritten by a formatting option")
+	}
+	if decompressed {
+		c.recordLoss(LossBodyDecompressed, "gzip", "gzip body sent decompressed")
+	} else if encoding := req.Header.Get("Content-Encoding"); encoding != "" {
+		for _, e := range strings.Split(encoding, ",") {
+			switch e = strings.ToLower(strings.TrimSpace(e)); {
+			case !knownContentEncodings[e]:
+				c.recordLoss(LossContentEncoding, e, fmt.Sprintf("unsupported Content-Encoding %q", e))
+			case c.AutoDecompressGZIP && e != "identity":
+				c.recordLoss(LossContentEncoding, e, fmt.Sprintf("%s body sent without decompression", e))
+			}
+		}
+	}
+	if c.hasBody() && !c.Body.Chunked && hasUnknownLength(req) {
+		c.recordLoss(LossStreamedBody, "", "body of unknown length sent with a Content-Length, see WithChunkedBody")
+	}
+	if len(c.FormParts) > 0 {
+		c.recordLoss(LossMultipartBoundary, "", "multipart body rebuilt by curl with its own boundary")
+	}
+	switch mode := c.bodyMode(); {
+	case mode == bodyInline && !c.CurlJSON && strings.Contains(c.Body.Data, "\n"):
+		c.recordLoss(LossBodyNewlines, "", `body newlines sent as \n, see WithEscapedNewlines`)
+	case mode != bodyFile && mode != bodyBase64 && mode != bodyPrintf && c.hasBody() && strings.Contains(c.Body.Data, "\x00"):
+		c.recordLoss(LossBodyNUL, "", "body NUL bytes cannot be passed on the command line")
+	}
+	if len(req.Trailer) > 0 {
+		keys := sortedKeys(req.Trailer)
+		c.recordLoss(LossTrailers, strings.Join(keys, ", "), "trailers not sent by curl: "+strings.Join(keys, ", "))
+	}
+	if isHTTP2(req) {
+		if c.HTTPVersion == "" {
+			c.recordLoss(LossHTTP2, "", "HTTP/2 request rendered without --http2, see WithHTTP2Flags")
+		}
+		for _, k := range sortedKeys(req.Header) {
+			if strings.HasPrefix(k, ":") && !standardPseudoHeaders[k] {
+				c.recordLoss(LossHTTP2, k, "HTTP/2 pseudo-header "+k+" dropped")
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package http2curl
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWarnings(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "https://example.com/cats", strings.NewReader("meow"))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Internal", "1")
+	req.Header["X-Note"] = []string{"a\x00b"}
+	req.Trailer = http.Header{"Checksum": nil}
+	transport := &http.Transport{Proxy: http.ProxyURL(&url.URL{Scheme: "http", User: url.UserPassword("u", "p"), Host: "proxy:3128"})}
+
+	command, err := GetCurlCommand(req,
+		WithRedactedHeaders("Authorization"),
+		WithExcludeHeaders("X-Internal"),
+		WithTransportProxy(transport),
+	)
+	if err != nil {
+		t.Fatalf("GetCurlCommand() error = %v", err)
+	}
+	want := []LossWarning{
+		{Kind: LossHeaderDropped, Name: "X-Internal", Detail: "header dropped: X-Internal"},
+		{Kind: LossHeaderSanitized, Name: "X-Note", Detail: "control characters stripped from header X-Note"},
+		{Kind: LossAuthStripped, Detail: "proxy credentials left out"},
+		{Kind: LossTrailers, Name: "Checksum", Detail: "trailers not sent by curl: Checksum"},
+		{Kind: LossRedacted, Name: "Authorization", Detail: "1 header value(s) redacted"},
+	}
+	if got := command.Warnings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Got:\n%+v\nWant:\n%+v", got, want)
+	}
+	if got := command.Clone().Warnings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Clone().Warnings():\nGot:\n%+v\nWant:\n%+v", got, want)
+	}
+
+	exact, err := GetCurlCommand(req, WithExcludeHeaders("X-Note"), WithStrict())
+	if err == nil || !strings.HasSuffix(err.Error(), ": trailers not sent by curl: Checksum") {
+		t.Errorf("GetCurlCommand() with WithStrict = %v, %v, want only the trailers reported", exact, err)
+	}
+}
@@ -0,0 +1,85 @@
+package http2curl
+
+import "strings"
+
+// windowsLineBreak separates the flags of MultiLineString for cmd.exe
+const windowsLineBreak = " ^\n  "
+
+// WithWindowsCurl renders commands for the curl.exe bundled with Windows
+// 10 and 11, run from a cmd.exe batch file: words are double-quoted with
+// cmd-safe escaping, comments use REM, variables the %NAME% syntax and
+// inline bodies --data-raw. Bodies that would be piped through standard
+// input, or contain newlines or binary bytes, are read from
+// DefaultBodyFile instead, which the caller writes with Body.Data as
+// reported by Fallback.
+func WithWindowsCurl() CurlOption {
+	return func(c *CurlCommand) {
+		c.WindowsCurl = true
+		c.PlaceholderSyntax = PlaceholderPercent
+	}
+}
+
+// applyWindowsBody moves bodies cmd.exe and PowerShell cannot pass on the command line
+// to DefaultBodyFile
+func (c *CurlCommand) applyWindowsBody() {
+	if !c.windows() || !c.hasBody() || c.BodyFile != "" {
+		return
+	}
+	if !c.readsStdin() && c.bodyMode() != bodyANSIC && !strings.ContainsAny(c.Body.Data, "\r\n") {
+		return
+	}
+	c.BodyFile = DefaultBodyFile
+	c.Fallback = BodyFileFallback
+	c.Comments = append(c.Comments, "write the body to "+DefaultBodyFile+" before running the command")
+}
+
+// commentPrefix returns the prefix of comment lines
+func (c *CurlCommand) commentPrefix() string {
+	if c.WindowsCurl {
+		return "REM "
+	}
+	return "# "
+}
+
+// writeCmdQuoted writes str to b as a double-quoted cmd.exe word parsed by
+// curl.exe's C runtime: quotes are doubled, backslashes preceding a quote
+// doubled and percent signs doubled for batch files. Variable placeholders
+// are left for cmd.exe to expand.
+func (c *CurlCommand) writeCmdQuoted(b *strings.Builder, str string) {
+	b.WriteByte('"')
+	for {
+		i, v := c.nextPlaceholder(str)
+		if i < 0 {
+			break
+		}
+		writeCmdQuotedContent(b, c.literal(str[:i]))
+		b.WriteString(c.placeholder(v))
+		str = str[i+len(c.placeholder(v)):]
+	}
+	backslashes := writeCmdQuotedContent(b, c.literal(str))
+	b.WriteString(strings.Repeat(`\`, backslashes)) // Keep the closing quote
+	b.WriteByte('"')
+}
+
+// writeCmdQuotedContent writes the escaped content of a cmd.exe word to b,
+// returning the number of backslashes it ends with
+func writeCmdQuotedContent(b *strings.Builder, str string) int {
+	backslashes := 0
+	for i := 0; i < len(str); i++ {
+		switch str[i] {
+		case '\\':
+			backslashes++
+		case '"':
+			b.WriteString(strings.Repeat(`\`, backslashes))
+			b.WriteByte('"')
+			backslashes = 0
+		case '%':
+			b.WriteByte('%')
+			backslashes = 0
+		default:
+			backslashes = 0
+		}
+		b.WriteByte(str[i])
+	}
+	return backslashes
+}
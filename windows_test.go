@@ -0,0 +1,72 @@
+package http2curl
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithWindowsCurl(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		header   string
+		opts     []CurlOption
+		want     string
+		fallback LengthFallback
+	}{
+		{
+			name:   "inline body",
+			body:   `{"discount":"50%","note":"say \"meow\""}`,
+			header: `C:\cats\`,
+			want:   `curl -X "POST" --data-raw "{""discount"":""50%%"",""note"":""say \\""meow\\""""}" -H "X-Path: C:\cats\\" "http://example.com/cats?a=1&b=2"`,
+		},
+		{
+			name:     "multi-line body",
+			body:     "meow\nmeow",
+			want:     "REM write the body to body.bin before running the command\n" + `curl -X "POST" --data-binary "@body.bin" "http://example.com/cats?a=1&b=2"`,
+			fallback: BodyFileFallback,
+		},
+		{
+			name:     "heredoc body",
+			body:     "meow",
+			opts:     []CurlOption{WithHeredocBody()},
+			want:     "REM write the body to body.bin before running the command\n" + `curl -X "POST" --data-binary "@body.bin" "http://example.com/cats?a=1&b=2"`,
+			fallback: BodyFileFallback,
+		},
+		{
+			name: "variables and inline comments",
+			body: "meow",
+			opts: []CurlOption{WithVariable("HOST", "example.com"), WithComment("cats"), WithInlineComments()},
+			want: `set "HOST=example.com"` + "\n" + `curl -X "POST" --data-raw "meow" "http://%HOST%/cats?a=1&b=2" & REM cats`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "http://example.com/cats?a=1&b=2", strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Set("X-Path", tt.header)
+			}
+			command, err := GetCurlCommand(req, append(tt.opts, WithWindowsCurl())...)
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if got := command.String(); got != tt.want {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.want)
+			}
+			if command.Fallback != tt.fallback {
+				t.Errorf("Fallback = %v, want %v", command.Fallback, tt.fallback)
+			}
+		})
+	}
+}
+
+func TestWindowsMultiLineString(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	command, _ := GetCurlCommand(req, WithWindowsCurl())
+	want := "curl ^\n  -X \"GET\" ^\n  \"http://example.com\""
+	if got := command.MultiLineString(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
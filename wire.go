@@ -0,0 +1,186 @@
+package http2curl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+)
+
+// WireTransport is an http.RoundTripper logging the curl command of each
+// request as it was serialized on the connection rather than as it was
+// built, making changes made by the transport visible, e.g. chunking, header
+// normalization and the Host sent. Connections are kept at HTTP/1.1 so the
+// written bytes stay readable; requests tunnelled through a proxy CONNECT
+// are logged without a command.
+type WireTransport struct {
+	base      *http.Transport
+	log       TransportLogger
+	converter *Converter
+}
+
+// NewWireTransport returns a WireTransport sending requests with a copy of
+// base, or http.DefaultTransport if base is nil, and logging the commands
+// generated with opts from their wire form with log
+func NewWireTransport(base *http.Transport, log TransportLogger, opts ...CurlOption) *WireTransport {
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	t := &WireTransport{base: base.Clone(), log: log, converter: NewConverter(opts...)}
+	t.base.ForceAttemptHTTP2 = false
+	t.base.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	plain := base.DialContext
+	if plain == nil {
+		plain = (&net.Dialer{}).DialContext
+	}
+	t.base.DialContext = wrapDial(plain)
+	if base.DialTLSContext != nil {
+		t.base.DialTLSContext = wrapDial(base.DialTLSContext)
+	} else {
+		t.base.DialTLSContext = t.dialTLS(plain)
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *WireTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		mu   sync.Mutex
+		conn *wireConn
+	)
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if wc, ok := info.Conn.(*wireConn); ok {
+				wc.record()
+				mu.Lock()
+				conn = wc
+				mu.Unlock()
+			}
+		},
+	}
+	resp, err := t.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+
+	// The connection is not reused before the response body is closed, so
+	// it only holds the bytes of req, or part of them if the server answered
+	// before the body was written
+	var wire []byte
+	mu.Lock()
+	if conn != nil {
+		wire = conn.take()
+	}
+	mu.Unlock()
+	command, cerr := t.command(req, wire)
+	if cerr != nil {
+		command = nil
+	}
+	t.log(command, resp, err)
+	return resp, err
+}
+
+// command generates the command of req from the bytes written for it
+func (t *WireTransport) command(req *http.Request, wire []byte) (*CurlCommand, error) {
+	parsed, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(wire)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrWireFormat, err)
+	}
+	body, _ := io.ReadAll(parsed.Body) // Keep what was written of a partial body
+
+	render := req.Clone(req.Context())
+	render.Method = parsed.Method
+	render.Header = parsed.Header
+	if len(parsed.TransferEncoding) > 0 {
+		render.Header.Set("Transfer-Encoding", strings.Join(parsed.TransferEncoding, ", "))
+	}
+	if !strings.EqualFold(parsed.Host, req.URL.Host) {
+		render.Header.Set("Host", parsed.Host)
+	}
+	u := *req.URL
+	u.Path, u.RawPath, u.RawQuery = parsed.URL.Path, parsed.URL.RawPath, parsed.URL.RawQuery
+	render.URL = &u
+	render.Body = io.NopCloser(bytes.NewReader(body))
+	render.ContentLength = int64(len(body))
+	render.GetBody = nil
+	return t.converter.Convert(render)
+}
+
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// wrapDial returns a dialer recording the connections of dial
+func wrapDial(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &wireConn{Conn: conn}, nil
+	}
+}
+
+// dialTLS returns a dialer doing the TLS handshake over connections of
+// dial with the transport's TLS configuration and recording above it, so
+// the plaintext is recorded
+func (t *WireTransport) dialTLS(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		config := &tls.Config{}
+		if t.base.TLSClientConfig != nil {
+			config = t.base.TLSClientConfig.Clone()
+		}
+		if config.ServerName == "" {
+			config.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		config.NextProtos = []string{"http/1.1"}
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return &wireConn{Conn: tlsConn}, nil
+	}
+}
+
+// wireConn is a connection recording the bytes written while a request is
+// being sent
+type wireConn struct {
+	net.Conn
+
+	mu        sync.Mutex
+	recording bool
+	written   bytes.Buffer
+}
+
+func (c *wireConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.mu.Lock()
+	if c.recording {
+		c.written.Write(p[:n])
+	}
+	c.mu.Unlock()
+	return n, err
+}
+
+// record starts recording the bytes of a new request
+func (c *wireConn) record() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.recording = true
+	c.written.Reset()
+}
+
+// take stops recording and returns the bytes recorded
+func (c *wireConn) take() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.recording = false
+	return append([]byte(nil), c.written.Bytes()...)
+}
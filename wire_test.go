@@ -0,0 +1,86 @@
+package http2curl
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWireTransport(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+	})
+	plain := httptest.NewServer(handler)
+	defer plain.Close()
+	secure := httptest.NewUnstartedServer(handler)
+	secure.EnableHTTP2 = true
+	secure.StartTLS()
+	defer secure.Close()
+
+	tests := []struct {
+		name   string
+		server *httptest.Server
+		body   io.Reader
+		host   string
+		want   string
+	}{
+		{
+			name:   "transport headers",
+			server: plain,
+			want:   `curl -X 'GET' -H 'Accept-Encoding: gzip' -H 'User-Agent: Go-http-client/1.1' '` + plain.URL + `/cats?name=tom'`,
+		},
+		{
+			name:   "chunked body and host",
+			server: plain,
+			body:   io.MultiReader(strings.NewReader("meow")),
+			host:   "cats.example.com",
+			want: `curl -X 'POST' -d 'meow' -H 'Accept-Encoding: gzip' -H 'Host: cats.example.com' ` +
+				`-H 'Transfer-Encoding: chunked' -H 'User-Agent: Go-http-client/1.1' '` + plain.URL + `/cats?name=tom'`,
+		},
+		{
+			name:   "TLS kept at HTTP/1.1",
+			server: secure,
+			body:   strings.NewReader("meow"),
+			want: `curl -k -X 'POST' -d 'meow' -H 'Accept-Encoding: gzip' -H 'User-Agent: Go-http-client/1.1' '` +
+				secure.URL + `/cats?name=tom'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			var gotCommand bool
+			transport := NewWireTransport(tt.server.Client().Transport.(*http.Transport), func(command *CurlCommand, resp *http.Response, err error) {
+				if err != nil {
+					t.Errorf("round trip error = %v", err)
+				}
+				if command != nil {
+					got, gotCommand = command.String(), true
+				}
+			}, WithInsecureSkipVerify())
+			method := "GET"
+			if tt.body != nil {
+				method = "POST"
+			}
+			req, _ := http.NewRequest(method, tt.server.URL+"/cats?name=tom", tt.body)
+			req.Host = tt.host
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("RoundTrip() error = %v", err)
+			}
+			resp.Body.Close()
+
+			if !gotCommand {
+				t.Fatal("no command logged")
+			}
+			if resp.ProtoMajor != 1 {
+				t.Errorf("response protocol = %s, want HTTP/1.1", resp.Proto)
+			}
+			if got != tt.want {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package http2curl
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"mime"
+	"strings"
+)
+
+// WithPrettyXML indents XML and SOAP bodies and passes them through a
+// here-document
+func WithPrettyXML() CurlOption {
+	return func(c *CurlCommand) {
+		c.PrettyXML = true
+	}
+}
+
+// formatXML indents an XML body, leaving other bodies untouched
+func (c *CurlCommand) formatXML(contentType string) {
+	if !c.hasBody() || !isXML(contentType, c.Body.Data) {
+		return
+	}
+	indented, err := indentXML(c.Body.Data)
+	if err != nil {
+		return
+	}
+	c.Body.Data = indented
+	c.HeredocBody = true
+}
+
+// isXML reports whether the body is XML according to its Content-Type, or
+// its first character when no Content-Type is set
+func isXML(contentType, body string) bool {
+	if contentType == "" {
+		return strings.HasPrefix(strings.TrimSpace(body), "<")
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// indentXML re-indents an XML document without resolving namespaces, so
+// prefixes are kept as written
+func indentXML(data string) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(data))
+	decoder.Strict = false
+	var tokens []xml.Token
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if text, ok := token.(xml.CharData); ok && len(bytes.TrimSpace(text)) == 0 {
+			continue
+		}
+		tokens = append(tokens, xml.CopyToken(token))
+	}
+
+	var b strings.Builder
+	depth := 0
+	indent := func() {
+		b.WriteString(strings.Repeat("  ", depth))
+	}
+	for i := 0; i < len(tokens); i++ {
+		switch token := tokens[i].(type) {
+		case xml.StartElement:
+			indent()
+			b.WriteString("<" + xmlName(token.Name))
+			for _, attr := range token.Attr {
+				b.WriteString(" " + xmlName(attr.Name) + `="` + escapeXML(attr.Value) + `"`)
+			}
+			if i+1 < len(tokens) {
+				if _, ok := tokens[i+1].(xml.EndElement); ok {
+					b.WriteString("/>\n")
+					i++
+					continue
+				}
+			}
+			if i+2 < len(tokens) {
+				text, isText := tokens[i+1].(xml.CharData)
+				_, isEnd := tokens[i+2].(xml.EndElement)
+				if isText && isEnd {
+					b.WriteString(">" + escapeXML(string(bytes.TrimSpace(text))) + "</" + xmlName(token.Name) + ">\n")
+					i += 2
+					continue
+				}
+			}
+			b.WriteString(">\n")
+			depth++
+		case xml.EndElement:
+			depth--
+			indent()
+			b.WriteString("</" + xmlName(token.Name) + ">\n")
+		case xml.CharData:
+			indent()
+			b.WriteString(escapeXML(string(bytes.TrimSpace(token))) + "\n")
+		case xml.Comment:
+			indent()
+			b.WriteString("<!--" + string(token) + "-->\n")
+		case xml.ProcInst:
+			indent()
+			b.WriteString("<?" + token.Target + " " + string(token.Inst) + "?>\n")
+		case xml.Directive:
+			indent()
+			b.WriteString("<!" + string(token) + ">\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package http2curl
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestWithPrettyXML(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantCommand string
+	}{
+		{
+			name:        "SOAP envelope",
+			contentType: "application/soap+xml; charset=utf-8",
+			body: `<?xml version="1.0"?><soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope">` +
+				`<soap:Header/><soap:Body><m:GetPrice xmlns:m="https://example.com/prices"><m:Item note="a &amp; b">Apples</m:Item>` +
+				`<!-- quantity is optional --></m:GetPrice></soap:Body></soap:Envelope>`,
+			wantCommand: "{ printf '%s' \"$(cat)\"; } <<'EOF' | curl -X 'POST' --data-binary @- -H 'Content-Type: application/soap+xml; charset=utf-8' 'http://example.com/soap'\n" +
+				`<?xml version="1.0"?>` + "\n" +
+				`<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope">` + "\n" +
+				`  <soap:Header/>` + "\n" +
+				`  <soap:Body>` + "\n" +
+				`    <m:GetPrice xmlns:m="https://example.com/prices">` + "\n" +
+				`      <m:Item note="a &amp; b">Apples</m:Item>` + "\n" +
+				`      <!-- quantity is optional -->` + "\n" +
+				`    </m:GetPrice>` + "\n" +
+				`  </soap:Body>` + "\n" +
+				`</soap:Envelope>` + "\n" +
+				"EOF",
+		},
+		{
+			name:        "XML without Content-Type",
+			body:        `<a><b>1</b></a>`,
+			wantCommand: "{ printf '%s' \"$(cat)\"; } <<'EOF' | curl -X 'POST' --data-binary @- 'http://example.com/soap'\n<a>\n  <b>1</b>\n</a>\nEOF",
+		},
+		{
+			name:        "JSON body",
+			contentType: "application/json",
+			body:        `{"a":1}`,
+			wantCommand: `curl -X 'POST' -d '{"a":1}' -H 'Content-Type: application/json' 'http://example.com/soap'`,
+		},
+		{
+			name:        "malformed XML",
+			contentType: "text/xml",
+			body:        `<a><b>1</a`,
+			wantCommand: `curl -X 'POST' -d '<a><b>1</a' -H 'Content-Type: text/xml' 'http://example.com/soap'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "http://example.com/soap", bytes.NewBufferString(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			command, err := GetCurlCommand(req, WithPrettyXML())
+			if err != nil {
+				t.Fatalf("GetCurlCommand() error = %v", err)
+			}
+			if command.String() != tt.wantCommand {
+				t.Errorf("Got:\n%s\nWant:\n%s", command.String(), tt.wantCommand)
+			}
+		})
+	}
+}